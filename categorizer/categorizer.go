@@ -0,0 +1,157 @@
+// Package categorizer evaluates a user's category rules against transactions
+// to pick their category, payee and tags.
+package categorizer
+
+import (
+	"errors"
+	"my-api/models"
+	"my-api/money"
+	"my-api/utils"
+	"sort"
+)
+
+// Transaction holds the fields rules can match on
+type Transaction struct {
+	Description     string
+	Amount          money.Amount
+	TransactionType int
+	AssetID         uint64
+}
+
+// Result is what the matching rules assign. A field is set by the first
+// matching rule that provides it; tags are collected from every match.
+type Result struct {
+	CategoryID *uint
+	PayeeID    *uint
+	TagIDs     []uint
+	RuleIDs    []uint // matching rules in evaluation order
+}
+
+// Rule is a compiled CategoryRule
+type Rule struct {
+	ID         uint
+	Priority   int
+	CategoryID *uint
+	PayeeID    *uint
+	TagIDs     []uint
+
+	matcher         *utils.TextMatcher // nil matches any description
+	minAmount       *money.Amount
+	maxAmount       *money.Amount
+	transactionType *int
+	assetID         *uint64
+}
+
+// Compile validates a rule and prepares its description matcher
+func Compile(rule models.CategoryRule) (*Rule, error) {
+	if rule.CategoryID == nil && rule.PayeeID == nil && len(rule.Tags) == 0 {
+		return nil, errors.New("rule must set a category, payee or tags")
+	}
+	if rule.Pattern == "" && rule.MinAmount == nil && rule.MaxAmount == nil && rule.TransactionType == nil && rule.AssetID == nil {
+		return nil, errors.New("rule must have at least one condition")
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount >= *rule.MaxAmount {
+		return nil, errors.New("min_amount must be less than max_amount")
+	}
+
+	compiled := &Rule{
+		ID:              rule.ID,
+		Priority:        rule.Priority,
+		CategoryID:      rule.CategoryID,
+		PayeeID:         rule.PayeeID,
+		minAmount:       rule.MinAmount,
+		maxAmount:       rule.MaxAmount,
+		transactionType: rule.TransactionType,
+		assetID:         rule.AssetID,
+	}
+	for _, tag := range rule.Tags {
+		compiled.TagIDs = append(compiled.TagIDs, tag.ID)
+	}
+
+	if rule.Pattern != "" {
+		matchType := rule.MatchType
+		if matchType == "" {
+			matchType = utils.MatchContains
+		}
+		matcher, err := utils.NewTextMatcher(matchType, rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled.matcher = matcher
+	}
+
+	return compiled, nil
+}
+
+// Matches reports whether the transaction meets every condition of the rule
+func (r *Rule) Matches(t Transaction) bool {
+	if r.transactionType != nil && *r.transactionType != t.TransactionType {
+		return false
+	}
+	if r.assetID != nil && *r.assetID != t.AssetID {
+		return false
+	}
+	if r.minAmount != nil && t.Amount < *r.minAmount {
+		return false
+	}
+	if r.maxAmount != nil && t.Amount >= *r.maxAmount {
+		return false
+	}
+	if r.matcher != nil && !r.matcher.Matches(t.Description) {
+		return false
+	}
+	return true
+}
+
+// Engine evaluates a set of rules in priority order
+type Engine struct {
+	rules []*Rule
+}
+
+// New compiles the rules and orders them by priority, then id. Rules that
+// fail to compile are skipped with a warning so one bad rule does not block
+// transaction creation.
+func New(rules []models.CategoryRule) *Engine {
+	engine := &Engine{}
+	for _, rule := range rules {
+		compiled, err := Compile(rule)
+		if err != nil {
+			utils.LogWarningf("Skipping category rule %d: %v", rule.ID, err)
+			continue
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+
+	sort.SliceStable(engine.rules, func(i, j int) bool {
+		if engine.rules[i].Priority != engine.rules[j].Priority {
+			return engine.rules[i].Priority < engine.rules[j].Priority
+		}
+		return engine.rules[i].ID < engine.rules[j].ID
+	})
+	return engine
+}
+
+// Evaluate runs every rule against the transaction
+func (e *Engine) Evaluate(t Transaction) Result {
+	var result Result
+	seenTags := map[uint]bool{}
+	for _, rule := range e.rules {
+		if !rule.Matches(t) {
+			continue
+		}
+		result.RuleIDs = append(result.RuleIDs, rule.ID)
+		if result.CategoryID == nil {
+			result.CategoryID = rule.CategoryID
+		}
+		if result.PayeeID == nil {
+			result.PayeeID = rule.PayeeID
+		}
+		for _, tagID := range rule.TagIDs {
+			if !seenTags[tagID] {
+				seenTags[tagID] = true
+				result.TagIDs = append(result.TagIDs, tagID)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package categorizer
+
+import (
+	"my-api/models"
+	"my-api/money"
+	"my-api/utils"
+	"testing"
+)
+
+func uintPtr(v uint) *uint { return &v }
+func intPtr(v int) *int    { return &v }
+
+func amountPtr(v int64) *money.Amount {
+	amount := money.FromInt(v)
+	return &amount
+}
+
+func TestEvaluateUsesPriorityOrder(t *testing.T) {
+	groceries, dining, coffee := uint(1), uint(2), uint(3)
+	engine := New([]models.CategoryRule{
+		{ID: 10, Priority: 200, MatchType: utils.MatchContains, Pattern: "indomaret", CategoryID: &dining},
+		{ID: 11, Priority: 100, MatchType: utils.MatchContains, Pattern: "indomaret", MaxAmount: amountPtr(500000), CategoryID: &groceries, Tags: []models.Tag{{ID: 7}}},
+		{ID: 12, Priority: 300, TransactionType: intPtr(2), PayeeID: uintPtr(5), Tags: []models.Tag{{ID: 7}, {ID: 8}}},
+		{ID: 13, Priority: 50, MatchType: utils.MatchPrefix, Pattern: "kopi", CategoryID: &coffee},
+	})
+
+	result := engine.Evaluate(Transaction{Description: "INDOMARET JKT 01", Amount: money.FromInt(125000), TransactionType: 2})
+	if result.CategoryID == nil || *result.CategoryID != groceries {
+		t.Errorf("expected groceries, got %v", result.CategoryID)
+	}
+	if result.PayeeID == nil || *result.PayeeID != 5 {
+		t.Errorf("expected payee 5, got %v", result.PayeeID)
+	}
+	if len(result.TagIDs) != 2 || result.TagIDs[0] != 7 || result.TagIDs[1] != 8 {
+		t.Errorf("expected tags [7 8], got %v", result.TagIDs)
+	}
+
+	result = engine.Evaluate(Transaction{Description: "INDOMARET JKT 01", Amount: money.FromInt(500000), TransactionType: 2})
+	if result.CategoryID == nil || *result.CategoryID != dining {
+		t.Errorf("expected the amount limit to be exclusive, got %v", result.CategoryID)
+	}
+
+	result = engine.Evaluate(Transaction{Description: "Gaji", Amount: money.FromInt(100), TransactionType: 1})
+	if result.CategoryID != nil || result.PayeeID != nil || len(result.RuleIDs) != 0 {
+		t.Errorf("expected no match, got %+v", result)
+	}
+}
+
+func TestCompileRejectsIncompleteRules(t *testing.T) {
+	category := uint(1)
+	cases := []models.CategoryRule{
+		{Pattern: "grab"},
+		{CategoryID: &category},
+		{CategoryID: &category, MinAmount: amountPtr(100), MaxAmount: amountPtr(100)},
+		{CategoryID: &category, MatchType: utils.MatchRegex, Pattern: "("},
+	}
+	for i, rule := range cases {
+		if _, err := Compile(rule); err == nil {
+			t.Errorf("case %d: expected an error", i)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"my-api/config"
+	"my-api/repositories"
+	"my-api/services"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// reconcile recomputes every wallet balance from its history and reports the
+// ones that disagree with the stored balance. With -fix it posts correcting
+// adjustment entries. It exits with status 1 while discrepancies remain.
+func main() {
+	userFlag := flag.Uint("user", 0, "Only check this user's wallets")
+	assetsFlag := flag.String("assets", "", "Comma-separated wallet IDs to check")
+	fix := flag.Bool("fix", false, "Post correcting adjustment entries")
+	all := flag.Bool("all", false, "List every wallet, not only discrepancies")
+	flag.Parse()
+
+	var userID *uint
+	if *userFlag != 0 {
+		id := *userFlag
+		userID = &id
+	}
+
+	var assetIDs []uint64
+	if *assetsFlag != "" {
+		for _, part := range strings.Split(*assetsFlag, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				log.Fatalf("Invalid wallet ID %q", part)
+			}
+			assetIDs = append(assetIDs, id)
+		}
+	}
+
+	// Initialize database connection
+	config.ConnectDatabase()
+	service := services.NewReconciliationService(
+		repositories.NewReconciliationRepository(config.DB),
+		repositories.NewLedgerRepository(config.DB),
+	)
+
+	check := service.Check
+	if *fix {
+		check = service.Fix
+	}
+	report, err := check(userID, assetIDs)
+	if err != nil {
+		log.Fatal("Reconciliation failed:", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "USER\tWALLET\tCURRENCY\tSTORED\tEXPECTED\tLEDGER\tDISCREPANCY\tADJUSTED\t")
+	for _, a := range report.Assets {
+		if !*all && a.Discrepancy == 0 && a.LedgerDiscrepancy == 0 && a.Adjusted == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%d\t%d %s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			a.UserID, a.AssetID, a.AssetName, a.Currency,
+			a.StoredBalance, a.ExpectedBalance, a.LedgerBalance, a.Discrepancy, a.Adjusted)
+	}
+	w.Flush()
+
+	log.Printf("Checked %d wallets, %d with discrepancies", report.Checked, report.Discrepancies)
+	if report.Discrepancies > 0 {
+		os.Exit(1)
+	}
+}
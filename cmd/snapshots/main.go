@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"my-api/config"
+	"my-api/repositories"
+	"my-api/services"
+	"time"
+)
+
+// snapshots records daily asset balances up to today. Without -from it
+// continues each asset from its newest snapshot and backfills assets that
+// have none from their history; -from rebuilds every day since that date.
+func main() {
+	userFlag := flag.Uint("user", 0, "Only snapshot this user's assets")
+	fromFlag := flag.String("from", "", "Rebuild snapshots from this date (YYYY-MM-DD)")
+	flag.Parse()
+
+	var userID *uint
+	if *userFlag != 0 {
+		id := *userFlag
+		userID = &id
+	}
+
+	var from *time.Time
+	if *fromFlag != "" {
+		parsed, err := time.Parse("2006-01-02", *fromFlag)
+		if err != nil {
+			log.Fatalf("Invalid -from date %q, use YYYY-MM-DD", *fromFlag)
+		}
+		from = &parsed
+	}
+
+	// Initialize database connection
+	config.ConnectDatabase()
+	assetRepo := repositories.NewAssetRepository(config.DB)
+	service := services.NewSnapshotService(
+		repositories.NewSnapshotRepository(config.DB),
+		services.NewInvestmentService(repositories.NewInvestmentRepository(config.DB), assetRepo),
+	)
+
+	written, err := service.Run(userID, from, time.Now())
+	if err != nil {
+		log.Fatal("Snapshot run failed:", err)
+	}
+	log.Printf("Wrote %d balance snapshots", written)
+}
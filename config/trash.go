@@ -0,0 +1,21 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// defaultTrashRetentionDays applies when TRASH_RETENTION_DAYS is unset or
+// not a positive number
+const defaultTrashRetentionDays = 30
+
+// TrashRetention is how long deleted transactions, wallets and categories
+// stay in the trash before they are purged for good
+func TrashRetention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = defaultTrashRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
@@ -46,6 +46,50 @@ func (ctrl *AnalyticsController) GetSpendingByCategory(c *gin.Context) {
 	utils.JSONSuccess(c, "Spending by category retrieved successfully", result)
 }
 
+func (ctrl *AnalyticsController) GetSpendingByTag(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req dto.AnalyticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	result, err := ctrl.service.GetSpendingByTag(userID.(uint), &req)
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Spending by tag retrieved successfully", result)
+}
+
+func (ctrl *AnalyticsController) GetSpendingByPayee(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req dto.AnalyticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	result, err := ctrl.service.GetSpendingByPayee(userID.(uint), &req)
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Spending by payee retrieved successfully", result)
+}
+
 func (ctrl *AnalyticsController) GetIncomeVsExpense(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -282,3 +326,27 @@ func (ctrl *AnalyticsController) GetCategoryTrend(c *gin.Context) {
 
 	utils.JSONSuccess(c, "Category trend retrieved successfully", result)
 }
+
+// GetNetWorth returns assets, liabilities and net worth per period from the
+// daily balance snapshots
+func (ctrl *AnalyticsController) GetNetWorth(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req dto.AnalyticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	result, err := ctrl.service.GetNetWorth(userID.(uint), &req)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Net worth retrieved successfully", result)
+}
@@ -10,11 +10,12 @@ import (
 )
 
 type AssetController struct {
-    service *services.AssetService
+    service       *services.AssetService
+    budgetService services.BudgetService
 }
 
-func NewAssetController(service *services.AssetService) *AssetController {
-    return &AssetController{service: service}
+func NewAssetController(service *services.AssetService, budgetService services.BudgetService) *AssetController {
+    return &AssetController{service: service, budgetService: budgetService}
 }
 
 func (ac *AssetController) ListAssets(c *gin.Context) {
@@ -121,9 +122,54 @@ func (ac *AssetController) DeleteAsset(c *gin.Context) {
         utils.JSONError(c, http.StatusBadRequest, err.Error())
         return
     }
+    // The wallet's transactions no longer count towards budgets
+    ac.budgetService.CheckBudgetAlerts(userID.(uint))
     utils.JSONSuccess(c, "Asset deleted successfully", nil)
 }
 
+// ListDeletedAssets lists the wallets in the trash
+func (ac *AssetController) ListDeletedAssets(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+        return
+    }
+
+    assets, err := ac.service.ListDeletedAssets(userID.(uint))
+    if err != nil {
+        utils.JSONError(c, http.StatusInternalServerError, err.Error())
+        return
+    }
+    utils.JSONSuccess(c, "Deleted assets retrieved successfully", assets)
+}
+
+// RestoreAsset takes a wallet out of the trash
+func (ac *AssetController) RestoreAsset(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+        return
+    }
+
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        utils.JSONError(c, http.StatusBadRequest, "Invalid asset ID")
+        return
+    }
+
+    if err := ac.service.RestoreAsset(userID.(uint), uint(id)); err != nil {
+        if err.Error() == "asset not found in trash" {
+            utils.JSONError(c, http.StatusNotFound, err.Error())
+            return
+        }
+        utils.JSONError(c, http.StatusInternalServerError, err.Error())
+        return
+    }
+    // The wallet's transactions count towards budgets again
+    ac.budgetService.CheckBudgetAlerts(userID.(uint))
+    utils.JSONSuccess(c, "Asset restored successfully", nil)
+}
+
 func (ac *AssetController) Summary(c *gin.Context) {
     userID, exists := c.Get("user_id")
     if !exists {
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"mime"
+	"my-api/services"
+	"my-api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttachmentController struct {
+	service services.AttachmentService
+}
+
+func NewAttachmentController(service services.AttachmentService) *AttachmentController {
+	return &AttachmentController{service: service}
+}
+
+// UploadAttachment stores the multipart "file" field as a receipt of the transaction
+func (ctrl *AttachmentController) UploadAttachment(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid transaction ID")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "A file is required")
+		return
+	}
+	if fileHeader.Size > services.MaxAttachmentSize {
+		utils.JSONError(c, http.StatusBadRequest, "File is too large (max 10 MB)")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Failed to read uploaded file")
+		return
+	}
+	defer file.Close()
+
+	attachment, err := ctrl.service.UploadAttachment(userID.(uint), uint(transactionID), fileHeader.Filename, file)
+	if err != nil {
+		if err.Error() == "transaction not found" {
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Attachment uploaded successfully",
+		"data":    attachment,
+	})
+}
+
+func (ctrl *AttachmentController) GetAttachments(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid transaction ID")
+		return
+	}
+
+	attachments, err := ctrl.service.GetAttachments(userID.(uint), uint(transactionID))
+	if err != nil {
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Attachments retrieved successfully", attachments)
+}
+
+func (ctrl *AttachmentController) DownloadAttachment(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid attachment ID")
+		return
+	}
+
+	attachment, reader, err := ctrl.service.OpenAttachment(uint(id), userID.(uint))
+	if err != nil {
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	defer reader.Close()
+
+	disposition := mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName})
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, reader, map[string]string{
+		"Content-Disposition":    disposition,
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func (ctrl *AttachmentController) DeleteAttachment(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid attachment ID")
+		return
+	}
+
+	if err := ctrl.service.DeleteAttachment(uint(id), userID.(uint)); err != nil {
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Attachment deleted successfully", nil)
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"github.com/gin-gonic/gin"
 	"my-api/dto"
+	"my-api/money"
 	"my-api/services"
 	"my-api/utils"
 	"net/http"
@@ -38,11 +39,31 @@ func (ctrl *BudgetController) CreateBudget(c *gin.Context) {
 	// Manually map fields with proper type conversion
 	if categoryID, ok := payload["category_id"].(float64); ok {
 		req.CategoryID = uint(categoryID)
+	}
+	if categoryIDs, ok := positiveIntList(payload["category_ids"]); ok {
+		for _, id := range categoryIDs {
+			req.CategoryIDs = append(req.CategoryIDs, uint(id))
+		}
 	} else {
-		utils.JSONError(c, http.StatusBadRequest, "category_id is required")
+		utils.JSONError(c, http.StatusBadRequest, "category_ids must be a list of IDs")
+		return
+	}
+	if req.CategoryID == 0 && len(req.CategoryIDs) == 0 {
+		utils.JSONError(c, http.StatusBadRequest, "category_id or category_ids is required")
 		return
 	}
 
+	if assetIDs, ok := positiveIntList(payload["asset_ids"]); ok {
+		req.AssetIDs = assetIDs
+	} else {
+		utils.JSONError(c, http.StatusBadRequest, "asset_ids must be a list of IDs")
+		return
+	}
+
+	if name, ok := payload["name"].(string); ok {
+		req.Name = name
+	}
+
 	amountVal, ok := payload["amount"]
 	if !ok {
 		utils.JSONError(c, http.StatusBadRequest, "amount is required")
@@ -51,11 +72,18 @@ func (ctrl *BudgetController) CreateBudget(c *gin.Context) {
 
 	switch v := amountVal.(type) {
 	case float64:
-		req.Amount = int(v)
+		req.Amount = money.FromFloat(v)
 	case int:
-		req.Amount = v
+		req.Amount = money.FromInt(int64(v))
 	case int64:
-		req.Amount = int(v)
+		req.Amount = money.FromInt(v)
+	case string:
+		amount, err := money.Parse(v)
+		if err != nil {
+			utils.JSONError(c, http.StatusBadRequest, "amount must be a number")
+			return
+		}
+		req.Amount = amount
 	default:
 		utils.JSONError(c, http.StatusBadRequest, "amount must be a number")
 		return
@@ -89,10 +117,31 @@ func (ctrl *BudgetController) CreateBudget(c *gin.Context) {
 		return
 	}
 
+	if recurring, ok := payload["recurring"].(bool); ok {
+		req.Recurring = &recurring
+	}
+
 	if alertAt, ok := payload["alert_at"].(float64); ok {
 		req.AlertAt = int(alertAt)
 	}
 
+	if thresholds, ok := positiveIntList(payload["alert_thresholds"]); ok {
+		for _, threshold := range thresholds {
+			req.AlertThresholds = append(req.AlertThresholds, int(threshold))
+		}
+	} else {
+		utils.JSONError(c, http.StatusBadRequest, "alert_thresholds must be a list of percentages")
+		return
+	}
+
+	if projectedAlert, ok := payload["projected_alert"].(bool); ok {
+		req.ProjectedAlert = projectedAlert
+	}
+
+	if rollover, ok := payload["rollover"].(bool); ok {
+		req.Rollover = rollover
+	}
+
 	if description, ok := payload["description"].(string); ok {
 		req.Description = description
 	}
@@ -215,6 +264,32 @@ func (ctrl *BudgetController) GetBudgetStatus(c *gin.Context) {
 	utils.JSONSuccess(c, "Budget status retrieved successfully", budgets)
 }
 
+func (ctrl *BudgetController) GetBudgetHistory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid budget ID")
+		return
+	}
+
+	history, err := ctrl.service.GetBudgetHistory(uint(id), userID.(uint))
+	if err != nil {
+		if err.Error() == "budget not found" {
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Budget history retrieved successfully", history)
+}
+
 func (ctrl *BudgetController) GetAlerts(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -272,3 +347,24 @@ func (ctrl *BudgetController) MarkAllAlertsAsRead(c *gin.Context) {
 
 	utils.JSONSuccess(c, "All alerts marked as read", nil)
 }
+
+// positiveIntList reads a JSON list of positive whole numbers, such as IDs
+// or percentages. A missing value is an empty list.
+func positiveIntList(value interface{}) ([]uint64, bool) {
+	if value == nil {
+		return nil, true
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	values := make([]uint64, len(items))
+	for i, item := range items {
+		n, ok := item.(float64)
+		if !ok || n <= 0 || n != float64(uint64(n)) {
+			return nil, false
+		}
+		values[i] = uint64(n)
+	}
+	return values, true
+}
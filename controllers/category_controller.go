@@ -62,12 +62,65 @@ func CreateCategory(c *gin.Context) {
 
 func DeleteCategory(c *gin.Context) {
     // Get the user ID from the JWT token
+    userID, exists := c.Get("user_id")
+    if !exists {
+        utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+        return
+    }
+
     var category models.Category
-    if err := config.DB.Where("id = ?", c.Param("id")).First(&category).Error; err != nil {
-        utils.JSONError(c, http.StatusBadRequest, "Category not found")
+    if err := config.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&category).Error; err != nil {
+        utils.JSONError(c, http.StatusNotFound, "Category not found")
+        return
+    }
+    // Soft delete: the category stays in the trash until it is purged
+    if err := config.DB.Delete(&category).Error; err != nil {
+        utils.JSONError(c, http.StatusInternalServerError, "Failed to delete category")
         return
     }
-    config.DB.Delete(&category)
 
     utils.JSONSuccess(c, "Category successfully deleted", nil)
+}
+
+// GetDeletedCategories lists the user's categories in the trash
+func GetDeletedCategories(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+        return
+    }
+
+    var categories []models.Category
+    if err := config.DB.Unscoped().
+        Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+        Order("deleted_at DESC, id DESC").
+        Find(&categories).Error; err != nil {
+        utils.JSONError(c, http.StatusInternalServerError, "Failed to fetch deleted categories")
+        return
+    }
+
+    utils.JSONSuccess(c, "Deleted categories successfully retrieved", categories)
+}
+
+// RestoreCategory takes one of the user's categories out of the trash
+func RestoreCategory(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+        return
+    }
+
+    result := config.DB.Unscoped().Model(&models.Category{}).
+        Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", c.Param("id"), userID).
+        Update("deleted_at", nil)
+    if result.Error != nil {
+        utils.JSONError(c, http.StatusInternalServerError, "Failed to restore category")
+        return
+    }
+    if result.RowsAffected == 0 {
+        utils.JSONError(c, http.StatusNotFound, "Category not found in trash")
+        return
+    }
+
+    utils.JSONSuccess(c, "Category successfully restored", nil)
 }
\ No newline at end of file
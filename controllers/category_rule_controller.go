@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"my-api/dto"
+	"my-api/services"
+	"my-api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryRuleController struct {
+	service services.CategoryRuleService
+}
+
+func NewCategoryRuleController(service services.CategoryRuleService) *CategoryRuleController {
+	return &CategoryRuleController{service: service}
+}
+
+func (ctrl *CategoryRuleController) CreateRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req dto.CategoryRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	rule, err := ctrl.service.CreateRule(userID.(uint), &req)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Category rule created successfully",
+		"data":    rule,
+	})
+}
+
+func (ctrl *CategoryRuleController) GetRules(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	rules, err := ctrl.service.GetRules(userID.(uint))
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Category rules retrieved successfully", rules)
+}
+
+func (ctrl *CategoryRuleController) GetRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid category rule ID")
+		return
+	}
+
+	rule, err := ctrl.service.GetRuleByID(uint(id), userID.(uint))
+	if err != nil {
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Category rule retrieved successfully", rule)
+}
+
+func (ctrl *CategoryRuleController) UpdateRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid category rule ID")
+		return
+	}
+
+	var req dto.CategoryRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	rule, err := ctrl.service.UpdateRule(uint(id), userID.(uint), &req)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Category rule updated successfully", rule)
+}
+
+func (ctrl *CategoryRuleController) DeleteRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid category rule ID")
+		return
+	}
+
+	if err := ctrl.service.DeleteRule(uint(id), userID.(uint)); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Category rule deleted successfully", nil)
+}
+
+// DryRun shows which existing transactions the rule would change. The number
+// of listed changes is capped by ?limit (default 100, max 1000).
+func (ctrl *CategoryRuleController) DryRun(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid category rule ID")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	result, err := ctrl.service.DryRun(uint(id), userID.(uint), limit)
+	if err != nil {
+		respondCategoryRuleRunError(c, err)
+		return
+	}
+
+	utils.JSONSuccess(c, "Category rule dry run completed", result)
+}
+
+// Apply re-categorizes the existing transactions that match the rule
+func (ctrl *CategoryRuleController) Apply(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid category rule ID")
+		return
+	}
+
+	result, err := ctrl.service.Apply(uint(id), userID.(uint))
+	if err != nil {
+		respondCategoryRuleRunError(c, err)
+		return
+	}
+
+	utils.JSONSuccess(c, "Category rule applied successfully", result)
+}
+
+func respondCategoryRuleRunError(c *gin.Context, err error) {
+	if err.Error() == "category rule not found" {
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	utils.JSONError(c, http.StatusInternalServerError, err.Error())
+}
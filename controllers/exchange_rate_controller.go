@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"my-api/dto"
+	"my-api/services"
+	"my-api/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExchangeRateController struct {
+	service services.ExchangeRateService
+}
+
+func NewExchangeRateController(service services.ExchangeRateService) *ExchangeRateController {
+	return &ExchangeRateController{service: service}
+}
+
+// GetRates lists rates, optionally filtered by currency and a date range
+func (ctrl *ExchangeRateController) GetRates(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var startDate, endDate *time.Time
+	if value := c.Query("start_date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.JSONError(c, http.StatusBadRequest, "Invalid start_date format, use YYYY-MM-DD")
+			return
+		}
+		startDate = &parsed
+	}
+	if value := c.Query("end_date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.JSONError(c, http.StatusBadRequest, "Invalid end_date format, use YYYY-MM-DD")
+			return
+		}
+		endDate = &parsed
+	}
+
+	rates, err := ctrl.service.GetRates(userID.(uint), c.Query("currency"), startDate, endDate)
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Exchange rates retrieved successfully", rates)
+}
+
+// CreateRate stores a manual rate, replacing any rate for the same pair and date
+func (ctrl *ExchangeRateController) CreateRate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req dto.CreateExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	rate, err := ctrl.service.CreateRate(userID.(uint), &req)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Exchange rate saved successfully",
+		"data":    rate,
+	})
+}
+
+// ImportRates handles a multipart CSV upload with a "file" field and an
+// optional "date_format" field
+func (ctrl *ExchangeRateController) ImportRates(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "A CSV file is required")
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		utils.JSONError(c, http.StatusBadRequest, "File is too large (max 5 MB)")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Failed to read uploaded file")
+		return
+	}
+	defer file.Close()
+
+	result, err := ctrl.service.ImportCSV(userID.(uint), file, c.PostForm("date_format"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Exchange rates imported successfully", result)
+}
+
+func (ctrl *ExchangeRateController) DeleteRate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid exchange rate ID")
+		return
+	}
+
+	if err := ctrl.service.DeleteRate(uint(id), userID.(uint)); err != nil {
+		if err.Error() == "exchange rate not found" {
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Exchange rate deleted successfully", nil)
+}
@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"io"
+	"mime/multipart"
+	"my-api/dto"
+	"my-api/services"
+	"my-api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize limits uploaded statement files to 5 MB
+const maxImportFileSize = 5 << 20
+
+type ImportController struct {
+	service services.ImportService
+}
+
+func NewImportController(service services.ImportService) *ImportController {
+	return &ImportController{service: service}
+}
+
+// ImportCSV handles a multipart upload with a "file" field. The default is a
+// dry-run preview; pass commit=true to post the new rows.
+func (ctrl *ImportController) ImportCSV(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req dto.ImportCSVRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	file, ok := openImportFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	result, err := ctrl.service.ImportCSV(userID.(uint), &req, file)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondImportResult(c, result)
+}
+
+// ImportOFX handles a multipart OFX upload with the same preview/commit flow
+func (ctrl *ImportController) ImportOFX(c *gin.Context) {
+	ctrl.importFile(c, ctrl.service.ImportOFX)
+}
+
+// ImportQIF handles a multipart QIF upload with the same preview/commit flow
+func (ctrl *ImportController) ImportQIF(c *gin.Context) {
+	ctrl.importFile(c, ctrl.service.ImportQIF)
+}
+
+func (ctrl *ImportController) importFile(c *gin.Context, importFn func(uint, *dto.ImportFileRequest, io.Reader) (*dto.ImportResultResponse, error)) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req dto.ImportFileRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	file, ok := openImportFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	result, err := importFn(userID.(uint), &req, file)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondImportResult(c, result)
+}
+
+// openImportFile opens the uploaded "file" field, writing the error response
+// itself when the upload is missing or too large
+func openImportFile(c *gin.Context) (multipart.File, bool) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "A statement file is required")
+		return nil, false
+	}
+	if fileHeader.Size > maxImportFileSize {
+		utils.JSONError(c, http.StatusBadRequest, "File is too large (max 5 MB)")
+		return nil, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Failed to read uploaded file")
+		return nil, false
+	}
+	return file, true
+}
+
+func respondImportResult(c *gin.Context, result *dto.ImportResultResponse) {
+	if result.Committed {
+		utils.JSONSuccess(c, "Statement imported successfully", result)
+		return
+	}
+	utils.JSONSuccess(c, "Statement preview generated successfully", result)
+}
+
+func (ctrl *ImportController) CreateProfile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req dto.ImportProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	profile, err := ctrl.service.CreateProfile(userID.(uint), &req)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Import profile created successfully",
+		"data":    profile,
+	})
+}
+
+func (ctrl *ImportController) GetProfiles(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	profiles, err := ctrl.service.GetProfiles(userID.(uint))
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Import profiles retrieved successfully", profiles)
+}
+
+func (ctrl *ImportController) GetProfile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid import profile ID")
+		return
+	}
+
+	profile, err := ctrl.service.GetProfileByID(uint(id), userID.(uint))
+	if err != nil {
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Import profile retrieved successfully", profile)
+}
+
+func (ctrl *ImportController) UpdateProfile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid import profile ID")
+		return
+	}
+
+	var req dto.ImportProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	profile, err := ctrl.service.UpdateProfile(uint(id), userID.(uint), &req)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Import profile updated successfully", profile)
+}
+
+func (ctrl *ImportController) DeleteProfile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid import profile ID")
+		return
+	}
+
+	if err := ctrl.service.DeleteProfile(uint(id), userID.(uint)); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Import profile deleted successfully", nil)
+}
@@ -0,0 +1,388 @@
+package controllers
+
+import (
+	"my-api/dto"
+	"my-api/services"
+	"my-api/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvestmentController struct {
+	service services.InvestmentService
+}
+
+func NewInvestmentController(service services.InvestmentService) *InvestmentController {
+	return &InvestmentController{service: service}
+}
+
+func (ctrl *InvestmentController) GetInstruments(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	instruments, err := ctrl.service.GetInstruments(userID.(uint))
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Instruments retrieved successfully", instruments)
+}
+
+func (ctrl *InvestmentController) CreateInstrument(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req dto.CreateInstrumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	instrument, err := ctrl.service.CreateInstrument(userID.(uint), &req)
+	if err != nil {
+		if err.Error() == "instrument with this symbol already exists" {
+			utils.JSONError(c, http.StatusConflict, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Instrument created successfully",
+		"data":    instrument,
+	})
+}
+
+func (ctrl *InvestmentController) UpdateInstrument(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid instrument ID")
+		return
+	}
+
+	var req dto.UpdateInstrumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	instrument, err := ctrl.service.UpdateInstrument(uint(id), userID.(uint), &req)
+	if err != nil {
+		if err.Error() == "instrument not found" {
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Instrument updated successfully", instrument)
+}
+
+func (ctrl *InvestmentController) DeleteInstrument(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid instrument ID")
+		return
+	}
+
+	if err := ctrl.service.DeleteInstrument(uint(id), userID.(uint)); err != nil {
+		switch err.Error() {
+		case "instrument not found":
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+		case "instrument has holding lots":
+			utils.JSONError(c, http.StatusConflict, err.Error())
+		default:
+			utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.JSONSuccess(c, "Instrument deleted successfully", nil)
+}
+
+// GetPrices lists an instrument's prices, optionally within a date range
+func (ctrl *InvestmentController) GetPrices(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid instrument ID")
+		return
+	}
+
+	var startDate, endDate *time.Time
+	if value := c.Query("start_date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.JSONError(c, http.StatusBadRequest, "Invalid start_date format, use YYYY-MM-DD")
+			return
+		}
+		startDate = &parsed
+	}
+	if value := c.Query("end_date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.JSONError(c, http.StatusBadRequest, "Invalid end_date format, use YYYY-MM-DD")
+			return
+		}
+		endDate = &parsed
+	}
+
+	prices, err := ctrl.service.GetPrices(userID.(uint), uint(id), startDate, endDate)
+	if err != nil {
+		if err.Error() == "instrument not found" {
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Prices retrieved successfully", prices)
+}
+
+// CreatePrice stores a manual price, replacing any price for the same date
+func (ctrl *InvestmentController) CreatePrice(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid instrument ID")
+		return
+	}
+
+	var req dto.CreateInstrumentPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	price, err := ctrl.service.CreatePrice(userID.(uint), uint(id), &req)
+	if err != nil {
+		if err.Error() == "instrument not found" {
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Price saved successfully",
+		"data":    price,
+	})
+}
+
+// ImportPrices handles a multipart CSV upload with a "file" field and an
+// optional "date_format" field
+func (ctrl *InvestmentController) ImportPrices(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "A CSV file is required")
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		utils.JSONError(c, http.StatusBadRequest, "File is too large (max 5 MB)")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Failed to read uploaded file")
+		return
+	}
+	defer file.Close()
+
+	result, err := ctrl.service.ImportPricesCSV(userID.(uint), file, c.PostForm("date_format"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Prices imported successfully", result)
+}
+
+func (ctrl *InvestmentController) DeletePrice(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid price ID")
+		return
+	}
+
+	if err := ctrl.service.DeletePrice(uint(id), userID.(uint)); err != nil {
+		if err.Error() == "price not found" {
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Price deleted successfully", nil)
+}
+
+// GetHoldings returns the wallet's positions with cost basis, market value
+// and gains
+func (ctrl *InvestmentController) GetHoldings(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid asset ID")
+		return
+	}
+
+	holdings, err := ctrl.service.GetHoldings(userID.(uint), assetID)
+	if err != nil {
+		if err.Error() == "asset not found" {
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Holdings retrieved successfully", holdings)
+}
+
+func (ctrl *InvestmentController) GetLots(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid asset ID")
+		return
+	}
+
+	lots, err := ctrl.service.GetLots(userID.(uint), assetID)
+	if err != nil {
+		if err.Error() == "asset not found" {
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Holding lots retrieved successfully", lots)
+}
+
+// CreateLot records a buy or sell of an instrument in the wallet
+func (ctrl *InvestmentController) CreateLot(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid asset ID")
+		return
+	}
+
+	var req dto.CreateHoldingLotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	lot, err := ctrl.service.CreateLot(userID.(uint), assetID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "asset not found", "instrument not found":
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+		default:
+			utils.JSONError(c, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Holding lot created successfully",
+		"data":    lot,
+	})
+}
+
+func (ctrl *InvestmentController) DeleteLot(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid lot ID")
+		return
+	}
+
+	if err := ctrl.service.DeleteLot(uint(id), userID.(uint)); err != nil {
+		switch err.Error() {
+		case "lot not found":
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+		case "insufficient quantity":
+			utils.JSONError(c, http.StatusConflict, "Removing this lot would leave more units sold than bought")
+		default:
+			utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.JSONSuccess(c, "Holding lot deleted successfully", nil)
+}
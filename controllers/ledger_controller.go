@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"my-api/dto"
+	"my-api/services"
+	"my-api/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerController struct {
+	service services.LedgerService
+}
+
+func NewLedgerController(service services.LedgerService) *LedgerController {
+	return &LedgerController{service: service}
+}
+
+// GetStatement returns the wallet's running-balance statement, oldest first,
+// optionally limited by start_date and end_date
+func (ctrl *LedgerController) GetStatement(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid asset ID")
+		return
+	}
+
+	filter := &dto.LedgerFilter{}
+	if value := c.Query("start_date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.JSONError(c, http.StatusBadRequest, "Invalid start_date format, use YYYY-MM-DD")
+			return
+		}
+		filter.StartDate = &parsed
+	}
+	if value := c.Query("end_date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.JSONError(c, http.StatusBadRequest, "Invalid end_date format, use YYYY-MM-DD")
+			return
+		}
+		filter.EndDate = &parsed
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize := c.Query("page_size")
+	if pageSize == "" {
+		pageSize = c.DefaultQuery("limit", "50")
+	}
+	limit, _ := strconv.Atoi(pageSize)
+
+	statement, pagination, err := ctrl.service.GetStatement(assetID, userID.(uint), filter, page, limit)
+	if err != nil {
+		if err.Error() == "asset not found" {
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "Ledger retrieved successfully",
+		"data":       statement,
+		"pagination": pagination,
+	})
+}
+
+// GetCreditStatement returns the last closed statement of a credit card or
+// loan, as of today or the as_of date
+func (ctrl *LedgerController) GetCreditStatement(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid asset ID")
+		return
+	}
+
+	now := time.Now()
+	asOf := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if value := c.Query("as_of"); value != "" {
+		if asOf, err = time.Parse("2006-01-02", value); err != nil {
+			utils.JSONError(c, http.StatusBadRequest, "Invalid as_of format, use YYYY-MM-DD")
+			return
+		}
+	}
+
+	statement, err := ctrl.service.GetCreditStatement(assetID, userID.(uint), asOf)
+	if err != nil {
+		if err.Error() == "asset not found" {
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Statement retrieved successfully", statement)
+}
@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"my-api/dto"
+	"my-api/services"
+	"my-api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PayeeController struct {
+	service services.PayeeService
+}
+
+func NewPayeeController(service services.PayeeService) *PayeeController {
+	return &PayeeController{service: service}
+}
+
+func (ctrl *PayeeController) CreatePayee(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req dto.CreatePayeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	payee, err := ctrl.service.CreatePayee(userID.(uint), &req)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Payee created successfully",
+		"data":    payee,
+	})
+}
+
+func (ctrl *PayeeController) GetPayees(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	payees, err := ctrl.service.GetPayees(userID.(uint), c.Query("search"))
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Payees retrieved successfully", payees)
+}
+
+func (ctrl *PayeeController) GetPayee(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid payee ID")
+		return
+	}
+
+	payee, err := ctrl.service.GetPayeeByID(uint(id), userID.(uint))
+	if err != nil {
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Payee retrieved successfully", payee)
+}
+
+func (ctrl *PayeeController) UpdatePayee(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid payee ID")
+		return
+	}
+
+	var req dto.UpdatePayeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	payee, err := ctrl.service.UpdatePayee(uint(id), userID.(uint), &req)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Payee updated successfully", payee)
+}
+
+func (ctrl *PayeeController) DeletePayee(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid payee ID")
+		return
+	}
+
+	if err := ctrl.service.DeletePayee(uint(id), userID.(uint)); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Payee deleted successfully", nil)
+}
+
+func (ctrl *PayeeController) CreateRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req dto.PayeeRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	rule, err := ctrl.service.CreateRule(userID.(uint), &req)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Payee rule created successfully",
+		"data":    rule,
+	})
+}
+
+func (ctrl *PayeeController) GetRules(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	rules, err := ctrl.service.GetRules(userID.(uint))
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Payee rules retrieved successfully", rules)
+}
+
+func (ctrl *PayeeController) UpdateRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid payee rule ID")
+		return
+	}
+
+	var req dto.PayeeRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	rule, err := ctrl.service.UpdateRule(uint(id), userID.(uint), &req)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Payee rule updated successfully", rule)
+}
+
+func (ctrl *PayeeController) DeleteRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid payee rule ID")
+		return
+	}
+
+	if err := ctrl.service.DeleteRule(uint(id), userID.(uint)); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Payee rule deleted successfully", nil)
+}
+
+// ApplyRules assigns payees to existing transactions that have none yet
+func (ctrl *PayeeController) ApplyRules(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	result, err := ctrl.service.ApplyRules(userID.(uint))
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Payee rules applied successfully", result)
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"my-api/dto"
+	"my-api/services"
+	"my-api/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReconciliationController struct {
+	service services.ReconciliationService
+}
+
+func NewReconciliationController(service services.ReconciliationService) *ReconciliationController {
+	return &ReconciliationController{service: service}
+}
+
+// CheckBalances reports wallets whose stored balance does not match their
+// history. asset_id=1,2 limits the check to some wallets.
+func (ctrl *ReconciliationController) CheckBalances(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var assetIDs []uint64
+	if value := c.Query("asset_id"); value != "" {
+		for _, part := range strings.Split(value, ",") {
+			assetID, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				utils.JSONError(c, http.StatusBadRequest, "Invalid asset ID")
+				return
+			}
+			assetIDs = append(assetIDs, assetID)
+		}
+	}
+
+	uid := userID.(uint)
+	report, err := ctrl.service.Check(&uid, assetIDs)
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Balances checked successfully", report)
+}
+
+// FixDiscrepancies posts correcting adjustments for the wallets that
+// CheckBalances reports
+func (ctrl *ReconciliationController) FixDiscrepancies(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req dto.FixDiscrepanciesRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+	}
+
+	uid := userID.(uint)
+	report, err := ctrl.service.Fix(&uid, req.AssetIDs)
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Discrepancies adjusted successfully", report)
+}
+
+func (ctrl *ReconciliationController) GetReconciliations(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid asset ID")
+		return
+	}
+
+	reconciliations, err := ctrl.service.GetReconciliations(userID.(uint), assetID)
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Reconciliations retrieved successfully", reconciliations)
+}
+
+// CreateReconciliation marks the wallet as reconciled against a statement
+// balance as of the statement date
+func (ctrl *ReconciliationController) CreateReconciliation(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid asset ID")
+		return
+	}
+
+	var req dto.CreateReconciliationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	reconciliation, err := ctrl.service.CreateReconciliation(userID.(uint), assetID, &req)
+	if err != nil {
+		if err.Error() == "asset not found" {
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Wallet reconciled successfully",
+		"data":    reconciliation,
+	})
+}
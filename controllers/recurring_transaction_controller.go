@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"my-api/dto"
+	"my-api/services"
+	"my-api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecurringTransactionController struct {
+	service services.RecurringTransactionService
+}
+
+func NewRecurringTransactionController(service services.RecurringTransactionService) *RecurringTransactionController {
+	return &RecurringTransactionController{service: service}
+}
+
+func (ctrl *RecurringTransactionController) CreateRecurringTransaction(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req dto.CreateRecurringTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	recurring, err := ctrl.service.CreateRecurringTransaction(userID.(uint), &req)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Recurring transaction created successfully",
+		"data":    recurring,
+	})
+}
+
+func (ctrl *RecurringTransactionController) GetRecurringTransactions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var filter dto.RecurringTransactionFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	result, err := ctrl.service.GetAllRecurringTransactions(userID.(uint), &filter)
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Recurring transactions retrieved successfully", result)
+}
+
+func (ctrl *RecurringTransactionController) GetRecurringTransaction(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid recurring transaction ID")
+		return
+	}
+
+	recurring, err := ctrl.service.GetRecurringTransactionByID(uint(id), userID.(uint))
+	if err != nil {
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Recurring transaction retrieved successfully", recurring)
+}
+
+func (ctrl *RecurringTransactionController) UpdateRecurringTransaction(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid recurring transaction ID")
+		return
+	}
+
+	var req dto.UpdateRecurringTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	recurring, err := ctrl.service.UpdateRecurringTransaction(uint(id), userID.(uint), &req)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Recurring transaction updated successfully", recurring)
+}
+
+func (ctrl *RecurringTransactionController) DeleteRecurringTransaction(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid recurring transaction ID")
+		return
+	}
+
+	if err := ctrl.service.DeleteRecurringTransaction(uint(id), userID.(uint)); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Recurring transaction deleted successfully", nil)
+}
+
+func (ctrl *RecurringTransactionController) GetUpcomingOccurrences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid recurring transaction ID")
+		return
+	}
+
+	count := 5
+	if n := c.Query("count"); n != "" {
+		if parsed, err := strconv.Atoi(n); err == nil && parsed > 0 && parsed <= 100 {
+			count = parsed
+		}
+	}
+
+	occurrences, err := ctrl.service.GetUpcomingOccurrences(uint(id), userID.(uint), count)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Upcoming occurrences retrieved successfully", occurrences)
+}
+
+func (ctrl *RecurringTransactionController) SkipOccurrence(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid recurring transaction ID")
+		return
+	}
+
+	var req dto.SkipOccurrenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := ctrl.service.SkipOccurrence(uint(id), userID.(uint), req.Date.Time); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Occurrence skipped successfully", nil)
+}
+
+func (ctrl *RecurringTransactionController) RetryOccurrence(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.JSONError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid recurring transaction ID")
+		return
+	}
+
+	var req dto.RetryOccurrenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := ctrl.service.RetryOccurrence(uint(id), userID.(uint), req.Date.Time); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.JSONSuccess(c, "Occurrence posted successfully", nil)
+}
@@ -3,6 +3,7 @@ package controllers
 import (
     "my-api/config"
     "my-api/models"
+    "my-api/money"
     "my-api/services"
     "github.com/gin-gonic/gin"
     "my-api/utils"
@@ -193,9 +194,9 @@ func (ctrl *TransactionController) CreateTransaction(c *gin.Context) {
     transaction.BankID = uint(payload["BankID"].(float64))
 
     
-    // Convert string amount to int
+    // Convert string amount to money.Amount
     if amountStr, ok := payload["Amount"].(string); ok {
-        amount, err := strconv.Atoi(amountStr)
+        amount, err := money.Parse(amountStr)
         if err != nil {
             utils.JSONError(c, http.StatusBadRequest, "Invalid amount format")
             return
@@ -203,7 +204,7 @@ func (ctrl *TransactionController) CreateTransaction(c *gin.Context) {
         transaction.Amount = amount
     } else if amount, ok := payload["Amount"].(float64); ok {
         // If sent as number instead of string
-        transaction.Amount = int(amount)
+        transaction.Amount = money.FromFloat(amount)
     }
 
     // Handle TransactionType - can be string or number
@@ -283,5 +284,8 @@ func (ctrl *TransactionController) DeleteTransaction(c *gin.Context) {
         return
     }
 
+    // A removed expense no longer counts towards budgets
+    ctrl.budgetService.CheckBudgetAlerts(userIDUint)
+
     utils.JSONSuccess(c, "Transaction deleted successfully", nil)
 }
@@ -1,12 +1,15 @@
 package controllers
 
 import (
+	"fmt"
 	"my-api/dto"
+	"my-api/exporters"
 	"my-api/models"
 	"my-api/services"
 	"my-api/utils"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -45,69 +48,62 @@ func (ctrl *TransactionV2Controller) GetTransactions(c *gin.Context) {
 	}
 	limit, _ := strconv.Atoi(pageSize)
 
-	var startDate, endDate *time.Time
-	var transactionType *int
-	var categoryID *uint
-	var assetID *uint64
+	filter := parseTransactionV2Filter(c)
 
-	if startDateStr := c.Query("start_date"); startDateStr != "" {
-		if parsed, err := time.Parse("2006-01-02", startDateStr); err == nil {
-			startDate = &parsed
-		}
-	}
+	transactions, pagination, err := ctrl.transactionService.GetTransactions(userIDUint, page, limit, filter)
 
-	if endDateStr := c.Query("end_date"); endDateStr != "" {
-		if parsed, err := time.Parse("2006-01-02", endDateStr); err == nil {
-			endDate = &parsed
-		}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch transactions"})
+		return
 	}
 
-	if txTypeStr := c.Query("transaction_type"); txTypeStr != "" {
-		var txType int
-		if txTypeStr == "Income" || txTypeStr == "income" {
-			txType = 1
-		} else if txTypeStr == "Expense" || txTypeStr == "expense" {
-			txType = 2
-		} else {
-			if parsed, err := strconv.Atoi(txTypeStr); err == nil {
-				txType = parsed
-			}
-		}
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "Transactions fetched successfully",
+		"data":       transactions,
+		"pagination": pagination,
+	})
+}
 
-		if txType == 1 || txType == 2 {
-			transactionType = &txType
-		}
+// ExportTransactions streams every transaction matching the list filters as
+// CSV, XLSX or JSON (format query parameter, default csv)
+func (ctrl *TransactionV2Controller) ExportTransactions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "User not authenticated"})
+		return
 	}
 
-	if catIDStr := c.Query("category_id"); catIDStr != "" {
-		if catID, err := strconv.ParseUint(catIDStr, 10, 32); err == nil {
-			temp := uint(catID)
-			categoryID = &temp
-		}
+	userIDUint, ok := userID.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Invalid user ID"})
+		return
 	}
 
-	if assetIDStr := c.Query("asset_id"); assetIDStr != "" {
-		if aID, err := strconv.ParseUint(assetIDStr, 10, 64); err == nil {
-			temp := aID
-			assetID = &temp
-		}
+	format, err := exporters.LookupFormat(c.DefaultQuery("format", "csv"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": err.Error()})
+		return
 	}
 
-	transactions, pagination, err := ctrl.transactionService.GetTransactions(
-		userIDUint, page, limit, startDate, endDate, transactionType, categoryID, assetID,
-	)
+	filter := parseTransactionV2Filter(c)
+	filename := fmt.Sprintf("transactions-%s.%s", time.Now().Format("20060102"), format.Extension)
+
+	c.Header("Content-Type", format.ContentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
 
+	writer, err := exporters.NewWriter(format, c.Writer)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch transactions"})
+		utils.LogErrorf("Failed to start transaction export for user %d: %v", userIDUint, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success":    true,
-		"message":    "Transactions fetched successfully",
-		"data":       transactions,
-		"pagination": pagination,
-	})
+	// Headers are already sent, so a failure can only be logged; the client
+	// sees a truncated file
+	if err := ctrl.transactionService.ExportTransactions(userIDUint, filter, writer); err != nil {
+		utils.LogErrorf("Transaction export failed for user %d: %v", userIDUint, err)
+	}
 }
 
 func (ctrl *TransactionV2Controller) GetTransactionByID(c *gin.Context) {
@@ -184,6 +180,10 @@ func (ctrl *TransactionV2Controller) CreateTransaction(c *gin.Context) {
 		TransactionType: transactionType,
 		Date:            utils.CustomTime{Time: date},
 		BankID:          0, // Optional for v2
+		PayeeID:         req.PayeeID,
+		Status:          req.Status,
+		Splits:          toSplitModels(req.Splits),
+		Tags:            toTagModels(req.TagIDs),
 	}
 
 	if err := ctrl.transactionService.CreateTransaction(transaction); err != nil {
@@ -199,6 +199,22 @@ func (ctrl *TransactionV2Controller) CreateTransaction(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "Asset does not belong to you"})
 			return
 		}
+		if isSplitValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": err.Error()})
+			return
+		}
+		if err.Error() == "tag not found" {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Tag not found"})
+			return
+		}
+		if err.Error() == "payee not found" {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Payee not found"})
+			return
+		}
+		if err.Error() == "category is required" {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Category is required when no category rule matches"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create transaction"})
 		return
 	}
@@ -259,11 +275,25 @@ func (ctrl *TransactionV2Controller) UpdateTransaction(c *gin.Context) {
 		Amount:          existing.Amount,
 		TransactionType: existing.TransactionType,
 		Date:            existing.Date,
+		PayeeID:         existing.PayeeID,
 		BankID:          0,
 	}
 
-	if req.Description != nil {
+	transaction.Splits = make([]models.TransactionSplit, len(existing.Splits))
+	for i, split := range existing.Splits {
+		transaction.Splits[i] = models.TransactionSplit{
+			CategoryID:  split.CategoryID,
+			Amount:      split.Amount,
+			Description: split.Description,
+		}
+	}
+
+	if req.Description != nil && *req.Description != existing.Description {
 		transaction.Description = *req.Description
+		transaction.PayeeID = nil // re-resolved from the payee rules
+	}
+	if req.PayeeID != nil {
+		transaction.PayeeID = req.PayeeID
 	}
 	if req.CategoryID != nil {
 		transaction.CategoryID = *req.CategoryID
@@ -281,6 +311,17 @@ func (ctrl *TransactionV2Controller) UpdateTransaction(c *gin.Context) {
 			transaction.TransactionType = 1
 		}
 	}
+	if req.Splits != nil {
+		transaction.Splits = toSplitModels(*req.Splits)
+	}
+
+	transaction.Tags = make([]models.Tag, len(existing.Tags))
+	for i, tag := range existing.Tags {
+		transaction.Tags[i] = models.Tag{ID: tag.ID}
+	}
+	if req.TagIDs != nil {
+		transaction.Tags = toTagModels(*req.TagIDs)
+	}
 	if req.Date != nil {
 		date, err := time.Parse("2006-01-02", *req.Date)
 		if err != nil {
@@ -298,6 +339,26 @@ func (ctrl *TransactionV2Controller) UpdateTransaction(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Insufficient balance in the selected asset"})
 			return
 		}
+		if err.Error() == "transaction belongs to a transfer" {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Transfer transactions must be changed through the transfer"})
+			return
+		}
+		if err.Error() == "transaction is reconciled" {
+			c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Transaction is reconciled; unlock it before editing"})
+			return
+		}
+		if isSplitValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": err.Error()})
+			return
+		}
+		if err.Error() == "tag not found" {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Tag not found"})
+			return
+		}
+		if err.Error() == "payee not found" {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Payee not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to update transaction"})
 		return
 	}
@@ -342,6 +403,14 @@ func (ctrl *TransactionV2Controller) DeleteTransaction(c *gin.Context) {
 	}
 
 	if err := ctrl.transactionService.DeleteTransaction(uint(id), userIDUint); err != nil {
+		if err.Error() == "transaction belongs to a transfer" {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Transfer transactions must be deleted through the transfer"})
+			return
+		}
+		if err.Error() == "transaction is reconciled" {
+			c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Transaction is reconciled; unlock it before deleting"})
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Transaction not found or unauthorized"})
 		return
 	}
@@ -357,6 +426,136 @@ func (ctrl *TransactionV2Controller) DeleteTransaction(c *gin.Context) {
 	})
 }
 
+// GetDeletedTransactions lists the transactions in the trash
+func (ctrl *TransactionV2Controller) GetDeletedTransactions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "User not authenticated"})
+		return
+	}
+
+	userIDUint, ok := userID.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Invalid user ID"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	transactions, pagination, err := ctrl.transactionService.GetDeletedTransactions(userIDUint, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch deleted transactions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "Deleted transactions fetched successfully",
+		"data":       transactions,
+		"pagination": pagination,
+	})
+}
+
+// RestoreTransaction takes a transaction out of the trash and applies it to
+// its asset's balance again
+func (ctrl *TransactionV2Controller) RestoreTransaction(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "User not authenticated"})
+		return
+	}
+
+	userIDUint, ok := userID.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Invalid user ID"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid transaction ID"})
+		return
+	}
+
+	if err := ctrl.transactionService.RestoreTransaction(uint(id), userIDUint); err != nil {
+		switch err.Error() {
+		case "transaction not found in trash":
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Transaction not found in trash"})
+		case "asset not found":
+			c.JSON(http.StatusConflict, gin.H{"success": false, "message": "The transaction's wallet is deleted; restore it first"})
+		case "insufficient balance":
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Insufficient balance in asset"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to restore transaction"})
+		}
+		return
+	}
+
+	// A restored expense counts towards budgets again
+	transaction, err := ctrl.transactionService.GetTransactionByID(uint(id), userIDUint)
+	if err == nil && transaction.TransactionType == 2 {
+		ctrl.budgetService.CheckBudgetAlerts(userIDUint)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Transaction restored successfully",
+		"data":    transaction,
+	})
+}
+
+// UpdateTransactionStatus marks a transaction pending, cleared or reconciled.
+// Reconciled transactions are locked until a request with "unlock" moves them
+// back.
+func (ctrl *TransactionV2Controller) UpdateTransactionStatus(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "User not authenticated"})
+		return
+	}
+
+	userIDUint, ok := userID.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Invalid user ID"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid transaction ID"})
+		return
+	}
+
+	var req dto.UpdateTransactionStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": err.Error()})
+		return
+	}
+
+	if err := ctrl.transactionService.UpdateTransactionStatus(uint(id), userIDUint, req.Status, req.Unlock); err != nil {
+		if err.Error() == "transaction is reconciled" {
+			c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Transaction is reconciled; set unlock to change its status"})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Transaction not found or unauthorized"})
+		return
+	}
+
+	updated, _ := ctrl.transactionService.GetTransactionByID(uint(id), userIDUint)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Transaction status updated successfully",
+		"data":    updated,
+	})
+}
+
 func (ctrl *TransactionV2Controller) GetAssetTransactions(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -396,3 +595,97 @@ func (ctrl *TransactionV2Controller) GetAssetTransactions(c *gin.Context) {
 		"data":    response,
 	})
 }
+
+func toSplitModels(splits []dto.TransactionSplitRequest) []models.TransactionSplit {
+	result := make([]models.TransactionSplit, len(splits))
+	for i, split := range splits {
+		result[i] = models.TransactionSplit{
+			CategoryID:  split.CategoryID,
+			Amount:      split.Amount,
+			Description: split.Description,
+		}
+	}
+	return result
+}
+
+func toTagModels(tagIDs []uint) []models.Tag {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	result := make([]models.Tag, len(tagIDs))
+	for i, tagID := range tagIDs {
+		result[i] = models.Tag{ID: tagID}
+	}
+	return result
+}
+
+func isSplitValidationError(err error) bool {
+	return err.Error() == "split amounts must add up to the transaction amount" ||
+		err.Error() == "split amount must be positive"
+}
+
+// parseTransactionV2Filter reads the list filters shared by GetTransactions and
+// ExportTransactions. Invalid values are ignored.
+func parseTransactionV2Filter(c *gin.Context) *dto.TransactionV2Filter {
+	filter := &dto.TransactionV2Filter{}
+
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		if parsed, err := time.Parse("2006-01-02", startDateStr); err == nil {
+			filter.StartDate = &parsed
+		}
+	}
+
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		if parsed, err := time.Parse("2006-01-02", endDateStr); err == nil {
+			filter.EndDate = &parsed
+		}
+	}
+
+	if txTypeStr := c.Query("transaction_type"); txTypeStr != "" {
+		var txType int
+		if txTypeStr == "Income" || txTypeStr == "income" {
+			txType = 1
+		} else if txTypeStr == "Expense" || txTypeStr == "expense" {
+			txType = 2
+		} else {
+			if parsed, err := strconv.Atoi(txTypeStr); err == nil {
+				txType = parsed
+			}
+		}
+
+		if txType == 1 || txType == 2 {
+			filter.TransactionType = &txType
+		}
+	}
+
+	if catIDStr := c.Query("category_id"); catIDStr != "" {
+		if catID, err := strconv.ParseUint(catIDStr, 10, 32); err == nil {
+			temp := uint(catID)
+			filter.CategoryID = &temp
+		}
+	}
+
+	if assetIDStr := c.Query("asset_id"); assetIDStr != "" {
+		if aID, err := strconv.ParseUint(assetIDStr, 10, 64); err == nil {
+			temp := aID
+			filter.AssetID = &temp
+		}
+	}
+
+	switch status := c.Query("status"); status {
+	case models.TransactionStatusPending, models.TransactionStatusCleared, models.TransactionStatusReconciled:
+		filter.Status = status
+	}
+
+	// tags=1,2 matches transactions carrying any of the tags
+	if tagsStr := c.Query("tags"); tagsStr != "" {
+		for _, part := range strings.Split(tagsStr, ",") {
+			if tagID, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32); err == nil {
+				filter.TagIDs = append(filter.TagIDs, uint(tagID))
+			}
+		}
+	}
+
+	return filter
+}
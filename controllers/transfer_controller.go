@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"my-api/dto"
+	"my-api/models"
+	"my-api/services"
+	"my-api/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TransferController struct {
+	transferService services.TransferService
+}
+
+func NewTransferController(transferService services.TransferService) *TransferController {
+	return &TransferController{transferService: transferService}
+}
+
+func (ctrl *TransferController) GetTransfers(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "User not authenticated"})
+		return
+	}
+
+	userIDUint, ok := userID.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Invalid user ID"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize := c.Query("page_size")
+	if pageSize == "" {
+		pageSize = c.DefaultQuery("limit", "10")
+	}
+	limit, _ := strconv.Atoi(pageSize)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	var assetID *uint64
+	if assetIDStr := c.Query("asset_id"); assetIDStr != "" {
+		if aID, err := strconv.ParseUint(assetIDStr, 10, 64); err == nil {
+			assetID = &aID
+		}
+	}
+
+	transfers, pagination, err := ctrl.transferService.GetTransfers(userIDUint, page, limit, assetID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch transfers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "Transfers fetched successfully",
+		"data":       transfers,
+		"pagination": pagination,
+	})
+}
+
+func (ctrl *TransferController) GetTransferByID(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "User not authenticated"})
+		return
+	}
+
+	userIDUint, ok := userID.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Invalid user ID"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid transfer ID"})
+		return
+	}
+
+	transfer, err := ctrl.transferService.GetTransferByID(uint(id), userIDUint)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Transfer not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Transfer fetched successfully",
+		"data":    transfer,
+	})
+}
+
+func (ctrl *TransferController) CreateTransfer(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "User not authenticated"})
+		return
+	}
+
+	userIDUint, ok := userID.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Invalid user ID"})
+		return
+	}
+
+	var req dto.CreateTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": err.Error()})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		date, err = time.Parse(time.RFC3339, req.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid date format. Use YYYY-MM-DD or ISO 8601"})
+			return
+		}
+	}
+
+	transfer := &models.Transfer{
+		UserID:        userIDUint,
+		SourceAssetID: req.SourceAssetID,
+		TargetAssetID: req.TargetAssetID,
+		Amount:        req.Amount,
+		Description:   req.Description,
+		Date:          utils.CustomTime{Time: date},
+	}
+
+	if err := ctrl.transferService.CreateTransfer(transfer, req.CategoryID); err != nil {
+		switch err.Error() {
+		case "insufficient balance":
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Insufficient balance in the source asset"})
+		case "source and target asset must be different":
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Source and target asset must be different"})
+		case "currency mismatch":
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Source and target asset must use the same currency"})
+		case "asset not found":
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Asset not found"})
+		case "category not found":
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Category not found"})
+		case "unauthorized: asset does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "Asset does not belong to you"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create transfer"})
+		}
+		return
+	}
+
+	created, _ := ctrl.transferService.GetTransferByID(transfer.ID, userIDUint)
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Transfer created successfully",
+		"data":    created,
+	})
+}
+
+func (ctrl *TransferController) DeleteTransfer(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "User not authenticated"})
+		return
+	}
+
+	userIDUint, ok := userID.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Invalid user ID"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid transfer ID"})
+		return
+	}
+
+	if err := ctrl.transferService.DeleteTransfer(uint(id), userIDUint); err != nil {
+		if err.Error() == "transaction is reconciled" {
+			c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Transfer is reconciled; unlock its transactions first"})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Transfer not found or unauthorized"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Transfer deleted successfully",
+	})
+}
+
+// CreatePayment pays down the credit card or loan in the URL from another
+// asset
+func (ctrl *TransferController) CreatePayment(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "User not authenticated"})
+		return
+	}
+
+	userIDUint, ok := userID.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Invalid user ID"})
+		return
+	}
+
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid asset ID"})
+		return
+	}
+
+	var req dto.CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": err.Error()})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		date, err = time.Parse(time.RFC3339, req.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid date format. Use YYYY-MM-DD or ISO 8601"})
+			return
+		}
+	}
+
+	transfer := &models.Transfer{
+		UserID:        userIDUint,
+		SourceAssetID: req.SourceAssetID,
+		TargetAssetID: assetID,
+		Amount:        req.Amount,
+		Description:   req.Description,
+		Date:          utils.CustomTime{Time: date},
+	}
+
+	if err := ctrl.transferService.CreatePayment(transfer, req.CategoryID); err != nil {
+		switch err.Error() {
+		case "insufficient balance":
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Insufficient balance in the source asset"})
+		case "source and target asset must be different",
+			"payments can only be made to a credit card or loan",
+			"payments must come from a non-credit asset":
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": err.Error()})
+		case "currency mismatch":
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Source and target asset must use the same currency"})
+		case "asset not found":
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Asset not found"})
+		case "category not found":
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Category not found"})
+		case "unauthorized: asset does not belong to user":
+			c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "Asset does not belong to you"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create payment"})
+		}
+		return
+	}
+
+	created, _ := ctrl.transferService.GetTransferByID(transfer.ID, userIDUint)
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Payment created successfully",
+		"data":    created,
+	})
+}
@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    DROP INDEX idx_transactions_transfer_id,
+    DROP COLUMN transfer_id;
+
+DROP TABLE IF EXISTS transfers;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS transfers (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    source_asset_id BIGINT UNSIGNED NOT NULL,
+    target_asset_id BIGINT UNSIGNED NOT NULL,
+    amount INT NOT NULL,
+    description VARCHAR(200),
+    date DATETIME NOT NULL,
+    source_transaction_id INT UNSIGNED,
+    target_transaction_id INT UNSIGNED,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_transfers_user_id (user_id),
+    INDEX idx_transfers_source_asset_id (source_asset_id),
+    INDEX idx_transfers_target_asset_id (target_asset_id),
+    INDEX idx_transfers_date (date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE transactions
+    ADD COLUMN transfer_id INT UNSIGNED NULL AFTER transaction_type,
+    ADD INDEX idx_transactions_transfer_id (transfer_id);
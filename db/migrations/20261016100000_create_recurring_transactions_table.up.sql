@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS recurring_transactions (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    description VARCHAR(200) NOT NULL,
+    category_id INT UNSIGNED NOT NULL,
+    asset_id BIGINT UNSIGNED NOT NULL,
+    amount INT NOT NULL,
+    transaction_type INT NOT NULL COMMENT '1=income, 2=expense',
+    frequency VARCHAR(20) NOT NULL COMMENT 'daily, weekly, monthly, yearly, pay_cycle',
+    `interval` INT NOT NULL DEFAULT 1,
+    start_date DATETIME NOT NULL,
+    end_date DATETIME NULL,
+    next_occurrence DATETIME NULL,
+    is_active TINYINT(1) NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_recurring_transactions_user_id (user_id),
+    INDEX idx_recurring_transactions_next_occurrence (next_occurrence)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS recurring_occurrences (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    recurring_transaction_id INT UNSIGNED NOT NULL,
+    user_id INT UNSIGNED NOT NULL,
+    occurrence_date DATETIME NOT NULL,
+    status VARCHAR(20) NOT NULL COMMENT 'pending, posted, skipped, failed',
+    transaction_id INT UNSIGNED NULL,
+    message VARCHAR(255),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_recurring_occurrence (recurring_transaction_id, occurrence_date),
+    INDEX idx_recurring_occurrences_user_id (user_id),
+    FOREIGN KEY (recurring_transaction_id) REFERENCES recurring_transactions(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS transaction_splits (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    transaction_id INT UNSIGNED NOT NULL,
+    category_id INT UNSIGNED NOT NULL,
+    amount INT NOT NULL,
+    description VARCHAR(200),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_transaction_splits_transaction_id (transaction_id),
+    INDEX idx_transaction_splits_category_id (category_id),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
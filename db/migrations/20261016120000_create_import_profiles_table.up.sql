@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS import_profiles (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    delimiter CHAR(1) NOT NULL DEFAULT ',',
+    has_header TINYINT(1) NOT NULL DEFAULT 1,
+    skip_rows INT NOT NULL DEFAULT 0,
+    date_column VARCHAR(100) NOT NULL,
+    date_format VARCHAR(50),
+    description_column VARCHAR(100),
+    amount_column VARCHAR(100),
+    debit_column VARCHAR(100),
+    credit_column VARCHAR(100),
+    type_column VARCHAR(100),
+    debit_value VARCHAR(20),
+    credit_value VARCHAR(20),
+    decimal_separator CHAR(1) NOT NULL DEFAULT '.',
+    asset_id BIGINT UNSIGNED NULL,
+    income_category_id INT UNSIGNED NULL,
+    expense_category_id INT UNSIGNED NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_import_profiles_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
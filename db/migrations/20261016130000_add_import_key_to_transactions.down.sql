@@ -0,0 +1,3 @@
+ALTER TABLE transactions
+    DROP INDEX idx_transactions_asset_import_key,
+    DROP COLUMN import_key;
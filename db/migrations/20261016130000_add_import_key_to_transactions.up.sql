@@ -0,0 +1,3 @@
+ALTER TABLE transactions
+    ADD COLUMN import_key VARCHAR(255) NULL AFTER transfer_id,
+    ADD UNIQUE INDEX idx_transactions_asset_import_key (asset_id, import_key);
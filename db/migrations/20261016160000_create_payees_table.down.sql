@@ -0,0 +1,6 @@
+ALTER TABLE transactions
+    DROP INDEX idx_transactions_payee_id,
+    DROP COLUMN payee_id;
+
+DROP TABLE IF EXISTS payee_rules;
+DROP TABLE IF EXISTS payees;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS payees (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_payees_user_name (user_id, name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS payee_rules (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    payee_id INT UNSIGNED NOT NULL,
+    match_type VARCHAR(20) NOT NULL,
+    pattern VARCHAR(255) NOT NULL,
+    priority INT NOT NULL DEFAULT 100,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_payee_rules_user_id (user_id),
+    INDEX idx_payee_rules_payee_id (payee_id),
+    FOREIGN KEY (payee_id) REFERENCES payees(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE transactions
+    ADD COLUMN payee_id INT UNSIGNED NULL AFTER import_key,
+    ADD INDEX idx_transactions_payee_id (payee_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS category_rule_tags;
+DROP TABLE IF EXISTS category_rules;
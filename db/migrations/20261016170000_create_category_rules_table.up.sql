@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS category_rules (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    priority INT NOT NULL DEFAULT 100,
+    is_active TINYINT(1) NOT NULL DEFAULT 1,
+    match_type VARCHAR(20),
+    pattern VARCHAR(255),
+    min_amount INT NULL,
+    max_amount INT NULL,
+    transaction_type INT NULL,
+    asset_id INT UNSIGNED NULL,
+    category_id INT UNSIGNED NULL,
+    payee_id INT UNSIGNED NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_category_rules_user_id (user_id),
+    FOREIGN KEY (payee_id) REFERENCES payees(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS category_rule_tags (
+    rule_id INT UNSIGNED NOT NULL,
+    tag_id INT UNSIGNED NOT NULL,
+    PRIMARY KEY (rule_id, tag_id),
+    INDEX idx_category_rule_tags_tag_id (tag_id),
+    FOREIGN KEY (rule_id) REFERENCES category_rules(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    base_currency VARCHAR(10) NOT NULL,
+    quote_currency VARCHAR(10) NOT NULL,
+    rate_date DATE NOT NULL,
+    rate DECIMAL(20,8) NOT NULL,
+    source VARCHAR(20) NOT NULL DEFAULT 'manual',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_exchange_rates_pair_date (user_id, base_currency, quote_currency, rate_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE user_settings
+    ADD COLUMN base_currency VARCHAR(10) NOT NULL DEFAULT 'IDR' AFTER cycle_start_offset;
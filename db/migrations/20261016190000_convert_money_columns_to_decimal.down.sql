@@ -0,0 +1,10 @@
+ALTER TABLE assets MODIFY balance DECIMAL(20,8) NOT NULL DEFAULT 0;
+ALTER TABLE budget_alerts MODIFY spent_amount BIGINT NOT NULL;
+ALTER TABLE budgets MODIFY amount BIGINT NOT NULL;
+ALTER TABLE category_rules
+    MODIFY min_amount INT NULL,
+    MODIFY max_amount INT NULL;
+ALTER TABLE recurring_transactions MODIFY amount INT NOT NULL;
+ALTER TABLE transfers MODIFY amount INT NOT NULL;
+ALTER TABLE transaction_splits MODIFY amount INT NOT NULL;
+ALTER TABLE transactions MODIFY amount DECIMAL(10,2) NOT NULL;
@@ -0,0 +1,26 @@
+-- Amounts and balances are stored as exact decimals with 4 places.
+-- assets.balance was DECIMAL(20,8). Rather than rounding away digits past the
+-- 4th place, the migration stops with an error before changing anything when
+-- a balance has them. Such balances have to be reviewed and rounded by hand
+-- first.
+DROP PROCEDURE IF EXISTS check_money_scale;
+CREATE PROCEDURE check_money_scale()
+BEGIN
+    IF EXISTS (SELECT 1 FROM assets WHERE balance <> ROUND(balance, 4)) THEN
+        SIGNAL SQLSTATE '45000'
+            SET MESSAGE_TEXT = 'assets.balance has digits past the 4th decimal place; round them by hand before migrating';
+    END IF;
+END;
+CALL check_money_scale();
+DROP PROCEDURE check_money_scale;
+
+ALTER TABLE transactions MODIFY amount DECIMAL(19,4) NOT NULL;
+ALTER TABLE transaction_splits MODIFY amount DECIMAL(19,4) NOT NULL;
+ALTER TABLE transfers MODIFY amount DECIMAL(19,4) NOT NULL;
+ALTER TABLE recurring_transactions MODIFY amount DECIMAL(19,4) NOT NULL;
+ALTER TABLE category_rules
+    MODIFY min_amount DECIMAL(19,4) NULL,
+    MODIFY max_amount DECIMAL(19,4) NULL;
+ALTER TABLE budgets MODIFY amount DECIMAL(19,4) NOT NULL;
+ALTER TABLE budget_alerts MODIFY spent_amount DECIMAL(19,4) NOT NULL;
+ALTER TABLE assets MODIFY balance DECIMAL(19,4) NOT NULL DEFAULT 0;
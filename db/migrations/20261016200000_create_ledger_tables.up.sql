@@ -0,0 +1,108 @@
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    entry_type VARCHAR(20) NOT NULL,
+    transaction_id INT UNSIGNED NULL,
+    transfer_id INT UNSIGNED NULL,
+    asset_id BIGINT UNSIGNED NULL,
+    reverses_id INT UNSIGNED NULL,
+    reversed_at DATETIME NULL,
+    description VARCHAR(200),
+    date DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_journal_entries_user_id (user_id),
+    INDEX idx_journal_entries_transaction_id (transaction_id),
+    INDEX idx_journal_entries_transfer_id (transfer_id),
+    INDEX idx_journal_entries_asset_id (asset_id),
+    INDEX idx_journal_entries_reverses_id (reverses_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS postings (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    entry_id INT UNSIGNED NOT NULL,
+    user_id INT UNSIGNED NOT NULL,
+    account VARCHAR(20) NOT NULL,
+    asset_id BIGINT UNSIGNED NULL,
+    amount DECIMAL(19,4) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    date DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_postings_entry_id (entry_id),
+    INDEX idx_postings_user_id (user_id),
+    INDEX idx_postings_asset_date (asset_id, date, id),
+    FOREIGN KEY (entry_id) REFERENCES journal_entries(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Backfill: one entry per existing wallet transaction ...
+INSERT INTO journal_entries (user_id, entry_type, transaction_id, asset_id, description, date, created_at)
+SELECT t.user_id, 'transaction', t.id, t.asset_id, t.description, t.date, t.created_at
+FROM transactions t
+JOIN assets a ON a.id = t.asset_id
+WHERE t.transfer_id IS NULL;
+
+INSERT INTO postings (entry_id, user_id, account, asset_id, amount, currency, date, created_at)
+SELECT je.id, je.user_id, 'asset', t.asset_id,
+       IF(t.transaction_type = 1, t.amount, -t.amount), a.currency, t.date, je.created_at
+FROM journal_entries je
+JOIN transactions t ON t.id = je.transaction_id
+JOIN assets a ON a.id = t.asset_id
+WHERE je.entry_type = 'transaction';
+
+INSERT INTO postings (entry_id, user_id, account, asset_id, amount, currency, date, created_at)
+SELECT p.entry_id, p.user_id, IF(p.amount >= 0, 'income', 'expense'), NULL, -p.amount, p.currency, p.date, p.created_at
+FROM postings p
+JOIN journal_entries je ON je.id = p.entry_id
+WHERE je.entry_type = 'transaction';
+
+-- ... one per transfer ...
+INSERT INTO journal_entries (user_id, entry_type, transfer_id, description, date, created_at)
+SELECT tr.user_id, 'transfer', tr.id, tr.description, tr.date, tr.created_at
+FROM transfers tr;
+
+INSERT INTO postings (entry_id, user_id, account, asset_id, amount, currency, date, created_at)
+SELECT je.id, je.user_id, 'asset', tr.source_asset_id, -tr.amount, a.currency, tr.date, je.created_at
+FROM journal_entries je
+JOIN transfers tr ON tr.id = je.transfer_id
+JOIN assets a ON a.id = tr.source_asset_id
+WHERE je.entry_type = 'transfer';
+
+INSERT INTO postings (entry_id, user_id, account, asset_id, amount, currency, date, created_at)
+SELECT je.id, je.user_id, 'asset', tr.target_asset_id, tr.amount, a.currency, tr.date, je.created_at
+FROM journal_entries je
+JOIN transfers tr ON tr.id = je.transfer_id
+JOIN assets a ON a.id = tr.target_asset_id
+WHERE je.entry_type = 'transfer';
+
+-- ... and an opening balance for whatever part of each stored balance the
+-- history does not explain, dated before the wallet's first posting.
+INSERT INTO journal_entries (user_id, entry_type, asset_id, description, date, created_at)
+SELECT a.user_id, 'opening_balance', a.id, 'Opening balance',
+       LEAST(a.created_at, COALESCE(h.first_date, a.created_at)), a.created_at
+FROM assets a
+LEFT JOIN (
+    SELECT asset_id, SUM(amount) AS total, MIN(date) AS first_date
+    FROM postings
+    WHERE account = 'asset'
+    GROUP BY asset_id
+) h ON h.asset_id = a.id
+WHERE a.balance <> COALESCE(h.total, 0);
+
+INSERT INTO postings (entry_id, user_id, account, asset_id, amount, currency, date, created_at)
+SELECT je.id, je.user_id, 'asset', a.id, a.balance - COALESCE(h.total, 0), a.currency, je.date, je.created_at
+FROM journal_entries je
+JOIN assets a ON a.id = je.asset_id
+LEFT JOIN (
+    SELECT asset_id, SUM(amount) AS total
+    FROM postings
+    WHERE account = 'asset'
+    GROUP BY asset_id
+) h ON h.asset_id = a.id
+WHERE je.entry_type = 'opening_balance';
+
+INSERT INTO postings (entry_id, user_id, account, asset_id, amount, currency, date, created_at)
+SELECT p.entry_id, p.user_id, 'equity', NULL, -p.amount, p.currency, p.date, p.created_at
+FROM postings p
+JOIN journal_entries je ON je.id = p.entry_id
+WHERE je.entry_type = 'opening_balance';
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS reconciliations (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    asset_id BIGINT UNSIGNED NOT NULL,
+    statement_date DATE NOT NULL,
+    statement_balance DECIMAL(19,4) NOT NULL,
+    computed_balance DECIMAL(19,4) NOT NULL,
+    adjustment DECIMAL(19,4) NOT NULL DEFAULT 0,
+    note VARCHAR(255),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_reconciliations_user_id (user_id),
+    INDEX idx_reconciliations_asset_date (asset_id, statement_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,4 @@
+-- Existing transactions have all posted, so they start out cleared
+ALTER TABLE transactions
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'cleared' AFTER transaction_type,
+    ADD INDEX idx_transactions_asset_status (asset_id, status);
@@ -0,0 +1,6 @@
+ALTER TABLE assets
+    DROP COLUMN min_payment_amount,
+    DROP COLUMN min_payment_percent,
+    DROP COLUMN payment_due_day,
+    DROP COLUMN statement_closing_day,
+    DROP COLUMN credit_limit;
@@ -0,0 +1,7 @@
+-- Existing wallets keep their free-form type and a zero floor
+ALTER TABLE assets
+    ADD COLUMN credit_limit DECIMAL(19,4) NOT NULL DEFAULT 0 AFTER account_no,
+    ADD COLUMN statement_closing_day INT NOT NULL DEFAULT 0 AFTER credit_limit,
+    ADD COLUMN payment_due_day INT NOT NULL DEFAULT 0 AFTER statement_closing_day,
+    ADD COLUMN min_payment_percent DECIMAL(5,2) NOT NULL DEFAULT 0 AFTER payment_due_day,
+    ADD COLUMN min_payment_amount DECIMAL(19,4) NOT NULL DEFAULT 0 AFTER min_payment_percent;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS instrument_prices;
+DROP TABLE IF EXISTS holding_lots;
+DROP TABLE IF EXISTS instruments;
@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS instruments (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    symbol VARCHAR(50) NOT NULL,
+    name VARCHAR(255),
+    kind VARCHAR(20) NOT NULL DEFAULT 'other',
+    currency VARCHAR(10) NOT NULL,
+    unit VARCHAR(20),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_instruments_user_symbol (user_id, symbol)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS holding_lots (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    asset_id BIGINT UNSIGNED NOT NULL,
+    instrument_id INT UNSIGNED NOT NULL,
+    side VARCHAR(4) NOT NULL,
+    quantity DECIMAL(20,8) NOT NULL,
+    price DECIMAL(19,4) NOT NULL,
+    fees DECIMAL(19,4) NOT NULL DEFAULT 0,
+    date DATE NOT NULL,
+    note VARCHAR(255),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_holding_lots_user_id (user_id),
+    INDEX idx_holding_lots_asset_instrument (asset_id, instrument_id),
+    CONSTRAINT fk_holding_lots_instrument FOREIGN KEY (instrument_id) REFERENCES instruments (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS instrument_prices (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    instrument_id INT UNSIGNED NOT NULL,
+    price_date DATE NOT NULL,
+    price DECIMAL(19,4) NOT NULL,
+    source VARCHAR(20) NOT NULL DEFAULT 'manual',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_instrument_prices_user_id (user_id),
+    UNIQUE INDEX idx_instrument_prices_date (instrument_id, price_date),
+    CONSTRAINT fk_instrument_prices_instrument FOREIGN KEY (instrument_id) REFERENCES instruments (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
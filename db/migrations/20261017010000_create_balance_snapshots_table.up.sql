@@ -0,0 +1,16 @@
+-- Filled by the balance snapshot worker, which backfills from the ledger on
+-- its first run (or run cmd/snapshots)
+CREATE TABLE IF NOT EXISTS balance_snapshots (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    asset_id BIGINT UNSIGNED NOT NULL,
+    snapshot_date DATE NOT NULL,
+    balance DECIMAL(19,4) NOT NULL,
+    holdings_value DECIMAL(19,4) NOT NULL DEFAULT 0,
+    currency VARCHAR(10) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_balance_snapshots_asset_date (asset_id, snapshot_date),
+    INDEX idx_balance_snapshots_user_date (user_id, snapshot_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,11 @@
+ALTER TABLE categories
+    DROP INDEX idx_categories_deleted_at,
+    DROP COLUMN deleted_at;
+
+ALTER TABLE assets
+    DROP INDEX idx_assets_deleted_at,
+    DROP COLUMN deleted_at;
+
+ALTER TABLE transactions
+    DROP INDEX idx_transactions_deleted_at,
+    DROP COLUMN deleted_at;
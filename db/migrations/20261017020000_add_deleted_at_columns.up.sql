@@ -0,0 +1,12 @@
+-- Soft delete: rows stay in the trash until the purge job removes them
+ALTER TABLE transactions
+    ADD COLUMN deleted_at DATETIME NULL AFTER updated_at,
+    ADD INDEX idx_transactions_deleted_at (deleted_at);
+
+ALTER TABLE assets
+    ADD COLUMN deleted_at DATETIME NULL AFTER updated_at,
+    ADD INDEX idx_assets_deleted_at (deleted_at);
+
+ALTER TABLE categories
+    ADD COLUMN deleted_at DATETIME NULL AFTER updated_at,
+    ADD INDEX idx_categories_deleted_at (deleted_at);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS budget_periods;
+
+ALTER TABLE budgets
+    DROP COLUMN rollover;
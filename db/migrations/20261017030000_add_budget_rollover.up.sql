@@ -0,0 +1,21 @@
+ALTER TABLE budgets
+    ADD COLUMN rollover TINYINT(1) NOT NULL DEFAULT 0 AFTER alert_at;
+
+-- One row per budget window, refreshed whenever the budget is read
+CREATE TABLE IF NOT EXISTS budget_periods (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    budget_id INT UNSIGNED NOT NULL,
+    user_id INT UNSIGNED NOT NULL,
+    start_date DATETIME NOT NULL,
+    end_date DATETIME NOT NULL,
+    amount DECIMAL(19,4) NOT NULL,
+    carry_in DECIMAL(19,4) NOT NULL DEFAULT 0,
+    spent DECIMAL(19,4) NOT NULL DEFAULT 0,
+    remaining DECIMAL(19,4) NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_budget_periods_budget_start (budget_id, start_date),
+    INDEX idx_budget_periods_user_id (user_id),
+    FOREIGN KEY (budget_id) REFERENCES budgets(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
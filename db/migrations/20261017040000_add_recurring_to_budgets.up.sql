@@ -0,0 +1,3 @@
+-- Existing budgets keep their single window; new budgets renew by default
+ALTER TABLE budgets
+    ADD COLUMN recurring TINYINT(1) NOT NULL DEFAULT 0 AFTER period;
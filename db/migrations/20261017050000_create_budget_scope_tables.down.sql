@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS budget_assets;
+DROP TABLE IF EXISTS budget_categories;
+
+ALTER TABLE budgets
+    DROP COLUMN name;
@@ -0,0 +1,25 @@
+ALTER TABLE budgets
+    ADD COLUMN name VARCHAR(100) NOT NULL DEFAULT '' AFTER category_id;
+
+-- Categories a budget covers; category_id on budgets stays as the primary one
+CREATE TABLE IF NOT EXISTS budget_categories (
+    budget_id INT UNSIGNED NOT NULL,
+    category_id INT UNSIGNED NOT NULL,
+    PRIMARY KEY (budget_id, category_id),
+    INDEX idx_budget_categories_category_id (category_id),
+    FOREIGN KEY (budget_id) REFERENCES budgets(id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Wallets a budget is limited to; a budget without rows covers every wallet
+CREATE TABLE IF NOT EXISTS budget_assets (
+    budget_id INT UNSIGNED NOT NULL,
+    asset_id BIGINT UNSIGNED NOT NULL,
+    PRIMARY KEY (budget_id, asset_id),
+    INDEX idx_budget_assets_asset_id (asset_id),
+    FOREIGN KEY (budget_id) REFERENCES budgets(id) ON DELETE CASCADE,
+    FOREIGN KEY (asset_id) REFERENCES assets(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT IGNORE INTO budget_categories (budget_id, category_id)
+SELECT id, category_id FROM budgets;
@@ -0,0 +1,11 @@
+ALTER TABLE budget_alerts
+    DROP COLUMN threshold,
+    DROP COLUMN kind;
+
+ALTER TABLE budget_periods
+    DROP COLUMN projected_alerted,
+    DROP COLUMN alerted_threshold;
+
+ALTER TABLE budgets
+    DROP COLUMN projected_alert,
+    DROP COLUMN alert_thresholds;
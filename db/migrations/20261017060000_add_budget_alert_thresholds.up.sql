@@ -0,0 +1,26 @@
+ALTER TABLE budgets
+    ADD COLUMN alert_thresholds VARCHAR(100) NOT NULL DEFAULT '' AFTER alert_at,
+    ADD COLUMN projected_alert TINYINT(1) NOT NULL DEFAULT 0 AFTER alert_thresholds;
+
+-- Which alerts each period already fired, so every threshold fires once
+ALTER TABLE budget_periods
+    ADD COLUMN alerted_threshold INT NOT NULL DEFAULT 0 AFTER remaining,
+    ADD COLUMN projected_alerted TINYINT(1) NOT NULL DEFAULT 0 AFTER alerted_threshold;
+
+ALTER TABLE budget_alerts
+    ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'threshold' AFTER user_id,
+    ADD COLUMN threshold INT NOT NULL DEFAULT 0 AFTER kind;
+
+-- Periods that already alerted under the old single threshold keep quiet
+-- until they cross a higher one
+UPDATE budget_periods
+JOIN budgets ON budgets.id = budget_periods.budget_id
+JOIN (
+    SELECT budget_periods.id AS period_id, MAX(budget_alerts.percentage) AS percentage
+    FROM budget_periods
+    JOIN budget_alerts ON budget_alerts.budget_id = budget_periods.budget_id
+        AND budget_alerts.created_at >= budget_periods.start_date
+        AND budget_alerts.created_at < budget_periods.end_date + INTERVAL 1 DAY
+    GROUP BY budget_periods.id
+) alerted ON alerted.period_id = budget_periods.id
+SET budget_periods.alerted_threshold = IF(alerted.percentage >= 100, 100, budgets.alert_at);
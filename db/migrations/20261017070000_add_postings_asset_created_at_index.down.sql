@@ -0,0 +1 @@
+DROP INDEX idx_postings_asset_created_at ON postings;
@@ -0,0 +1,2 @@
+-- Lets the snapshot job find postings recorded since an asset's last snapshot
+CREATE INDEX idx_postings_asset_created_at ON postings (asset_id, created_at);
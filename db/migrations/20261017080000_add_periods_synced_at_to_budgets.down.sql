@@ -0,0 +1,2 @@
+ALTER TABLE budgets
+    DROP COLUMN periods_synced_at;
@@ -0,0 +1,4 @@
+-- When the stored budget_periods of a budget were last brought up to date;
+-- closed periods that no later transaction change reaches are reused as stored
+ALTER TABLE budgets
+    ADD COLUMN periods_synced_at DATETIME NULL AFTER description;
@@ -0,0 +1,2 @@
+ALTER TABLE assets
+    DROP COLUMN restored_at;
@@ -0,0 +1,5 @@
+-- When a wallet was last taken out of the trash, so budgets can tell that its
+-- transactions count again without looking at updated_at, which every
+-- balance change moves
+ALTER TABLE assets
+    ADD COLUMN restored_at DATETIME NULL AFTER deleted_at;
@@ -0,0 +1,3 @@
+ALTER TABLE budget_assets
+    DROP FOREIGN KEY fk_budget_assets_asset,
+    ADD CONSTRAINT budget_assets_ibfk_2 FOREIGN KEY (asset_id) REFERENCES assets(id) ON DELETE CASCADE;
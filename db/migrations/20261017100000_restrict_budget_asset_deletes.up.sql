@@ -0,0 +1,5 @@
+-- A wallet a budget is limited to must not be purged from under it: dropping
+-- the budget's last budget_assets row would widen it to every wallet
+ALTER TABLE budget_assets
+    DROP FOREIGN KEY budget_assets_ibfk_2,
+    ADD CONSTRAINT fk_budget_assets_asset FOREIGN KEY (asset_id) REFERENCES assets(id) ON DELETE RESTRICT;
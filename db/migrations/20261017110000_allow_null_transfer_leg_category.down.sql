@@ -0,0 +1,5 @@
+UPDATE transactions SET category_id = 0
+WHERE category_id IS NULL;
+
+ALTER TABLE transactions
+    MODIFY category_id INT NOT NULL;
@@ -0,0 +1,6 @@
+-- Transfer legs created without a category store NULL instead of 0
+ALTER TABLE transactions
+    MODIFY category_id INT NULL;
+
+UPDATE transactions SET category_id = NULL
+WHERE transfer_id IS NOT NULL AND category_id = 0;
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"my-api/money"
 	"my-api/utils"
 	"time"
 )
@@ -32,65 +33,83 @@ func (r *AnalyticsRequest) GetEndDate() (time.Time, error) {
 }
 
 type SpendingByCategoryResponse struct {
-	CategoryID   uint    `json:"category_id"`
-	CategoryName string  `json:"category_name"`
-	TotalAmount  int     `json:"total_amount"`
-	Percentage   float64 `json:"percentage"`
-	Count        int     `json:"count"`
+	CategoryID   uint         `json:"category_id"`
+	CategoryName string       `json:"category_name"`
+	TotalAmount  money.Amount `json:"total_amount"`
+	Percentage   float64      `json:"percentage"`
+	Count        int          `json:"count"`
+
+	OriginalAmounts map[string]money.Amount `json:"original_amounts,omitempty"` // Unconverted totals per currency
+	RatesMissing    []string                `json:"rates_missing,omitempty"`    // currencies converted at 1
 }
 
+// IncomeVsExpenseResponse totals are in Currency, the user's base currency
 type IncomeVsExpenseResponse struct {
-	TotalIncome  int     `json:"total_income"`
-	TotalExpense int     `json:"total_expense"`
-	NetAmount    int     `json:"net_amount"`
-	IncomeCount  int     `json:"income_count"`
-	ExpenseCount int     `json:"expense_count"`
-	SavingsRate  float64 `json:"savings_rate"`
+	Currency     string       `json:"currency"`
+	TotalIncome  money.Amount `json:"total_income"`
+	TotalExpense money.Amount `json:"total_expense"`
+	NetAmount    money.Amount `json:"net_amount"`
+	IncomeCount  int          `json:"income_count"`
+	ExpenseCount int          `json:"expense_count"`
+	SavingsRate  float64      `json:"savings_rate"`
+
+	OriginalIncome  map[string]money.Amount `json:"original_income,omitempty"`
+	OriginalExpense map[string]money.Amount `json:"original_expense,omitempty"`
+	RatesMissing    []string                `json:"rates_missing,omitempty"` // currencies converted at 1
 }
 
 type TrendDataPoint struct {
-	Date    string `json:"date"`
-	Income  int    `json:"income"`
-	Expense int    `json:"expense"`
-	Net     int    `json:"net"`
+	Date    string       `json:"date"`
+	Income  money.Amount `json:"income"`
+	Expense money.Amount `json:"expense"`
+	Net     money.Amount `json:"net"`
 }
 
 type TrendAnalysisResponse struct {
 	Period     string                  `json:"period"`
+	Currency   string                  `json:"currency"`
 	DataPoints []TrendDataPoint        `json:"data_points"`
 	Summary    IncomeVsExpenseResponse `json:"summary"`
 }
 
 type SpendingByBankResponse struct {
-	BankID      uint    `json:"bank_id"`
-	BankName    string  `json:"bank_name"`
-	TotalAmount int     `json:"total_amount"`
-	Percentage  float64 `json:"percentage"`
-	Count       int     `json:"count"`
+	BankID      uint         `json:"bank_id"`
+	BankName    string       `json:"bank_name"`
+	TotalAmount money.Amount `json:"total_amount"`
+	Percentage  float64      `json:"percentage"`
+	Count       int          `json:"count"`
+
+	OriginalAmounts map[string]money.Amount `json:"original_amounts,omitempty"`
+	RatesMissing    []string                `json:"rates_missing,omitempty"` // currencies converted at 1
 }
 
 type SpendingByAssetResponse struct {
-	AssetID          uint64  `json:"asset_id"`
-	AssetName        string  `json:"asset_name"`
-	AssetType        string  `json:"asset_type"`
-	AssetCurrency    string  `json:"asset_currency"`
-	TotalIncome      int     `json:"total_income"`
-	TotalExpense     int     `json:"total_expense"`
-	NetAmount        int     `json:"net_amount"`
-	Percentage       float64 `json:"percentage"`
-	TransactionCount int     `json:"transaction_count"`
+	AssetID          uint64       `json:"asset_id"`
+	AssetName        string       `json:"asset_name"`
+	AssetType        string       `json:"asset_type"`
+	AssetCurrency    string       `json:"asset_currency"`
+	TotalIncome      money.Amount `json:"total_income"`
+	TotalExpense     money.Amount `json:"total_expense"`
+	NetAmount        money.Amount `json:"net_amount"`
+	ConvertedIncome  money.Amount `json:"converted_income"`  // In the user's base currency
+	ConvertedExpense money.Amount `json:"converted_expense"` // In the user's base currency
+	ConvertedNet     money.Amount `json:"converted_net"`
+	Percentage       float64      `json:"percentage"` // Share of converted expenses
+	TransactionCount int          `json:"transaction_count"`
+	RateMissing      bool         `json:"rate_missing,omitempty"` // converted at 1, no exchange rate found
 }
 
 type MonthlyComparisonResponse struct {
-	Month         string  `json:"month"`
-	Income        int     `json:"income"`
-	Expense       int     `json:"expense"`
-	Net           int     `json:"net"`
-	IncomeChange  float64 `json:"income_change"` // % change from previous month
-	ExpenseChange float64 `json:"expense_change"`
+	Month         string       `json:"month"`
+	Income        money.Amount `json:"income"`
+	Expense       money.Amount `json:"expense"`
+	Net           money.Amount `json:"net"`
+	IncomeChange  float64      `json:"income_change"` // % change from previous month
+	ExpenseChange float64      `json:"expense_change"`
 }
 
 type DashboardSummaryResponse struct {
+	BaseCurrency       string                       `json:"base_currency"`
 	CurrentMonth       IncomeVsExpenseResponse      `json:"current_month"`
 	LastMonth          IncomeVsExpenseResponse      `json:"last_month"`
 	TopCategories      []SpendingByCategoryResponse `json:"top_categories"`
@@ -99,40 +118,65 @@ type DashboardSummaryResponse struct {
 }
 
 type BudgetSummaryResponse struct {
-	TotalBudgets       int     `json:"total_budgets"`
-	ActiveBudgets      int     `json:"active_budgets"`
-	ExceededBudgets    int     `json:"exceeded_budgets"`
-	WarningBudgets     int     `json:"warning_budgets"`
-	TotalBudgeted      int     `json:"total_budgeted"`
-	TotalSpent         int     `json:"total_spent"`
-	AverageUtilization float64 `json:"average_utilization"`
+	TotalBudgets       int          `json:"total_budgets"`
+	ActiveBudgets      int          `json:"active_budgets"`
+	ExceededBudgets    int          `json:"exceeded_budgets"`
+	WarningBudgets     int          `json:"warning_budgets"`
+	TotalBudgeted      money.Amount `json:"total_budgeted"`
+	TotalSpent         money.Amount `json:"total_spent"`
+	AverageUtilization float64      `json:"average_utilization"`
 }
 
 type TransactionResponse struct {
 	ID              uint             `json:"id"`
 	Description     string           `json:"description"`
-	Amount          int              `json:"amount"`
+	Amount          money.Amount     `json:"amount"`
 	TransactionType int              `json:"transaction_type"`
 	Date            utils.CustomTime `json:"date"`
 	CategoryName    string           `json:"category_name"`
 	BankName        string           `json:"bank_name"`
 	AssetName       string           `json:"asset_name"`
+	Currency        string           `json:"currency"`
+	ConvertedAmount money.Amount     `json:"converted_amount"` // Amount in the user's base currency
 }
 
 type YearlyReportResponse struct {
 	Year                 int                          `json:"year"`
-	TotalIncome          int                          `json:"total_income"`
-	TotalExpense         int                          `json:"total_expense"`
-	NetSavings           int                          `json:"net_savings"`
+	Currency             string                       `json:"currency"`
+	TotalIncome          money.Amount                 `json:"total_income"`
+	TotalExpense         money.Amount                 `json:"total_expense"`
+	NetSavings           money.Amount                 `json:"net_savings"`
 	MonthlyBreakdown     []MonthlyComparisonResponse  `json:"monthly_breakdown"`
 	TopExpenseCategories []SpendingByCategoryResponse `json:"top_expense_categories"`
 	TopIncomeCategories  []SpendingByCategoryResponse `json:"top_income_categories"`
+	RatesMissing         []string                     `json:"rates_missing,omitempty"` // currencies converted at 1
 }
 
 type CategoryTrendResponse struct {
 	CategoryID    uint             `json:"category_id"`
 	CategoryName  string           `json:"category_name"`
+	Currency      string           `json:"currency"`
 	DataPoints    []TrendDataPoint `json:"data_points"`
-	TotalAmount   int              `json:"total_amount"`
-	AverageAmount float64          `json:"average_amount"`
+	TotalAmount   money.Amount     `json:"total_amount"`
+	AverageAmount money.Amount     `json:"average_amount"`
+	RatesMissing  []string         `json:"rates_missing,omitempty"` // currencies converted at 1
+}
+
+// NetWorthDataPoint is what the user owned and owed at the end of a period,
+// in the base currency. Assets with a negative value count as liabilities.
+type NetWorthDataPoint struct {
+	Period      string       `json:"period"`
+	StartDate   string       `json:"start_date"`
+	EndDate     string       `json:"end_date"`
+	Assets      money.Amount `json:"assets"`
+	Liabilities money.Amount `json:"liabilities"`
+	NetWorth    money.Amount `json:"net_worth"`
+}
+
+type NetWorthResponse struct {
+	Currency     string              `json:"currency"`
+	GroupBy      string              `json:"group_by"`
+	DataPoints   []NetWorthDataPoint `json:"data_points"`
+	Change       money.Amount        `json:"change"`                  // last net worth minus first
+	RatesMissing []string            `json:"rates_missing,omitempty"` // currencies converted at 1
 }
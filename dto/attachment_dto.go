@@ -0,0 +1,15 @@
+package dto
+
+import (
+	"my-api/utils"
+)
+
+type AttachmentResponse struct {
+	ID            uint             `json:"id"`
+	TransactionID uint             `json:"transaction_id"`
+	FileName      string           `json:"file_name"`
+	ContentType   string           `json:"content_type"`
+	Size          int64            `json:"size"`
+	DownloadURL   string           `json:"download_url"`
+	CreatedAt     utils.CustomTime `json:"created_at"`
+}
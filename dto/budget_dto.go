@@ -1,47 +1,93 @@
 package dto
 
 import (
+	"my-api/money"
 	"my-api/utils"
 	"time"
 )
 
+// CreateBudgetRequest takes the budget's categories as category_id,
+// category_ids or both; the first one becomes the primary category. Without
+// asset_ids the budget covers every wallet.
 type CreateBudgetRequest struct {
-	CategoryID  uint             `json:"category_id" binding:"required"`
-	Amount      int              `json:"amount" binding:"required,min=1"`
-	Period      string           `json:"period" binding:"required,oneof=monthly yearly"`
-	StartDate   utils.CustomTime `json:"start_date" binding:"required"`
-	AlertAt     int              `json:"alert_at" binding:"omitempty,min=1,max=100"`
-	Description string           `json:"description" binding:"omitempty,max=500"`
+	CategoryID      uint             `json:"category_id"`
+	CategoryIDs     []uint           `json:"category_ids"`
+	AssetIDs        []uint64         `json:"asset_ids"`
+	Name            string           `json:"name" binding:"omitempty,max=100"`
+	Amount          money.Amount     `json:"amount" binding:"required,gt=0"`
+	Period          string           `json:"period" binding:"required,oneof=weekly monthly yearly pay_cycle"`
+	StartDate       utils.CustomTime `json:"start_date" binding:"required"`
+	Recurring       *bool            `json:"recurring"` // defaults to true
+	AlertAt         int              `json:"alert_at" binding:"omitempty,min=1,max=100"`
+	AlertThresholds []int            `json:"alert_thresholds"` // percentages, defaults to alert_at and 100
+	ProjectedAlert  bool             `json:"projected_alert"`
+	Rollover        bool             `json:"rollover"`
+	Description     string           `json:"description" binding:"omitempty,max=500"`
 }
 
 type UpdateBudgetRequest struct {
-	Amount      int    `json:"amount" binding:"omitempty,min=1"`
-	AlertAt     int    `json:"alert_at" binding:"omitempty,min=1,max=100"`
-	Description string `json:"description" binding:"omitempty,max=500"`
-	IsActive    *bool  `json:"is_active"`
+	Name            *string      `json:"name" binding:"omitempty,max=100"`
+	CategoryIDs     []uint       `json:"category_ids"`
+	AssetIDs        *[]uint64    `json:"asset_ids"` // an empty list covers every wallet
+	Amount          money.Amount `json:"amount" binding:"omitempty,gt=0"`
+	AlertAt         int          `json:"alert_at" binding:"omitempty,min=1,max=100"`
+	AlertThresholds *[]int       `json:"alert_thresholds"` // an empty list goes back to alert_at and 100
+	ProjectedAlert  *bool        `json:"projected_alert"`
+	Description     string       `json:"description" binding:"omitempty,max=500"`
+	IsActive        *bool        `json:"is_active"`
+	Rollover        *bool        `json:"rollover"`
 }
 
 type BudgetResponse struct {
-	ID           uint             `json:"id"`
-	CategoryID   uint             `json:"category_id"`
-	CategoryName string           `json:"category_name"`
-	Amount       int              `json:"amount"`
-	Period       string           `json:"period"`
-	StartDate    utils.CustomTime `json:"start_date"`
-	EndDate      utils.CustomTime `json:"end_date"`
-	IsActive     bool             `json:"is_active"`
-	AlertAt      int              `json:"alert_at"`
-	Description  string           `json:"description"`
-	CreatedAt    utils.CustomTime `json:"created_at"`
+	ID              uint             `json:"id"`
+	Name            string           `json:"name"`
+	CategoryID      uint             `json:"category_id"`
+	CategoryName    string           `json:"category_name"`
+	CategoryIDs     []uint           `json:"category_ids"`
+	AssetIDs        []uint64         `json:"asset_ids,omitempty"`
+	Amount          money.Amount     `json:"amount"`
+	Period          string           `json:"period"`
+	Recurring       bool             `json:"recurring"`
+	StartDate       utils.CustomTime `json:"start_date"`
+	EndDate         utils.CustomTime `json:"end_date"`
+	IsActive        bool             `json:"is_active"`
+	AlertAt         int              `json:"alert_at"`
+	AlertThresholds []int            `json:"alert_thresholds"`
+	ProjectedAlert  bool             `json:"projected_alert"`
+	Rollover        bool             `json:"rollover"`
+	Description     string           `json:"description"`
+	CreatedAt       utils.CustomTime `json:"created_at"`
 }
 
+// BudgetWithSpendingResponse measures spending in the current period, from
+// PeriodStart to PeriodEnd, against AvailableAmount, the budget amount plus
+// whatever a rollover budget carried over from the previous period
 type BudgetWithSpendingResponse struct {
 	BudgetResponse
-	SpentAmount     int     `json:"spent_amount"`
-	RemainingAmount int     `json:"remaining_amount"`
-	PercentageUsed  float64 `json:"percentage_used"`
-	Status          string  `json:"status"` // safe, warning, exceeded
-	DaysRemaining   int     `json:"days_remaining"`
+	PeriodStart     utils.CustomTime `json:"period_start"`
+	PeriodEnd       utils.CustomTime `json:"period_end"`
+	CarryOver       money.Amount     `json:"carry_over"`
+	AvailableAmount money.Amount     `json:"available_amount"`
+	SpentAmount     money.Amount     `json:"spent_amount"`
+	RemainingAmount money.Amount     `json:"remaining_amount"`
+	PercentageUsed  float64          `json:"percentage_used"`
+	Status          string           `json:"status"` // safe, warning, exceeded
+	DaysRemaining   int              `json:"days_remaining"`
+}
+
+// BudgetPeriodResponse compares what was planned for one period of a budget
+// with what was actually spent in it
+type BudgetPeriodResponse struct {
+	StartDate       utils.CustomTime `json:"start_date"`
+	EndDate         utils.CustomTime `json:"end_date"`
+	PlannedAmount   money.Amount     `json:"planned_amount"`
+	CarryOver       money.Amount     `json:"carry_over"`
+	AvailableAmount money.Amount     `json:"available_amount"`
+	ActualAmount    money.Amount     `json:"actual_amount"`
+	RemainingAmount money.Amount     `json:"remaining_amount"`
+	PercentageUsed  float64          `json:"percentage_used"`
+	Status          string           `json:"status"` // safe, warning, exceeded
+	IsCurrent       bool             `json:"is_current"`
 }
 
 type BudgetFilterRequest struct {
@@ -53,16 +99,19 @@ type BudgetFilterRequest struct {
 }
 
 type BudgetAlertResponse struct {
-	ID           uint      `json:"id"`
-	BudgetID     uint      `json:"budget_id"`
-	Percentage   int       `json:"percentage"`
-	SpentAmount  int       `json:"spent_amount"`
-	Message      string    `json:"message"`
-	IsRead       bool      `json:"is_read"`
-	CreatedAt    time.Time `json:"created_at"`
-	CategoryID   uint      `json:"category_id,omitempty"`
-	CategoryName string    `json:"category_name,omitempty"`
-	BudgetAmount int       `json:"budget_amount,omitempty"`
+	ID           uint         `json:"id"`
+	BudgetID     uint         `json:"budget_id"`
+	BudgetName   string       `json:"budget_name,omitempty"`
+	Kind         string       `json:"kind"` // threshold, projected
+	Threshold    int          `json:"threshold"`
+	Percentage   int          `json:"percentage"`
+	SpentAmount  money.Amount `json:"spent_amount"`
+	Message      string       `json:"message"`
+	IsRead       bool         `json:"is_read"`
+	CreatedAt    time.Time    `json:"created_at"`
+	CategoryID   uint         `json:"category_id,omitempty"`
+	CategoryName string       `json:"category_name,omitempty"`
+	BudgetAmount money.Amount `json:"budget_amount,omitempty"`
 }
 
 type AlertFilterRequest struct {
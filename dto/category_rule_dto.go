@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"my-api/money"
+	"my-api/utils"
+)
+
+// CategoryRuleRequest creates or replaces a categorization rule. At least one
+// condition and one action are required.
+type CategoryRuleRequest struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	Priority *int   `json:"priority" binding:"omitempty,min=0"` // lower runs first, default 100
+	IsActive *bool  `json:"is_active"`
+
+	// Conditions
+	MatchType       string        `json:"match_type" binding:"omitempty,oneof=exact prefix contains regex"` // default contains
+	Pattern         string        `json:"pattern" binding:"max=255"`
+	MinAmount       *money.Amount `json:"min_amount" binding:"omitempty,gte=0"` // inclusive
+	MaxAmount       *money.Amount `json:"max_amount" binding:"omitempty,gt=0"`  // exclusive
+	TransactionType *string       `json:"transaction_type" binding:"omitempty,oneof=Income Expense income expense"`
+	AssetID         *uint64       `json:"asset_id"`
+
+	// Actions
+	CategoryID *uint  `json:"category_id"`
+	PayeeID    *uint  `json:"payee_id"`
+	TagIDs     []uint `json:"tag_ids"`
+}
+
+type CategoryRuleResponse struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+	IsActive bool   `json:"is_active"`
+
+	MatchType       string        `json:"match_type,omitempty"`
+	Pattern         string        `json:"pattern,omitempty"`
+	MinAmount       *money.Amount `json:"min_amount,omitempty"`
+	MaxAmount       *money.Amount `json:"max_amount,omitempty"`
+	TransactionType *int          `json:"transaction_type,omitempty"`
+	AssetID         *uint64       `json:"asset_id,omitempty"`
+
+	CategoryID *uint         `json:"category_id,omitempty"`
+	PayeeID    *uint         `json:"payee_id,omitempty"`
+	Tags       []TagResponse `json:"tags,omitempty"`
+}
+
+// CategoryRuleChangeResponse describes how a rule changes one transaction
+type CategoryRuleChangeResponse struct {
+	TransactionID       uint             `json:"transaction_id"`
+	Date                utils.CustomTime `json:"date"`
+	Description         string           `json:"description"`
+	Amount              money.Amount     `json:"amount"`
+	TransactionType     int              `json:"transaction_type"`
+	CurrentCategoryID   uint             `json:"current_category_id"`
+	CurrentCategoryName string           `json:"current_category_name"`
+	NewCategoryID       *uint            `json:"new_category_id,omitempty"`
+	CurrentPayeeID      *uint            `json:"current_payee_id,omitempty"`
+	NewPayeeID          *uint            `json:"new_payee_id,omitempty"`
+	AddTagIDs           []uint           `json:"add_tag_ids,omitempty"`
+}
+
+// CategoryRuleRunResponse is returned by both the dry run and the apply
+// endpoints. Changes lists at most the requested number of transactions.
+type CategoryRuleRunResponse struct {
+	RuleID  uint                         `json:"rule_id"`
+	DryRun  bool                         `json:"dry_run"`
+	Scanned int                          `json:"scanned"`
+	Matched int                          `json:"matched"`
+	Changed int                          `json:"changed"`
+	Changes []CategoryRuleChangeResponse `json:"changes,omitempty"`
+}
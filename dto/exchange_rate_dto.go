@@ -0,0 +1,49 @@
+package dto
+
+import "my-api/money"
+
+type CreateExchangeRateRequest struct {
+	BaseCurrency  string  `json:"base_currency" binding:"required,alpha,min=3,max=10"`
+	QuoteCurrency string  `json:"quote_currency" binding:"required,alpha,min=3,max=10"`
+	Rate          float64 `json:"rate" binding:"required,gt=0"` // units of quote currency per unit of base currency
+	Date          string  `json:"date" binding:"required"`      // YYYY-MM-DD
+}
+
+type ExchangeRateResponse struct {
+	ID            uint    `json:"id"`
+	BaseCurrency  string  `json:"base_currency"`
+	QuoteCurrency string  `json:"quote_currency"`
+	Rate          float64 `json:"rate"`
+	Date          string  `json:"date"`
+	Source        string  `json:"source"`
+}
+
+type ExchangeRateImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ExchangeRateImportResponse struct {
+	TotalRows int                          `json:"total_rows"`
+	Imported  int                          `json:"imported"`
+	Rejected  int                          `json:"rejected"`
+	Errors    []ExchangeRateImportRowError `json:"errors,omitempty"`
+}
+
+// CurrencyBalance is the total balance of a user's wallets in one currency.
+// ConvertedBalance covers both the cash balance and the market value of
+// investment holdings.
+type CurrencyBalance struct {
+	Currency         string       `json:"currency"`
+	Balance          money.Amount `json:"balance"`
+	HoldingsValue    money.Amount `json:"holdings_value"`
+	Rate             float64      `json:"rate"`
+	ConvertedBalance money.Amount `json:"converted_balance"`
+	RateMissing      bool         `json:"rate_missing,omitempty"` // no rate known, converted at 1
+}
+
+type WalletSummaryResponse struct {
+	BaseCurrency string            `json:"base_currency"`
+	TotalBalance money.Amount      `json:"total_balance"` // in the base currency
+	Currencies   []CurrencyBalance `json:"currencies"`
+}
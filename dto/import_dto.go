@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"my-api/money"
+	"my-api/utils"
+)
+
+type ImportProfileRequest struct {
+	Name              string  `json:"name" binding:"required,max=100"`
+	Delimiter         string  `json:"delimiter" binding:"omitempty,len=1"`
+	HasHeader         *bool   `json:"has_header"`
+	SkipRows          int     `json:"skip_rows" binding:"omitempty,min=0,max=100"`
+	DateColumn        string  `json:"date_column" binding:"required,max=100"`
+	DateFormat        string  `json:"date_format" binding:"omitempty,max=50"`
+	DescriptionColumn string  `json:"description_column" binding:"omitempty,max=100"`
+	AmountColumn      string  `json:"amount_column" binding:"omitempty,max=100"`
+	DebitColumn       string  `json:"debit_column" binding:"omitempty,max=100"`
+	CreditColumn      string  `json:"credit_column" binding:"omitempty,max=100"`
+	TypeColumn        string  `json:"type_column" binding:"omitempty,max=100"`
+	DebitValue        string  `json:"debit_value" binding:"omitempty,max=20"`
+	CreditValue       string  `json:"credit_value" binding:"omitempty,max=20"`
+	DecimalSeparator  string  `json:"decimal_separator" binding:"omitempty,oneof=. ,"`
+	AssetID           *uint64 `json:"asset_id"`
+	IncomeCategoryID  *uint   `json:"income_category_id"`
+	ExpenseCategoryID *uint   `json:"expense_category_id"`
+}
+
+// ImportCSVRequest holds the multipart form fields sent along with the file
+type ImportCSVRequest struct {
+	ProfileID  uint    `form:"profile_id" binding:"required"`
+	AssetID    *uint64 `form:"asset_id"`    // overrides the profile's default asset
+	CategoryID *uint   `form:"category_id"` // overrides the profile's default categories
+	Commit     bool    `form:"commit"`      // false returns a dry-run preview
+}
+
+// ImportFileRequest holds the multipart form fields for OFX and QIF uploads
+type ImportFileRequest struct {
+	AssetID           uint64 `form:"asset_id" binding:"required"`
+	CategoryID        *uint  `form:"category_id"`
+	IncomeCategoryID  *uint  `form:"income_category_id"`
+	ExpenseCategoryID *uint  `form:"expense_category_id"`
+	DateFormat        string `form:"date_format"` // QIF only, e.g. DD/MM/YYYY
+	Commit            bool   `form:"commit"`
+}
+
+type ImportRowResponse struct {
+	Line            int              `json:"line"`
+	Date            utils.CustomTime `json:"date"`
+	Description     string           `json:"description"`
+	Payee           string           `json:"payee,omitempty"`
+	Amount          money.Amount     `json:"amount"`
+	TransactionType int              `json:"transaction_type"`
+	PayeeID         *uint            `json:"payee_id,omitempty"`
+	TagIDs          []uint           `json:"tag_ids,omitempty"`
+	CategoryID      uint             `json:"category_id"`
+	Status          string           `json:"status"` // new, created, duplicate, rejected
+	Error           string           `json:"error,omitempty"`
+}
+
+type ImportResultResponse struct {
+	Committed         bool                `json:"committed"`
+	AssetID           uint64              `json:"asset_id"`
+	TotalRows         int                 `json:"total_rows"`
+	Created           int                 `json:"created"` // rows that were (or, in a preview, would be) created
+	SkippedDuplicates int                 `json:"skipped_duplicates"`
+	Rejected          int                 `json:"rejected"`
+	TotalIncome       money.Amount        `json:"total_income"`
+	TotalExpense      money.Amount        `json:"total_expense"`
+	Rows              []ImportRowResponse `json:"rows"`
+}
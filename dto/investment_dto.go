@@ -0,0 +1,108 @@
+package dto
+
+import "my-api/money"
+
+type CreateInstrumentRequest struct {
+	Symbol   string `json:"symbol" binding:"required,max=50"`
+	Name     string `json:"name" binding:"max=255"`
+	Kind     string `json:"kind" binding:"omitempty,oneof=stock fund bond gold other"`
+	Currency string `json:"currency" binding:"required,alpha,min=3,max=10"`
+	Unit     string `json:"unit" binding:"max=20"`
+}
+
+type UpdateInstrumentRequest struct {
+	Name *string `json:"name" binding:"omitempty,max=255"`
+	Kind *string `json:"kind" binding:"omitempty,oneof=stock fund bond gold other"`
+	Unit *string `json:"unit" binding:"omitempty,max=20"`
+}
+
+type InstrumentResponse struct {
+	ID       uint   `json:"id"`
+	Symbol   string `json:"symbol"`
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	Currency string `json:"currency"`
+	Unit     string `json:"unit"`
+}
+
+type CreateHoldingLotRequest struct {
+	InstrumentID uint         `json:"instrument_id" binding:"required"`
+	Side         string       `json:"side" binding:"required,oneof=buy sell"`
+	Quantity     float64      `json:"quantity" binding:"required,gt=0"`
+	Price        money.Amount `json:"price" binding:"required,gt=0"` // per unit
+	Fees         money.Amount `json:"fees" binding:"gte=0"`
+	Date         string       `json:"date" binding:"required"` // YYYY-MM-DD
+	Note         string       `json:"note" binding:"max=255"`
+}
+
+type HoldingLotResponse struct {
+	ID           uint         `json:"id"`
+	AssetID      uint64       `json:"asset_id"`
+	InstrumentID uint         `json:"instrument_id"`
+	Symbol       string       `json:"symbol"`
+	Side         string       `json:"side"`
+	Quantity     float64      `json:"quantity"`
+	Price        money.Amount `json:"price"`
+	Fees         money.Amount `json:"fees"`
+	Date         string       `json:"date"`
+	Note         string       `json:"note"`
+}
+
+type CreateInstrumentPriceRequest struct {
+	Price money.Amount `json:"price" binding:"required,gt=0"`
+	Date  string       `json:"date" binding:"required"` // YYYY-MM-DD
+}
+
+type InstrumentPriceResponse struct {
+	ID           uint         `json:"id"`
+	InstrumentID uint         `json:"instrument_id"`
+	Price        money.Amount `json:"price"`
+	Date         string       `json:"date"`
+	Source       string       `json:"source"`
+}
+
+type InstrumentPriceImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type InstrumentPriceImportResponse struct {
+	TotalRows int                             `json:"total_rows"`
+	Imported  int                             `json:"imported"`
+	Rejected  int                             `json:"rejected"`
+	Errors    []InstrumentPriceImportRowError `json:"errors,omitempty"`
+}
+
+// HoldingResponse is the position in one instrument, valued at average cost.
+// Without a known price the holding is valued at its cost basis.
+type HoldingResponse struct {
+	InstrumentID   uint         `json:"instrument_id"`
+	Symbol         string       `json:"symbol"`
+	Name           string       `json:"name"`
+	Kind           string       `json:"kind"`
+	Unit           string       `json:"unit"`
+	Quantity       float64      `json:"quantity"`
+	AverageCost    money.Amount `json:"average_cost"`
+	CostBasis      money.Amount `json:"cost_basis"`
+	Price          money.Amount `json:"price"`
+	PriceDate      string       `json:"price_date,omitempty"`
+	PriceMissing   bool         `json:"price_missing,omitempty"`
+	MarketValue    money.Amount `json:"market_value"`
+	UnrealizedGain money.Amount `json:"unrealized_gain"`
+	RealizedGain   money.Amount `json:"realized_gain"`
+}
+
+// HoldingsResponse totals the holdings of one investment asset. TotalValue
+// adds the asset's cash balance to the market value.
+type HoldingsResponse struct {
+	AssetID        uint64            `json:"asset_id"`
+	AssetName      string            `json:"asset_name"`
+	Currency       string            `json:"currency"`
+	CashBalance    money.Amount      `json:"cash_balance"`
+	CostBasis      money.Amount      `json:"cost_basis"`
+	MarketValue    money.Amount      `json:"market_value"`
+	UnrealizedGain money.Amount      `json:"unrealized_gain"`
+	RealizedGain   money.Amount      `json:"realized_gain"`
+	TotalValue     money.Amount      `json:"total_value"`
+	Holdings       []HoldingResponse `json:"holdings"`
+}
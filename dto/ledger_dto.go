@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"my-api/money"
+	"my-api/utils"
+	"time"
+)
+
+// LedgerFilter limits a statement to a date range; both ends are inclusive
+type LedgerFilter struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// LedgerLineResponse is one posting on a wallet statement. Amount is signed
+// (positive adds to the wallet) and Balance is the running balance after it.
+type LedgerLineResponse struct {
+	PostingID     uint             `json:"posting_id"`
+	EntryID       uint             `json:"entry_id"`
+	EntryType     string           `json:"entry_type"`
+	TransactionID *uint            `json:"transaction_id,omitempty"`
+	TransferID    *uint            `json:"transfer_id,omitempty"`
+	ReversesID    *uint            `json:"reverses_id,omitempty"`
+	Description   string           `json:"description"`
+	Date          utils.CustomTime `json:"date"`
+	Amount        money.Amount     `json:"amount"`
+	Balance       money.Amount     `json:"balance"`
+}
+
+// LedgerStatementResponse is a running-balance statement for one wallet.
+// StoredBalance is Asset.Balance and LedgerBalance the sum of all postings;
+// the two only differ when the stored balance has drifted.
+type LedgerStatementResponse struct {
+	AssetID        uint64               `json:"asset_id"`
+	AssetName      string               `json:"asset_name"`
+	Currency       string               `json:"currency"`
+	OpeningBalance money.Amount         `json:"opening_balance"`
+	ClosingBalance money.Amount         `json:"closing_balance"`
+	Lines          []LedgerLineResponse `json:"lines"`
+	StoredBalance  money.Amount         `json:"stored_balance"`
+	LedgerBalance  money.Amount         `json:"ledger_balance"`
+	Difference     money.Amount         `json:"difference"`
+	InBalance      bool                 `json:"in_balance"`
+}
+
+// CreditStatementResponse is the latest closed statement of a credit card or
+// loan. Amounts owed are positive; AvailableCredit is what can still be
+// spent before the credit limit.
+type CreditStatementResponse struct {
+	AssetID          uint64           `json:"asset_id"`
+	AssetName        string           `json:"asset_name"`
+	Currency         string           `json:"currency"`
+	StartDate        utils.CustomTime `json:"start_date"`
+	ClosingDate      utils.CustomTime `json:"closing_date"`
+	DueDate          utils.CustomTime `json:"due_date"`
+	PreviousBalance  money.Amount     `json:"previous_balance"`
+	Charges          money.Amount     `json:"charges"`
+	Credits          money.Amount     `json:"credits"`
+	StatementBalance money.Amount     `json:"statement_balance"`
+	MinimumPayment   money.Amount     `json:"minimum_payment"`
+	PaidSinceClosing money.Amount     `json:"paid_since_closing"`
+	RemainingDue     money.Amount     `json:"remaining_due"`
+	RemainingMinimum money.Amount     `json:"remaining_minimum"`
+	CurrentBalance   money.Amount     `json:"current_balance"`
+	CreditLimit      money.Amount     `json:"credit_limit"`
+	AvailableCredit  money.Amount     `json:"available_credit"`
+}
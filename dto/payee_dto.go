@@ -0,0 +1,49 @@
+package dto
+
+import "my-api/money"
+
+type CreatePayeeRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type UpdatePayeeRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type PayeeRuleRequest struct {
+	PayeeID   uint   `json:"payee_id" binding:"required"`
+	MatchType string `json:"match_type" binding:"required,oneof=exact prefix contains regex"`
+	Pattern   string `json:"pattern" binding:"required,max=255"`
+	Priority  *int   `json:"priority" binding:"omitempty,min=0"` // lower runs first, default 100
+}
+
+type PayeeRuleResponse struct {
+	ID        uint   `json:"id"`
+	PayeeID   uint   `json:"payee_id"`
+	MatchType string `json:"match_type"`
+	Pattern   string `json:"pattern"`
+	Priority  int    `json:"priority"`
+}
+
+type PayeeResponse struct {
+	ID    uint                `json:"id"`
+	Name  string              `json:"name"`
+	Rules []PayeeRuleResponse `json:"rules"`
+}
+
+type ApplyPayeeRulesResponse struct {
+	Scanned  int `json:"scanned"`
+	Assigned int `json:"assigned"`
+}
+
+type SpendingByPayeeResponse struct {
+	PayeeID       uint         `json:"payee_id"` // 0 groups transactions without a payee
+	PayeeName     string       `json:"payee_name"`
+	TotalAmount   money.Amount `json:"total_amount"`
+	Count         int          `json:"count"`
+	AverageAmount money.Amount `json:"average_amount"`
+	Percentage    float64      `json:"percentage"`
+
+	OriginalAmounts map[string]money.Amount `json:"original_amounts,omitempty"`
+	RatesMissing    []string                `json:"rates_missing,omitempty"` // currencies converted at 1
+}
@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"my-api/money"
+	"my-api/utils"
+)
+
+// AssetBalanceCheck compares a wallet's stored balance with the balance
+// recomputed from its opening balance, adjustments and transactions, and with
+// the ledger
+type AssetBalanceCheck struct {
+	AssetID           uint64       `json:"asset_id"`
+	UserID            uint64       `json:"user_id"`
+	AssetName         string       `json:"asset_name"`
+	Currency          string       `json:"currency"`
+	StoredBalance     money.Amount `json:"stored_balance"`
+	ExpectedBalance   money.Amount `json:"expected_balance"`
+	LedgerBalance     money.Amount `json:"ledger_balance"`
+	Discrepancy       money.Amount `json:"discrepancy"`        // stored - expected
+	LedgerDiscrepancy money.Amount `json:"ledger_discrepancy"` // stored - ledger
+	Adjusted          money.Amount `json:"adjusted,omitempty"` // posted by a fix run
+}
+
+type ReconciliationReportResponse struct {
+	Checked       int                 `json:"checked"`
+	Discrepancies int                 `json:"discrepancies"`
+	Assets        []AssetBalanceCheck `json:"assets"`
+}
+
+// FixDiscrepanciesRequest limits a fix run to some assets; empty means all
+type FixDiscrepanciesRequest struct {
+	AssetIDs []uint64 `json:"asset_ids"`
+}
+
+type CreateReconciliationRequest struct {
+	StatementDate    string        `json:"statement_date" binding:"required"` // YYYY-MM-DD
+	StatementBalance *money.Amount `json:"statement_balance" binding:"required"`
+	Note             string        `json:"note" binding:"max=255"`
+	Adjust           bool          `json:"adjust"` // post any difference as an adjustment instead of rejecting
+}
+
+type ReconciliationResponse struct {
+	ID               uint             `json:"id"`
+	AssetID          uint64           `json:"asset_id"`
+	StatementDate    utils.CustomTime `json:"statement_date"`
+	StatementBalance money.Amount     `json:"statement_balance"`
+	ComputedBalance  money.Amount     `json:"computed_balance"`
+	Adjustment       money.Amount     `json:"adjustment"`
+	Note             string           `json:"note"`
+	CreatedAt        utils.CustomTime `json:"created_at"`
+
+	TransactionsReconciled int64 `json:"transactions_reconciled,omitempty"` // Set when the reconciliation is created
+}
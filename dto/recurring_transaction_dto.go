@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"my-api/money"
+	"my-api/utils"
+)
+
+type CreateRecurringTransactionRequest struct {
+	Description     string            `json:"description" binding:"required,max=200"`
+	CategoryID      uint              `json:"category_id" binding:"required"`
+	AssetID         uint64            `json:"asset_id" binding:"required"`
+	Amount          money.Amount      `json:"amount" binding:"required,gt=0"`
+	TransactionType string            `json:"transaction_type" binding:"required,oneof=Income Expense income expense"`
+	Frequency       string            `json:"frequency" binding:"required,oneof=daily weekly monthly yearly pay_cycle"`
+	Interval        int               `json:"interval" binding:"omitempty,min=1,max=365"`
+	StartDate       utils.CustomTime  `json:"start_date" binding:"required"`
+	EndDate         *utils.CustomTime `json:"end_date"`
+}
+
+type UpdateRecurringTransactionRequest struct {
+	Description     *string           `json:"description" binding:"omitempty,max=200"`
+	CategoryID      *uint             `json:"category_id"`
+	AssetID         *uint64           `json:"asset_id"`
+	Amount          *money.Amount     `json:"amount" binding:"omitempty,gt=0"`
+	TransactionType *string           `json:"transaction_type" binding:"omitempty,oneof=Income Expense income expense"`
+	Frequency       *string           `json:"frequency" binding:"omitempty,oneof=daily weekly monthly yearly pay_cycle"`
+	Interval        *int              `json:"interval" binding:"omitempty,min=1,max=365"`
+	StartDate       *utils.CustomTime `json:"start_date"`
+	EndDate         *utils.CustomTime `json:"end_date"`
+	IsActive        *bool             `json:"is_active"`
+}
+
+type SkipOccurrenceRequest struct {
+	Date utils.CustomTime `json:"date" binding:"required"`
+}
+
+type RetryOccurrenceRequest struct {
+	Date utils.CustomTime `json:"date" binding:"required"`
+}
+
+type RecurringTransactionResponse struct {
+	ID              uint              `json:"id"`
+	Description     string            `json:"description"`
+	CategoryID      uint              `json:"category_id"`
+	CategoryName    string            `json:"category_name"`
+	AssetID         uint64            `json:"asset_id"`
+	AssetName       string            `json:"asset_name"`
+	Amount          money.Amount      `json:"amount"`
+	TransactionType int               `json:"transaction_type"`
+	Frequency       string            `json:"frequency"`
+	Interval        int               `json:"interval"`
+	StartDate       utils.CustomTime  `json:"start_date"`
+	EndDate         *utils.CustomTime `json:"end_date"`
+	NextOccurrence  *utils.CustomTime `json:"next_occurrence"`
+	IsActive        bool              `json:"is_active"`
+	CreatedAt       utils.CustomTime  `json:"created_at"`
+}
+
+type RecurringOccurrenceResponse struct {
+	Date          utils.CustomTime `json:"date"`
+	Status        string           `json:"status"` // scheduled, pending, posted, skipped, failed
+	TransactionID *uint            `json:"transaction_id,omitempty"`
+	Message       string           `json:"message,omitempty"`
+}
+
+type RecurringTransactionFilterRequest struct {
+	PaginationRequest
+	AssetID   uint64 `form:"asset_id"`
+	Frequency string `form:"frequency"`
+	IsActive  *bool  `form:"is_active"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import "my-api/money"
+
+type CreateTagRequest struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Color string `json:"color" binding:"omitempty,hexcolor"`
+}
+
+type UpdateTagRequest struct {
+	Name  *string `json:"name" binding:"omitempty,min=1,max=50"`
+	Color *string `json:"color" binding:"omitempty,hexcolor"`
+}
+
+type TagResponse struct {
+	ID               uint   `json:"id"`
+	Name             string `json:"name"`
+	Color            string `json:"color,omitempty"`
+	TransactionCount *int64 `json:"transaction_count,omitempty"`
+}
+
+type SpendingByTagResponse struct {
+	TagID       uint         `json:"tag_id"`
+	TagName     string       `json:"tag_name"`
+	Color       string       `json:"color,omitempty"`
+	TotalAmount money.Amount `json:"total_amount"`
+	Percentage  float64      `json:"percentage"` // share of all expenses in the period
+	Count       int          `json:"count"`
+
+	OriginalAmounts map[string]money.Amount `json:"original_amounts,omitempty"`
+	RatesMissing    []string                `json:"rates_missing,omitempty"` // currencies converted at 1
+}
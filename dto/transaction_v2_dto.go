@@ -1,43 +1,96 @@
 package dto
 
 import (
+	"my-api/money"
 	"my-api/utils"
+	"time"
 )
 
+// TransactionV2Filter holds the optional filters shared by the v2 list and
+// export endpoints
+type TransactionV2Filter struct {
+	StartDate       *time.Time
+	EndDate         *time.Time
+	TransactionType *int
+	CategoryID      *uint
+	AssetID         *uint64
+	TagIDs          []uint // matches transactions with any of the tags
+	Status          string // pending, cleared or reconciled
+}
+
 // TransactionV2Response represents transaction response with asset information
 type TransactionV2Response struct {
 	ID              uint             `json:"id"`
 	Description     string           `json:"description"`
-	Amount          int              `json:"amount"`
+	Amount          money.Amount     `json:"amount"`
 	TransactionType int              `json:"transaction_type"`
+	Status          string           `json:"status"`
 	Date            utils.CustomTime `json:"date"`
 	CategoryName    string           `json:"category_name"`
 	BankName        string           `json:"bank_name,omitempty"`
 	AssetID         uint64           `json:"asset_id"`
 	AssetName       string           `json:"asset_name,omitempty"`
 	AssetType       string           `json:"asset_type,omitempty"`
-	AssetBalance    float64          `json:"asset_balance,omitempty"`
+	AssetBalance    money.Amount     `json:"asset_balance,omitempty"`
 	AssetCurrency   string           `json:"asset_currency,omitempty"`
+	TransferID      *uint            `json:"transfer_id,omitempty"`
+	PayeeID         *uint            `json:"payee_id,omitempty"`
+	PayeeName       string           `json:"payee_name,omitempty"`
+
+	// Asset balance just after this transaction in date order, set when the
+	// list is limited to one asset
+	RunningBalance *money.Amount `json:"running_balance,omitempty"`
+
+	// When the transaction was moved to the trash, set on trash listings
+	DeletedAt *utils.CustomTime `json:"deleted_at,omitempty"`
+
+	Splits []TransactionSplitResponse `json:"splits,omitempty"`
+	Tags   []TagResponse              `json:"tags,omitempty"`
+}
+
+// TransactionSplitRequest represents one category line of a split transaction
+type TransactionSplitRequest struct {
+	CategoryID  uint         `json:"category_id" binding:"required"`
+	Amount      money.Amount `json:"amount" binding:"required,gt=0"`
+	Description string       `json:"description" binding:"omitempty,max=200"`
+}
+
+// TransactionSplitResponse represents one category line of a split transaction
+type TransactionSplitResponse struct {
+	ID           uint         `json:"id"`
+	CategoryID   uint         `json:"category_id"`
+	CategoryName string       `json:"category_name"`
+	Amount       money.Amount `json:"amount"`
+	Description  string       `json:"description,omitempty"`
 }
 
 // CreateTransactionV2Request represents request to create transaction with asset
 type CreateTransactionV2Request struct {
-	Description     string `json:"description" binding:"required"`
-	CategoryID      uint   `json:"category_id" binding:"required"`
-	AssetID         uint64 `json:"asset_id" binding:"required"`
-	Amount          int    `json:"amount" binding:"required,min=1"`
-	TransactionType string `json:"transaction_type" binding:"required,oneof=Income Expense income expense"`
-	Date            string `json:"date" binding:"required"`
+	Description     string       `json:"description" binding:"required"`
+	CategoryID      uint         `json:"category_id"` // Optional when a category rule matches
+	AssetID         uint64       `json:"asset_id" binding:"required"`
+	Amount          money.Amount `json:"amount" binding:"required,gt=0"`
+	TransactionType string       `json:"transaction_type" binding:"required,oneof=Income Expense income expense"`
+	Date            string       `json:"date" binding:"required"`
+	PayeeID         *uint        `json:"payee_id"`                                         // Optional, resolved from payee rules when omitted
+	Status          string       `json:"status" binding:"omitempty,oneof=pending cleared"` // Defaults to cleared
+
+	Splits []TransactionSplitRequest `json:"splits" binding:"omitempty,dive"` // Optional, must add up to amount
+	TagIDs []uint                    `json:"tag_ids"`
 }
 
 // UpdateTransactionV2Request represents request to update transaction
 type UpdateTransactionV2Request struct {
-	Description     *string `json:"description"`
-	CategoryID      *uint   `json:"category_id"`
-	AssetID         *uint64 `json:"asset_id"`
-	Amount          *int    `json:"amount"`
-	TransactionType *string `json:"transaction_type"`
-	Date            *string `json:"date"`
+	Description     *string       `json:"description"`
+	CategoryID      *uint         `json:"category_id"`
+	AssetID         *uint64       `json:"asset_id"`
+	Amount          *money.Amount `json:"amount"`
+	TransactionType *string       `json:"transaction_type"`
+	Date            *string       `json:"date"`
+	PayeeID         *uint         `json:"payee_id"`
+
+	Splits *[]TransactionSplitRequest `json:"splits" binding:"omitempty,dive"` // Replaces existing splits, [] removes them
+	TagIDs *[]uint                    `json:"tag_ids"`                         // Replaces existing tags, [] removes them
 }
 
 // AssetTransactionsResponse represents transactions for a specific asset
@@ -45,9 +98,17 @@ type AssetTransactionsResponse struct {
 	AssetID        uint64                  `json:"asset_id"`
 	AssetName      string                  `json:"asset_name"`
 	AssetType      string                  `json:"asset_type"`
-	CurrentBalance float64                 `json:"current_balance"`
+	CurrentBalance money.Amount            `json:"current_balance"`
+	ClearedBalance money.Amount            `json:"cleared_balance"` // Current balance without pending transactions
 	Currency       string                  `json:"currency"`
 	Transactions   []TransactionV2Response `json:"transactions"`
-	TotalIncome    float64                 `json:"total_income"`
-	TotalExpense   float64                 `json:"total_expense"`
+	TotalIncome    money.Amount            `json:"total_income"`
+	TotalExpense   money.Amount            `json:"total_expense"`
+}
+
+// UpdateTransactionStatusRequest changes the status of a transaction. Moving
+// a reconciled transaction to another status requires Unlock.
+type UpdateTransactionStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=pending cleared reconciled"`
+	Unlock bool   `json:"unlock"`
 }
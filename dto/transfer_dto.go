@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"my-api/money"
+	"my-api/utils"
+)
+
+// CreateTransferRequest represents request to move money between two assets
+type CreateTransferRequest struct {
+	SourceAssetID uint64       `json:"source_asset_id" binding:"required"`
+	TargetAssetID uint64       `json:"target_asset_id" binding:"required"`
+	Amount        money.Amount `json:"amount" binding:"required,gt=0"`
+	Description   string       `json:"description" binding:"omitempty,max=200"`
+	CategoryID    uint         `json:"category_id"` // Optional category for the generated transactions
+	Date          string       `json:"date" binding:"required"`
+}
+
+// CreatePaymentRequest represents a payment towards a credit card or loan
+// from another asset
+type CreatePaymentRequest struct {
+	SourceAssetID uint64       `json:"source_asset_id" binding:"required"`
+	Amount        money.Amount `json:"amount" binding:"required,gt=0"`
+	Description   string       `json:"description" binding:"omitempty,max=200"`
+	CategoryID    uint         `json:"category_id"`
+	Date          string       `json:"date" binding:"required"`
+}
+
+// TransferResponse represents a transfer with its source and target asset information
+type TransferResponse struct {
+	ID                  uint             `json:"id"`
+	SourceAssetID       uint64           `json:"source_asset_id"`
+	SourceAssetName     string           `json:"source_asset_name"`
+	TargetAssetID       uint64           `json:"target_asset_id"`
+	TargetAssetName     string           `json:"target_asset_name"`
+	Amount              money.Amount     `json:"amount"`
+	Currency            string           `json:"currency"`
+	Description         string           `json:"description"`
+	Date                utils.CustomTime `json:"date"`
+	SourceTransactionID uint             `json:"source_transaction_id"`
+	TargetTransactionID uint             `json:"target_transaction_id"`
+	CreatedAt           utils.CustomTime `json:"created_at"`
+}
@@ -8,6 +8,7 @@ type UserSettingsResponse struct {
 	PayCycleType     models.PayCycleType   `json:"pay_cycle_type"`
 	PayDay           *int                  `json:"pay_day"`
 	CycleStartOffset int                   `json:"cycle_start_offset"`
+	BaseCurrency     string                `json:"base_currency"`
 	CreatedAt        string                `json:"created_at"`
 	UpdatedAt        *string               `json:"updated_at"`
 }
@@ -16,12 +17,14 @@ type CreateUserSettingsRequest struct {
 	PayCycleType     models.PayCycleType `json:"pay_cycle_type" binding:"required,oneof=calendar last_weekday custom_day bi_weekly"`
 	PayDay           *int                `json:"pay_day"`
 	CycleStartOffset int                 `json:"cycle_start_offset" binding:"min=0,max=31"`
+	BaseCurrency     string              `json:"base_currency" binding:"omitempty,alpha,min=3,max=10"` // Defaults to IDR
 }
 
 type UpdateUserSettingsRequest struct {
 	PayCycleType     models.PayCycleType `json:"pay_cycle_type" binding:"omitempty,oneof=calendar last_weekday custom_day bi_weekly"`
 	PayDay           *int                `json:"pay_day"`
 	CycleStartOffset *int                `json:"cycle_start_offset" binding:"omitempty,min=0,max=31"`
+	BaseCurrency     *string             `json:"base_currency" binding:"omitempty,alpha,min=3,max=10"`
 }
 
 // ValidatePayCycleSettings validates the consistency between pay_cycle_type and pay_day
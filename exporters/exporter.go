@@ -0,0 +1,125 @@
+// Package exporters writes tabular data as CSV, JSON or XLSX one row at a
+// time, so exports can be streamed straight into an HTTP response.
+package exporters
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Writer streams rows in a specific file format. WriteHeader must be called
+// once before the first row, and Close finishes the document.
+type Writer interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// Format describes an export format for HTTP responses
+type Format struct {
+	Name        string
+	ContentType string
+	Extension   string
+}
+
+var formats = map[string]Format{
+	"csv":  {Name: "csv", ContentType: "text/csv; charset=utf-8", Extension: "csv"},
+	"json": {Name: "json", ContentType: "application/json; charset=utf-8", Extension: "json"},
+	"xlsx": {Name: "xlsx", ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Extension: "xlsx"},
+}
+
+// LookupFormat returns the format with the given name
+func LookupFormat(name string) (Format, error) {
+	format, ok := formats[name]
+	if !ok {
+		return Format{}, errors.New("unsupported export format: " + name)
+	}
+	return format, nil
+}
+
+// NewWriter creates a writer for the format
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format.Name {
+	case "csv":
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case "json":
+		return &jsonWriter{writer: w}, nil
+	case "xlsx":
+		return newXLSXWriter(w), nil
+	}
+	return nil, errors.New("unsupported export format: " + format.Name)
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWriter) WriteHeader(columns []string) error {
+	return w.writer.Write(columns)
+}
+
+func (w *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		if value != nil {
+			record[i] = fmt.Sprint(value)
+		}
+	}
+	return w.writer.Write(record)
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// jsonWriter writes a JSON array of objects keyed by the header columns
+type jsonWriter struct {
+	writer  io.Writer
+	columns []string
+	rows    int
+}
+
+func (w *jsonWriter) WriteHeader(columns []string) error {
+	w.columns = columns
+	_, err := io.WriteString(w.writer, "[")
+	return err
+}
+
+func (w *jsonWriter) WriteRow(values []interface{}) error {
+	if len(values) != len(w.columns) {
+		return errors.New("row does not match header")
+	}
+
+	buf := make([]byte, 0, 256)
+	if w.rows > 0 {
+		buf = append(buf, ',')
+	}
+	buf = append(buf, '\n', '{')
+	for i, column := range w.columns {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		key, _ := json.Marshal(column)
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		buf = append(buf, key...)
+		buf = append(buf, ':')
+		buf = append(buf, value...)
+	}
+	buf = append(buf, '}')
+
+	w.rows++
+	_, err := w.writer.Write(buf)
+	return err
+}
+
+func (w *jsonWriter) Close() error {
+	_, err := io.WriteString(w.writer, "\n]\n")
+	return err
+}
@@ -0,0 +1,81 @@
+package exporters
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func writeAll(t *testing.T, name string, buf *bytes.Buffer) {
+	format, err := LookupFormat(name)
+	if err != nil {
+		t.Fatalf("LookupFormat(%q) failed: %v", name, err)
+	}
+	writer, err := NewWriter(format, buf)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := writer.WriteHeader([]string{"id", "description", "amount"}); err != nil {
+		t.Fatalf("WriteHeader failed: %v", err)
+	}
+	for _, row := range [][]interface{}{{1, "Coffee & cake", 45000}, {2, "Salary", 2500000}} {
+		if err := writer.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow failed: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+}
+
+func TestJSONWriterProducesArray(t *testing.T) {
+	var buf bytes.Buffer
+	writeAll(t, "json", &buf)
+
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if len(rows) != 2 || rows[0]["description"] != "Coffee & cake" || rows[1]["amount"] != float64(2500000) {
+		t.Errorf("unexpected rows: %v", rows)
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writeAll(t, "csv", &buf)
+
+	want := "id,description,amount\n1,Coffee & cake,45000\n2,Salary,2500000\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestXLSXWriterProducesWorkbook(t *testing.T) {
+	var buf bytes.Buffer
+	writeAll(t, "xlsx", &buf)
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+
+	var sheet string
+	for _, file := range archive.File {
+		if file.Name == "xl/worksheets/sheet1.xml" {
+			rc, _ := file.Open()
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			sheet = string(data)
+		}
+	}
+	if !strings.Contains(sheet, "Coffee &amp; cake") || !strings.Contains(sheet, `<c t="n"><v>2500000</v></c>`) {
+		t.Errorf("unexpected sheet content: %s", sheet)
+	}
+	if !strings.HasSuffix(sheet, "</sheetData></worksheet>") {
+		t.Errorf("sheet is not terminated: %s", sheet)
+	}
+}
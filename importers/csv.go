@@ -0,0 +1,214 @@
+package importers
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"my-api/money"
+	"strconv"
+	"strings"
+)
+
+// CSVMapping describes where the statement fields live in a CSV export.
+// Columns are given either as a header name or as a 1-based column number.
+//
+// Amounts can be read in three ways:
+//   - DebitColumn/CreditColumn: separate money-out and money-in columns
+//   - AmountColumn + TypeColumn: an unsigned amount plus a debit/credit marker
+//   - AmountColumn alone: a signed amount, negative means expense. A trailing
+//     DebitValue/CreditValue marker in the amount cell (e.g. "50,000.00 DB")
+//     is also recognized.
+type CSVMapping struct {
+	Delimiter         rune
+	HasHeader         bool
+	SkipRows          int
+	DateColumn        string
+	DateFormat        string
+	DescriptionColumn string
+	AmountColumn      string
+	DebitColumn       string
+	CreditColumn      string
+	TypeColumn        string
+	DebitValue        string
+	CreditValue       string
+	DecimalSeparator  string
+}
+
+// ParseCSV reads a CSV statement and returns one Row per data line. Lines that
+// cannot be parsed are returned with Error set instead of failing the import.
+func ParseCSV(reader io.Reader, mapping CSVMapping) ([]Row, error) {
+	csvReader := csv.NewReader(reader)
+	if mapping.Delimiter != 0 {
+		csvReader.Comma = mapping.Delimiter
+	}
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping.SkipRows > 0 {
+		if mapping.SkipRows >= len(records) {
+			return []Row{}, nil
+		}
+		records = records[mapping.SkipRows:]
+	}
+
+	var header []string
+	lineOffset := mapping.SkipRows + 1
+	if mapping.HasHeader {
+		if len(records) == 0 {
+			return []Row{}, nil
+		}
+		header = records[0]
+		records = records[1:]
+		lineOffset++
+	}
+
+	columns := map[string]int{}
+	for name, spec := range map[string]string{
+		"date":        mapping.DateColumn,
+		"description": mapping.DescriptionColumn,
+		"amount":      mapping.AmountColumn,
+		"debit":       mapping.DebitColumn,
+		"credit":      mapping.CreditColumn,
+		"type":        mapping.TypeColumn,
+	} {
+		if spec == "" {
+			continue
+		}
+		index, err := resolveColumn(spec, header)
+		if err != nil {
+			return nil, err
+		}
+		columns[name] = index
+	}
+
+	if _, ok := columns["date"]; !ok {
+		return nil, errors.New("date column is required")
+	}
+	_, hasAmount := columns["amount"]
+	_, hasDebit := columns["debit"]
+	_, hasCredit := columns["credit"]
+	if !hasAmount && !hasDebit && !hasCredit {
+		return nil, errors.New("amount column or debit/credit columns are required")
+	}
+
+	rows := make([]Row, 0, len(records))
+	for i, record := range records {
+		if isBlankRecord(record) {
+			continue
+		}
+		row := Row{Line: i + lineOffset}
+		if err := parseCSVRecord(record, columns, mapping, &row); err != nil {
+			row.Error = err.Error()
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseCSVRecord(record []string, columns map[string]int, mapping CSVMapping, row *Row) error {
+	date, err := ParseDate(cell(record, columns, "date"), mapping.DateFormat)
+	if err != nil {
+		return err
+	}
+	row.Date = date
+	row.Description = cell(record, columns, "description")
+
+	if _, ok := columns["amount"]; !ok {
+		debit := cell(record, columns, "debit")
+		credit := cell(record, columns, "credit")
+		if amount, err := ParseAmount(debit, mapping.DecimalSeparator); err == nil && amount != 0 {
+			row.Amount, row.TransactionType = abs(amount), 2
+			return nil
+		}
+		if amount, err := ParseAmount(credit, mapping.DecimalSeparator); err == nil && amount != 0 {
+			row.Amount, row.TransactionType = abs(amount), 1
+			return nil
+		}
+		return errors.New("missing amount")
+	}
+
+	raw := cell(record, columns, "amount")
+	amount, err := ParseAmount(raw, mapping.DecimalSeparator)
+	if err != nil {
+		return err
+	}
+	if amount == 0 {
+		return errors.New("amount must not be zero")
+	}
+
+	transactionType := 1
+	if amount < 0 {
+		transactionType = 2
+	}
+
+	marker := raw
+	if _, ok := columns["type"]; ok {
+		marker = cell(record, columns, "type")
+	}
+	if hasMarker(marker, mapping.DebitValue) {
+		transactionType = 2
+	} else if hasMarker(marker, mapping.CreditValue) {
+		transactionType = 1
+	}
+
+	row.Amount = abs(amount)
+	row.TransactionType = transactionType
+	return nil
+}
+
+// resolveColumn returns the 0-based index for a header name or 1-based number
+func resolveColumn(spec string, header []string) (int, error) {
+	if number, err := strconv.Atoi(spec); err == nil {
+		if number < 1 {
+			return 0, errors.New("column numbers start at 1")
+		}
+		return number - 1, nil
+	}
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(spec)) {
+			return i, nil
+		}
+	}
+	return 0, errors.New("column not found: " + spec)
+}
+
+func cell(record []string, columns map[string]int, name string) string {
+	index, ok := columns[name]
+	if !ok || index >= len(record) {
+		return ""
+	}
+	// Some bank exports prefix values with ' to keep spreadsheets from reformatting them
+	return strings.TrimPrefix(strings.TrimSpace(record[index]), "'")
+}
+
+func hasMarker(value, marker string) bool {
+	if marker == "" {
+		return false
+	}
+	value = strings.ToUpper(strings.TrimSpace(value))
+	marker = strings.ToUpper(strings.TrimSpace(marker))
+	return value == marker || strings.HasSuffix(value, " "+marker)
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(value money.Amount) money.Amount {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,91 @@
+package importers
+
+import (
+	"my-api/money"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCSVWithTypeMarkerInAmount(t *testing.T) {
+	data := `Tanggal,Keterangan,Jumlah
+'01/10/2026,TRSF E-BANKING CR GAJI,"10,000,000.00 CR"
+'02/10/2026,INDOMARET,"125,500.00 DB"
+'03/10/2026,BROKEN,abc
+`
+	rows, err := ParseCSV(strings.NewReader(data), CSVMapping{
+		HasHeader:         true,
+		DateColumn:        "Tanggal",
+		DateFormat:        "DD/MM/YYYY",
+		DescriptionColumn: "Keterangan",
+		AmountColumn:      "Jumlah",
+		DebitValue:        "DB",
+		CreditValue:       "CR",
+	})
+	if err != nil {
+		t.Fatalf("ParseCSV failed: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+
+	if rows[0].Amount != money.FromInt(10000000) || rows[0].TransactionType != 1 {
+		t.Errorf("expected income of 10000000, got %+v", rows[0])
+	}
+	if !rows[0].Date.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 2026-10-01, got %s", rows[0].Date)
+	}
+	if rows[1].Amount != money.FromInt(125500) || rows[1].TransactionType != 2 {
+		t.Errorf("expected expense of 125500, got %+v", rows[1])
+	}
+	if rows[2].Valid() || rows[2].Line != 4 {
+		t.Errorf("expected line 4 to be rejected, got %+v", rows[2])
+	}
+}
+
+func TestParseCSVWithDebitCreditColumns(t *testing.T) {
+	data := "2026-10-05;Listrik;350.000,00;\n2026-10-06;Refund;;75.000,00\n"
+	rows, err := ParseCSV(strings.NewReader(data), CSVMapping{
+		Delimiter:         ';',
+		DateColumn:        "1",
+		DescriptionColumn: "2",
+		DebitColumn:       "3",
+		CreditColumn:      "4",
+		DecimalSeparator:  ",",
+	})
+	if err != nil {
+		t.Fatalf("ParseCSV failed: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].Amount != money.FromInt(350000) || rows[0].TransactionType != 2 {
+		t.Errorf("expected expense of 350000, got %+v", rows[0])
+	}
+	if rows[1].Amount != money.FromInt(75000) || rows[1].TransactionType != 1 {
+		t.Errorf("expected income of 75000, got %+v", rows[1])
+	}
+}
+
+func TestAssignImportKeysKeepsIdenticalRows(t *testing.T) {
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	rows := []Row{
+		{Date: day, Amount: money.FromInt(20000), TransactionType: 2, Payee: "Kopi Kenangan"},
+		{Date: day, Amount: money.FromInt(20000), TransactionType: 2, Payee: "kopi  kenangan"},
+		{Date: day, Amount: money.FromInt(20000), TransactionType: 2, ExternalID: "ABC123"},
+	}
+	AssignImportKeys(rows)
+
+	if rows[0].ImportKey == "" || rows[0].ImportKey == rows[1].ImportKey {
+		t.Errorf("expected distinct keys for repeated rows, got %q and %q", rows[0].ImportKey, rows[1].ImportKey)
+	}
+	if rows[2].ImportKey != "fitid:ABC123" {
+		t.Errorf("expected FITID key, got %q", rows[2].ImportKey)
+	}
+
+	again := []Row{{Date: day, Amount: money.FromInt(20000), TransactionType: 2, Payee: "KOPI KENANGAN"}}
+	AssignImportKeys(again)
+	if again[0].ImportKey != rows[0].ImportKey {
+		t.Errorf("expected the same key on re-import, got %q and %q", again[0].ImportKey, rows[0].ImportKey)
+	}
+}
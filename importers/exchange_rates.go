@@ -0,0 +1,100 @@
+package importers
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateRow is a single parsed exchange rate
+type RateRow struct {
+	Line          int       `json:"line"`
+	Date          time.Time `json:"date"`
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	Rate          float64   `json:"rate"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// rateColumnNames lists the accepted header names for each rate field
+var rateColumnNames = map[string][]string{
+	"date":  {"date", "rate_date"},
+	"base":  {"base_currency", "base", "from"},
+	"quote": {"quote_currency", "quote", "to"},
+	"rate":  {"rate"},
+}
+
+// ParseExchangeRatesCSV reads rates from a CSV file whose header names the
+// date, base_currency, quote_currency and rate columns in any order. Rows
+// that cannot be parsed are returned with Error set.
+func ParseExchangeRatesCSV(reader io.Reader, dateFormat string) ([]RateRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []RateRow{}, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for field, aliases := range rateColumnNames {
+			for _, alias := range aliases {
+				if name == alias {
+					columns[field] = i
+				}
+			}
+		}
+	}
+	for field := range rateColumnNames {
+		if _, ok := columns[field]; !ok {
+			return nil, errors.New("missing column: " + rateColumnNames[field][0])
+		}
+	}
+
+	rows := make([]RateRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+		row := RateRow{Line: i + 2}
+		if err := parseRateRecord(record, columns, dateFormat, &row); err != nil {
+			row.Error = err.Error()
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseRateRecord(record []string, columns map[string]int, dateFormat string, row *RateRow) error {
+	date, err := ParseDate(cell(record, columns, "date"), dateFormat)
+	if err != nil {
+		return err
+	}
+	row.Date = date
+
+	row.BaseCurrency = strings.ToUpper(cell(record, columns, "base"))
+	row.QuoteCurrency = strings.ToUpper(cell(record, columns, "quote"))
+	if row.BaseCurrency == "" || row.QuoteCurrency == "" {
+		return errors.New("missing currency")
+	}
+	if row.BaseCurrency == row.QuoteCurrency {
+		return errors.New("base and quote currency must differ")
+	}
+
+	value := strings.ReplaceAll(cell(record, columns, "rate"), ",", "")
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate <= 0 {
+		return errors.New("invalid rate: " + value)
+	}
+	row.Rate = rate
+	return nil
+}
@@ -0,0 +1,42 @@
+package importers
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExchangeRatesCSV(t *testing.T) {
+	data := `Rate,From,To,Date
+"15,750.50",usd,IDR,2026-10-01
+0.0000635,IDR,USD,2026-10-01
+abc,USD,IDR,2026-10-02
+1,USD,USD,2026-10-02
+`
+	rows, err := ParseExchangeRatesCSV(strings.NewReader(data), "")
+	if err != nil {
+		t.Fatalf("ParseExchangeRatesCSV failed: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(rows))
+	}
+
+	if rows[0].Rate != 15750.5 || rows[0].BaseCurrency != "USD" || rows[0].QuoteCurrency != "IDR" {
+		t.Errorf("unexpected first row: %+v", rows[0])
+	}
+	if !rows[0].Date.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 2026-10-01, got %s", rows[0].Date)
+	}
+	if rows[1].Error != "" {
+		t.Errorf("expected second row to parse, got %q", rows[1].Error)
+	}
+	if rows[2].Error == "" || rows[3].Error == "" {
+		t.Errorf("expected invalid rows to be rejected, got %+v and %+v", rows[2], rows[3])
+	}
+}
+
+func TestParseExchangeRatesCSVRequiresColumns(t *testing.T) {
+	if _, err := ParseExchangeRatesCSV(strings.NewReader("date,rate\n2026-10-01,1\n"), ""); err == nil {
+		t.Error("expected an error for missing currency columns")
+	}
+}
@@ -0,0 +1,145 @@
+// Package importers parses bank statement exports into rows that can be
+// posted as v2 transactions.
+package importers
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"my-api/money"
+	"strings"
+	"time"
+)
+
+// Row is a single parsed statement entry
+type Row struct {
+	Line            int          `json:"line"`
+	Date            time.Time    `json:"date"`
+	Description     string       `json:"description"`
+	Payee           string       `json:"payee,omitempty"`
+	Amount          money.Amount `json:"amount"`
+	TransactionType int          `json:"transaction_type"`      // 1=income, 2=expense
+	ExternalID      string       `json:"external_id,omitempty"` // FITID for OFX
+	ImportKey       string       `json:"import_key,omitempty"`
+	Error           string       `json:"error,omitempty"`
+}
+
+// Valid reports whether the row was parsed without errors
+func (r *Row) Valid() bool {
+	return r.Error == ""
+}
+
+// AssignImportKeys sets the key used to recognize a row when an overlapping
+// statement is imported again. Rows with a bank transaction ID use it as is;
+// the others are keyed by date, amount, direction and payee, plus how many
+// identical rows came before it in the file so two equal purchases on the
+// same day are both kept.
+func AssignImportKeys(rows []Row) {
+	seen := map[string]int{}
+	for i := range rows {
+		row := &rows[i]
+		if !row.Valid() {
+			continue
+		}
+		if row.ExternalID != "" && len(row.ExternalID) <= 200 {
+			row.ImportKey = "fitid:" + row.ExternalID
+			continue
+		}
+		if row.ExternalID != "" {
+			sum := sha1.Sum([]byte(row.ExternalID))
+			row.ImportKey = "fitid:" + hex.EncodeToString(sum[:])
+			continue
+		}
+
+		payee := row.Payee
+		if payee == "" {
+			payee = row.Description
+		}
+		tuple := fmt.Sprintf("%s|%s|%d|%s", row.Date.Format("2006-01-02"), row.Amount, row.TransactionType, strings.ToLower(strings.Join(strings.Fields(payee), " ")))
+		occurrence := seen[tuple]
+		seen[tuple]++
+
+		sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d", tuple, occurrence)))
+		row.ImportKey = "hash:" + hex.EncodeToString(sum[:])
+	}
+}
+
+// defaultDateLayouts are tried in order when no date format is configured
+var defaultDateLayouts = []string{
+	"2006-01-02",
+	"02/01/2006",
+	"02-01-2006",
+	"2006/01/02",
+	"02/01/06",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+}
+
+// ParseDate parses a statement date using the given format, or the default
+// layouts when format is empty. The format accepts either a Go layout or
+// tokens such as DD/MM/YYYY.
+func ParseDate(value, format string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if format != "" {
+		return time.Parse(convertDateFormat(format), value)
+	}
+	for _, layout := range defaultDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, errors.New("unrecognized date: " + value)
+}
+
+// convertDateFormat turns DD/MM/YYYY style tokens into a Go layout
+func convertDateFormat(format string) string {
+	replacer := strings.NewReplacer(
+		"YYYY", "2006",
+		"YY", "06",
+		"MM", "01",
+		"DD", "02",
+		"HH", "15",
+		"mm", "04",
+		"ss", "05",
+	)
+	return replacer.Replace(format)
+}
+
+// ParseAmount parses a statement amount such as "1,250,000.00", "-50.000,00"
+// or "(75.00)" and returns it exactly, together with its sign.
+// decimalSeparator is "." (default) or ",".
+func ParseAmount(value, decimalSeparator string) (money.Amount, error) {
+	value = strings.TrimSpace(value)
+	negative := false
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+		value = value[1 : len(value)-1]
+	}
+
+	var cleaned strings.Builder
+	for _, ch := range value {
+		switch {
+		case ch >= '0' && ch <= '9':
+			cleaned.WriteRune(ch)
+		case ch == '-':
+			negative = !negative
+		case string(ch) == decimalSeparator || (decimalSeparator == "" && ch == '.'):
+			cleaned.WriteRune('.')
+		}
+	}
+
+	if cleaned.Len() == 0 {
+		return 0, errors.New("missing amount")
+	}
+
+	amount, err := money.Parse(cleaned.String())
+	if err != nil {
+		return 0, errors.New("invalid amount: " + value)
+	}
+
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
@@ -0,0 +1,114 @@
+package importers
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ofxTagPattern = regexp.MustCompile(`<(/?)([A-Za-z0-9.]+)>([^<]*)`)
+
+// ParseOFX reads the STMTTRN entries of an OFX statement. Both the SGML
+// (OFX 1.x, unclosed leaf tags) and XML (OFX 2.x) variants are accepted.
+func ParseOFX(reader io.Reader) ([]Row, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	content := string(data)
+	if !strings.Contains(strings.ToUpper(content), "<OFX>") {
+		return nil, errors.New("not an OFX file")
+	}
+
+	var rows []Row
+	var fields map[string]string
+	flush := func() {
+		if fields == nil {
+			return
+		}
+		rows = append(rows, ofxRow(len(rows)+1, fields))
+		fields = nil
+	}
+
+	for _, match := range ofxTagPattern.FindAllStringSubmatch(content, -1) {
+		closing := match[1] == "/"
+		tag := strings.ToUpper(match[2])
+		value := strings.TrimSpace(match[3])
+
+		switch {
+		case tag == "STMTTRN" && !closing:
+			flush()
+			fields = map[string]string{}
+		case tag == "STMTTRN" || tag == "BANKTRANLIST":
+			flush()
+		case fields != nil && !closing && value != "":
+			fields[tag] = value
+		}
+	}
+	flush()
+
+	return rows, nil
+}
+
+func ofxRow(line int, fields map[string]string) Row {
+	row := Row{
+		Line:       line,
+		Payee:      fields["NAME"],
+		ExternalID: fields["FITID"],
+	}
+	if row.Payee == "" {
+		row.Payee = fields["PAYEE"]
+	}
+
+	row.Description = row.Payee
+	if memo := fields["MEMO"]; memo != "" && memo != row.Payee {
+		if row.Description != "" {
+			row.Description += " - " + memo
+		} else {
+			row.Description = memo
+		}
+	}
+
+	date, err := parseOFXDate(fields["DTPOSTED"])
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	row.Date = date
+
+	amount, err := ParseAmount(fields["TRNAMT"], ofxDecimalSeparator(fields["TRNAMT"]))
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	if amount == 0 {
+		row.Error = "amount must not be zero"
+		return row
+	}
+
+	row.Amount = abs(amount)
+	row.TransactionType = 1
+	if amount < 0 {
+		row.TransactionType = 2
+	}
+	return row
+}
+
+// parseOFXDate parses YYYYMMDD[HHMMSS[.XXX]][[offset:TZ]] and keeps the date part
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, errors.New("invalid date: " + value)
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+// ofxDecimalSeparator detects amounts such as "-12,50" that some banks emit
+func ofxDecimalSeparator(value string) string {
+	if strings.Contains(value, ",") && !strings.Contains(value, ".") {
+		return ","
+	}
+	return "."
+}
@@ -0,0 +1,59 @@
+package importers
+
+import (
+	"my-api/money"
+	"strings"
+	"testing"
+)
+
+func TestParseOFXSGML(t *testing.T) {
+	data := `OFXHEADER:100
+DATA:OFXSGML
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<BANKTRANLIST>
+<DTSTART>20261001
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20261003120000[+7:WIB]
+<TRNAMT>-45000.00
+<FITID>2026100301
+<NAME>GRAB FOOD
+<MEMO>Lunch
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20261005
+<TRNAMT>2500000
+<FITID>2026100502
+<NAME>SALARY
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`
+
+	rows, err := ParseOFX(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseOFX failed: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+
+	if rows[0].Amount != money.FromInt(45000) || rows[0].TransactionType != 2 || rows[0].ExternalID != "2026100301" {
+		t.Errorf("unexpected first row: %+v", rows[0])
+	}
+	if rows[0].Description != "GRAB FOOD - Lunch" || rows[0].Date.Format("2006-01-02") != "2026-10-03" {
+		t.Errorf("unexpected first row: %+v", rows[0])
+	}
+	if rows[1].Amount != money.FromInt(2500000) || rows[1].TransactionType != 1 {
+		t.Errorf("unexpected second row: %+v", rows[1])
+	}
+}
+
+func TestParseOFXRejectsOtherFiles(t *testing.T) {
+	if _, err := ParseOFX(strings.NewReader("date,amount\n")); err == nil {
+		t.Error("expected an error for a non-OFX file")
+	}
+}
@@ -0,0 +1,96 @@
+package importers
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"my-api/money"
+	"strings"
+	"time"
+)
+
+// PriceRow is a single parsed instrument price
+type PriceRow struct {
+	Line   int          `json:"line"`
+	Date   time.Time    `json:"date"`
+	Symbol string       `json:"symbol"`
+	Price  money.Amount `json:"price"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// priceColumnNames lists the accepted header names for each price field
+var priceColumnNames = map[string][]string{
+	"date":   {"date", "price_date"},
+	"symbol": {"symbol", "ticker", "code"},
+	"price":  {"price", "close", "nav"},
+}
+
+// ParsePricesCSV reads prices from a CSV file whose header names the date,
+// symbol and price columns in any order. Rows that cannot be parsed are
+// returned with Error set.
+func ParsePricesCSV(reader io.Reader, dateFormat string) ([]PriceRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []PriceRow{}, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for field, aliases := range priceColumnNames {
+			for _, alias := range aliases {
+				if name == alias {
+					columns[field] = i
+				}
+			}
+		}
+	}
+	for field := range priceColumnNames {
+		if _, ok := columns[field]; !ok {
+			return nil, errors.New("missing column: " + priceColumnNames[field][0])
+		}
+	}
+
+	rows := make([]PriceRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+		row := PriceRow{Line: i + 2}
+		if err := parsePriceRecord(record, columns, dateFormat, &row); err != nil {
+			row.Error = err.Error()
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parsePriceRecord(record []string, columns map[string]int, dateFormat string, row *PriceRow) error {
+	date, err := ParseDate(cell(record, columns, "date"), dateFormat)
+	if err != nil {
+		return err
+	}
+	row.Date = date
+
+	row.Symbol = strings.ToUpper(cell(record, columns, "symbol"))
+	if row.Symbol == "" {
+		return errors.New("missing symbol")
+	}
+
+	price, err := ParseAmount(cell(record, columns, "price"), "")
+	if err != nil {
+		return err
+	}
+	if price <= 0 {
+		return errors.New("price must be positive")
+	}
+	row.Price = price
+	return nil
+}
@@ -0,0 +1,42 @@
+package importers
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePricesCSV(t *testing.T) {
+	data := `Ticker,Close,Date
+bbca,"9,875.50",2026-10-01
+GOLD,1450000,2026-10-01
+BBRI,abc,2026-10-02
+,100,2026-10-02
+`
+	rows, err := ParsePricesCSV(strings.NewReader(data), "")
+	if err != nil {
+		t.Fatalf("ParsePricesCSV failed: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(rows))
+	}
+
+	if rows[0].Symbol != "BBCA" || rows[0].Price.String() != "9875.5" {
+		t.Errorf("unexpected first row: %+v", rows[0])
+	}
+	if !rows[0].Date.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 2026-10-01, got %s", rows[0].Date)
+	}
+	if rows[1].Error != "" {
+		t.Errorf("expected second row to parse, got %q", rows[1].Error)
+	}
+	if rows[2].Error == "" || rows[3].Error == "" {
+		t.Errorf("expected invalid rows to be rejected, got %+v and %+v", rows[2], rows[3])
+	}
+}
+
+func TestParsePricesCSVRequiresColumns(t *testing.T) {
+	if _, err := ParsePricesCSV(strings.NewReader("date,price\n2026-10-01,1\n"), ""); err == nil {
+		t.Error("expected an error for a missing symbol column")
+	}
+}
@@ -0,0 +1,132 @@
+package importers
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// qifDateLayouts are tried when no date format is configured. QIF files from
+// US desktop tools use month-first dates, optionally with a ' before the year.
+var qifDateLayouts = []string{
+	"01/02/2006",
+	"1/2/2006",
+	"01/02/06",
+	"1/2/06",
+	"2006-01-02",
+	"02.01.2006",
+}
+
+// ParseQIF reads the entries of a QIF bank or cash account export. dateFormat
+// may be empty to use the common layouts.
+func ParseQIF(reader io.Reader, dateFormat string) ([]Row, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var rows []Row
+	fields := map[string]string{}
+	lineNumber := 0
+	startLine := 0
+	sawHeader := false
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "!") {
+			sawHeader = true
+			continue
+		}
+
+		if line[0] == '^' {
+			if len(fields) > 0 {
+				rows = append(rows, qifRow(startLine, fields, dateFormat))
+			}
+			fields = map[string]string{}
+			continue
+		}
+
+		if len(fields) == 0 {
+			startLine = lineNumber
+		}
+		code := strings.ToUpper(line[:1])
+		// Split lines (S, E, $) belong to the same entry and are not imported separately
+		if _, exists := fields[code]; !exists {
+			fields[code] = strings.TrimSpace(line[1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		rows = append(rows, qifRow(startLine, fields, dateFormat))
+	}
+
+	if !sawHeader && len(rows) == 0 {
+		return nil, errors.New("not a QIF file")
+	}
+	return rows, nil
+}
+
+func qifRow(line int, fields map[string]string, dateFormat string) Row {
+	row := Row{
+		Line:  line,
+		Payee: fields["P"],
+	}
+
+	row.Description = row.Payee
+	if memo := fields["M"]; memo != "" && memo != row.Payee {
+		if row.Description != "" {
+			row.Description += " - " + memo
+		} else {
+			row.Description = memo
+		}
+	}
+
+	date, err := parseQIFDate(fields["D"], dateFormat)
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	row.Date = date
+
+	value := fields["T"]
+	if value == "" {
+		value = fields["U"]
+	}
+	amount, err := ParseAmount(value, ".")
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	if amount == 0 {
+		row.Error = "amount must not be zero"
+		return row
+	}
+
+	row.Amount = abs(amount)
+	row.TransactionType = 1
+	if amount < 0 {
+		row.TransactionType = 2
+	}
+	return row
+}
+
+func parseQIFDate(value, dateFormat string) (time.Time, error) {
+	value = strings.TrimSpace(strings.ReplaceAll(value, "'", "/"))
+	value = strings.ReplaceAll(value, " ", "")
+	if dateFormat != "" {
+		return ParseDate(value, dateFormat)
+	}
+	for _, layout := range qifDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, errors.New("unrecognized date: " + value)
+}
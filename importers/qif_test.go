@@ -0,0 +1,45 @@
+package importers
+
+import (
+	"my-api/money"
+	"strings"
+	"testing"
+)
+
+func TestParseQIF(t *testing.T) {
+	data := `!Type:Bank
+D10/03'26
+T-45,000.00
+PGrab Food
+MLunch
+^
+D10/05/2026
+T2,500,000.00
+PSalary
+^
+D13/45/2026
+T-10.00
+PBroken
+^
+`
+	rows, err := ParseQIF(strings.NewReader(data), "")
+	if err != nil {
+		t.Fatalf("ParseQIF failed: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+
+	if rows[0].Amount != money.FromInt(45000) || rows[0].TransactionType != 2 || rows[0].Date.Format("2006-01-02") != "2026-10-03" {
+		t.Errorf("unexpected first row: %+v", rows[0])
+	}
+	if rows[0].Payee != "Grab Food" || rows[0].Description != "Grab Food - Lunch" {
+		t.Errorf("unexpected first row: %+v", rows[0])
+	}
+	if rows[1].Amount != money.FromInt(2500000) || rows[1].TransactionType != 1 {
+		t.Errorf("unexpected second row: %+v", rows[1])
+	}
+	if rows[2].Valid() || rows[2].Line != 11 {
+		t.Errorf("expected entry at line 11 to be rejected, got %+v", rows[2])
+	}
+}
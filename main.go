@@ -7,7 +7,9 @@ import (
 	"my-api/config"
 	// "my-api/models"
 	"my-api/routes"
+	"my-api/storage"
 	"my-api/utils"
+	"my-api/workers"
 
 	"github.com/gin-gonic/gin"
 )
@@ -68,6 +70,16 @@ func main() {
 	routes.SetupRouter(r)
 	utils.LogInfo("Routes configured successfully")
 
+	workers.StartRecurringTransactionWorker(config.DB, time.Hour)
+	workers.StartBalanceSnapshotWorker(config.DB, time.Hour)
+
+	attachmentStorage, err := storage.NewLocalStorage(storage.AttachmentDir())
+	if err != nil {
+		utils.LogErrorf("Failed to initialize attachment storage: %v", err)
+		log.Fatal("Failed to initialize attachment storage:", err)
+	}
+	workers.StartTrashPurgeWorker(config.DB, attachmentStorage, 24*time.Hour, config.TrashRetention())
+
 	utils.LogInfo("Server starting on port 8080...")
 	if err := r.Run(":8080"); err != nil {
 		utils.LogErrorf("Failed to start server: %v", err)
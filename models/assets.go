@@ -1,17 +1,86 @@
 package models
 
-import "time"
+import (
+    "my-api/money"
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Asset types. Credit cards and loans are liabilities: their balance goes
+// negative as debt builds up.
+const (
+    AssetTypeCash       = "cash"
+    AssetTypeBank       = "bank"
+    AssetTypeEWallet    = "e_wallet"
+    AssetTypeCreditCard = "credit_card"
+    AssetTypeLoan       = "loan"
+    AssetTypeInvestment = "investment"
+)
+
+// AssetTypes lists every valid asset type
+var AssetTypes = []string{AssetTypeCash, AssetTypeBank, AssetTypeEWallet, AssetTypeCreditCard, AssetTypeLoan, AssetTypeInvestment}
 
 // Asset represents a wallet/asset belonging to a user.
 type Asset struct {
-    ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
-    UserID    uint64    `gorm:"not null;index" json:"user_id"`
-    Name      string    `gorm:"size:255;not null" json:"name"`
-    Type      string    `gorm:"size:100" json:"type"`
-    Balance   float64   `gorm:"type:decimal(20,8);not null;default:0" json:"balance"`
-    Currency  string    `gorm:"size:10;not null" json:"currency"`
-    BankName  string    `gorm:"size:255" json:"bank_name"`
-    AccountNo string    `gorm:"size:100" json:"account_no"`
-    CreatedAt time.Time `json:"created_at"`
-    UpdatedAt time.Time `json:"updated_at"`
+    ID             uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+    UserID         uint64       `gorm:"not null;index" json:"user_id"`
+    Name           string       `gorm:"size:255;not null" json:"name"`
+    Type           string       `gorm:"size:100" json:"type"`
+    Balance        money.Amount `gorm:"type:decimal(19,4);not null;default:0" json:"balance"`
+    ClearedBalance money.Amount `gorm:"-" json:"cleared_balance"` // Balance without pending transactions, filled in by the service
+    Currency       string       `gorm:"size:10;not null" json:"currency"`
+    BankName       string       `gorm:"size:255" json:"bank_name"`
+    AccountNo      string       `gorm:"size:100" json:"account_no"`
+
+    // Credit limit or overdraft: how far below zero the balance may go
+    CreditLimit money.Amount `gorm:"type:decimal(19,4);not null;default:0" json:"credit_limit"`
+    // Credit card statements, 0 when not set
+    StatementClosingDay int          `gorm:"not null;default:0" json:"statement_closing_day"`
+    PaymentDueDay       int          `gorm:"not null;default:0" json:"payment_due_day"`
+    MinPaymentPercent   float64      `gorm:"type:decimal(5,2);not null;default:0" json:"min_payment_percent"`
+    MinPaymentAmount    money.Amount `gorm:"type:decimal(19,4);not null;default:0" json:"min_payment_amount"`
+
+    CreatedAt time.Time      `json:"created_at"`
+    UpdatedAt time.Time      `json:"updated_at"`
+    DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+    // Last time the asset was taken out of the trash
+    RestoredAt *time.Time `json:"restored_at,omitempty"`
+}
+
+// BalanceMoney returns the balance together with the asset's currency
+func (a Asset) BalanceMoney() money.Money {
+    return money.New(a.Balance, a.Currency)
+}
+
+// IsLiability reports whether the asset tracks money owed
+func (a Asset) IsLiability() bool {
+    return a.Type == AssetTypeCreditCard || a.Type == AssetTypeLoan
+}
+
+// CanSpend reports whether amount can be taken out without going past the
+// asset's floor: zero, or minus the credit limit. Loans have no floor since
+// interest and fees keep adding to the debt.
+func (a Asset) CanSpend(amount money.Amount) bool {
+    if a.Type == AssetTypeLoan {
+        return true
+    }
+    return a.Balance-amount >= -a.CreditLimit
+}
+
+// MinimumPayment returns the minimum payment due on a statement balance:
+// MinPaymentPercent of it, but at least MinPaymentAmount and never more than
+// the balance itself
+func (a Asset) MinimumPayment(statementBalance money.Amount) money.Amount {
+    if statementBalance <= 0 {
+        return 0
+    }
+    minimum := statementBalance.MulRate(a.MinPaymentPercent / 100)
+    if minimum < a.MinPaymentAmount {
+        minimum = a.MinPaymentAmount
+    }
+    if minimum > statementBalance {
+        minimum = statementBalance
+    }
+    return minimum
 }
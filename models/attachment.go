@@ -0,0 +1,18 @@
+package models
+
+import (
+	"my-api/utils"
+)
+
+// Attachment is a receipt file attached to a transaction. The file itself
+// lives in the configured storage under StorageKey.
+type Attachment struct {
+	ID            uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	TransactionID uint             `gorm:"not null;index;type:int unsigned" json:"transaction_id"`
+	UserID        uint             `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	FileName      string           `gorm:"size:255;not null" json:"file_name"`
+	ContentType   string           `gorm:"size:100;not null" json:"content_type"`
+	Size          int64            `gorm:"not null" json:"size"`
+	StorageKey    string           `gorm:"size:255;not null" json:"-"`
+	CreatedAt     utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+}
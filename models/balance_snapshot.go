@@ -0,0 +1,26 @@
+package models
+
+import (
+	"my-api/money"
+	"my-api/utils"
+)
+
+// BalanceSnapshot is an asset's balance at the end of SnapshotDate, in the
+// asset's currency. HoldingsValue is the market value of investment holdings
+// on that day, so Balance + HoldingsValue is what the asset was worth.
+type BalanceSnapshot struct {
+	ID            uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID        uint             `gorm:"not null;index:idx_balance_snapshots_user_date;type:int unsigned" json:"user_id"`
+	AssetID       uint64           `gorm:"not null;uniqueIndex:idx_balance_snapshots_asset_date;type:bigint unsigned" json:"asset_id"`
+	SnapshotDate  utils.CustomTime `gorm:"not null;type:date;uniqueIndex:idx_balance_snapshots_asset_date;index:idx_balance_snapshots_user_date" json:"snapshot_date"`
+	Balance       money.Amount     `gorm:"type:decimal(19,4);not null" json:"balance"`
+	HoldingsValue money.Amount     `gorm:"type:decimal(19,4);not null;default:0" json:"holdings_value"`
+	Currency      string           `gorm:"size:10;not null" json:"currency"`
+	CreatedAt     utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt     utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+}
+
+// Worth is what the asset was worth on the snapshot date
+func (s BalanceSnapshot) Worth() money.Amount {
+	return s.Balance + s.HoldingsValue
+}
@@ -1,34 +1,153 @@
 package models
 
 import (
+	"cmp"
+	"my-api/money"
 	"my-api/utils"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 )
 
+const (
+	BudgetAlertThreshold = "threshold" // spending crossed one of the budget's thresholds
+	BudgetAlertProjected = "projected" // spending is on pace to exceed the budget
+)
+
+// Budget caps spending across its categories, on every wallet or only on
+// the wallets in Assets. CategoryID is the primary category. A recurring
+// budget renews every period from StartDate and EndDate only closes its
+// first window; a one-off budget ends at EndDate.
 type Budget struct {
-	ID          uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
-	UserID      uint             `gorm:"not null;index;type:int unsigned" json:"user_id"`
-	CategoryID  uint             `gorm:"not null;index;type:int unsigned" json:"category_id"`
-	Amount      int              `gorm:"not null" json:"amount"`
-	Period      string           `gorm:"size:20;not null" json:"period"` // monthly, yearly
-	StartDate   utils.CustomTime `gorm:"not null;type:datetime" json:"start_date"`
-	EndDate     utils.CustomTime `gorm:"not null;type:datetime" json:"end_date"`
-	IsActive    bool             `gorm:"default:true" json:"is_active"`
-	AlertAt     int              `gorm:"default:80" json:"alert_at"` // Alert at 80% of budget
-	Description string           `gorm:"size:500" json:"description"`
-	CreatedAt   utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
-	UpdatedAt   utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+	ID              uint              `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID          uint              `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	CategoryID      uint              `gorm:"not null;index;type:int unsigned" json:"category_id"`
+	Name            string            `gorm:"size:100" json:"name"`
+	Amount          money.Amount      `gorm:"type:decimal(19,4);not null" json:"amount"`
+	Period          string            `gorm:"size:20;not null" json:"period"` // weekly, monthly, yearly, pay_cycle
+	Recurring       bool              `gorm:"not null;default:false" json:"recurring"`
+	StartDate       utils.CustomTime  `gorm:"not null;type:datetime" json:"start_date"`
+	EndDate         utils.CustomTime  `gorm:"not null;type:datetime" json:"end_date"`
+	IsActive        bool              `gorm:"default:true" json:"is_active"`
+	AlertAt         int               `gorm:"default:80" json:"alert_at"`                           // Alert at 80% of budget
+	AlertThresholds string            `gorm:"size:100;not null;default:''" json:"alert_thresholds"` // comma separated percentages, e.g. 50,80,100,120
+	ProjectedAlert  bool              `gorm:"not null;default:false" json:"projected_alert"`        // alert when the spending pace would exceed the budget
+	Rollover        bool              `gorm:"not null;default:false" json:"rollover"`               // carry the previous period's remainder forward
+	Description     string            `gorm:"size:500" json:"description"`
+	PeriodsSyncedAt *utils.CustomTime `gorm:"type:datetime" json:"-"` // when the stored periods were last brought up to date
+	CreatedAt       utils.CustomTime  `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt       utils.CustomTime  `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
 
 	// Relations
-	User     User     `gorm:"foreignKey:UserID" json:"-"`
-	Category Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	User       User       `gorm:"foreignKey:UserID" json:"-"`
+	Category   Category   `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Categories []Category `gorm:"many2many:budget_categories;joinForeignKey:BudgetID;joinReferences:CategoryID" json:"categories,omitempty"`
+	Assets     []Asset    `gorm:"many2many:budget_assets;joinForeignKey:BudgetID;joinReferences:AssetID" json:"assets,omitempty"`
+}
+
+// CategoryIDs returns the categories the budget covers
+func (b *Budget) CategoryIDs() []uint {
+	if len(b.Categories) == 0 {
+		return []uint{b.CategoryID}
+	}
+	ids := make([]uint, len(b.Categories))
+	for i, category := range b.Categories {
+		ids[i] = category.ID
+	}
+	return ids
+}
+
+// Thresholds returns the percentages of the available amount that trigger an
+// alert, lowest first. Budgets without a list alert at AlertAt and at 100%.
+func (b *Budget) Thresholds() []int {
+	var thresholds []int
+	for _, part := range strings.Split(b.AlertThresholds, ",") {
+		if threshold, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && threshold > 0 {
+			thresholds = append(thresholds, threshold)
+		}
+	}
+	if len(thresholds) == 0 {
+		thresholds = []int{b.AlertAt, 100}
+	}
+	sort.Ints(thresholds)
+	return thresholds
+}
+
+// SetThresholds stores the alert thresholds. An empty list goes back to
+// AlertAt and 100%.
+func (b *Budget) SetThresholds(thresholds []int) {
+	sorted := append([]int(nil), thresholds...)
+	sort.Ints(sorted)
+	parts := make([]string, 0, len(sorted))
+	for i, threshold := range sorted {
+		if i > 0 && threshold == sorted[i-1] {
+			continue
+		}
+		parts = append(parts, strconv.Itoa(threshold))
+	}
+	b.AlertThresholds = strings.Join(parts, ",")
+}
+
+// AssetIDs returns the wallets the budget is limited to, or nil when it
+// covers every wallet
+func (b *Budget) AssetIDs() []uint64 {
+	if len(b.Assets) == 0 {
+		return nil
+	}
+	ids := make([]uint64, len(b.Assets))
+	for i, asset := range b.Assets {
+		ids[i] = asset.ID
+	}
+	return ids
+}
+
+// SameScope reports whether the budgets cover the same categories and the
+// same wallets, in any order
+func (b *Budget) SameScope(other *Budget) bool {
+	return sameIDs(b.CategoryIDs(), other.CategoryIDs()) && sameIDs(b.AssetIDs(), other.AssetIDs())
+}
+
+func sameIDs[T cmp.Ordered](a, b []T) bool {
+	return slices.Equal(slices.Sorted(slices.Values(a)), slices.Sorted(slices.Values(b)))
+}
+
+// BudgetPeriod stores one window of a budget: the planned amount, what was
+// carried over from the previous period, what was spent and what is left.
+// Closed periods are reused until a transaction change reaches them, and the
+// figures are refreshed when alerts are checked or the budget is saved.
+// AlertedThreshold and ProjectedAlerted record which alerts the period
+// already fired.
+type BudgetPeriod struct {
+	ID               uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	BudgetID         uint             `gorm:"not null;uniqueIndex:idx_budget_periods_budget_start;type:int unsigned" json:"budget_id"`
+	UserID           uint             `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	StartDate        utils.CustomTime `gorm:"not null;uniqueIndex:idx_budget_periods_budget_start;type:datetime" json:"start_date"`
+	EndDate          utils.CustomTime `gorm:"not null;type:datetime" json:"end_date"`
+	Amount           money.Amount     `gorm:"type:decimal(19,4);not null" json:"amount"`
+	CarryIn          money.Amount     `gorm:"type:decimal(19,4);not null;default:0" json:"carry_in"`
+	Spent            money.Amount     `gorm:"type:decimal(19,4);not null;default:0" json:"spent"`
+	Remaining        money.Amount     `gorm:"type:decimal(19,4);not null;default:0" json:"remaining"`
+	AlertedThreshold int              `gorm:"not null;default:0" json:"alerted_threshold"`
+	ProjectedAlerted bool             `gorm:"not null;default:false" json:"projected_alerted"`
+	CreatedAt        utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt        utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+}
+
+// Available is what the period can spend: its amount plus the carry-over,
+// which is negative after an overspent period
+func (p BudgetPeriod) Available() money.Amount {
+	return p.Amount + p.CarryIn
 }
 
 type BudgetAlert struct {
 	ID          uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
 	BudgetID    uint             `gorm:"not null;index;type:int unsigned" json:"budget_id"`
 	UserID      uint             `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	Kind        string           `gorm:"size:20;not null;default:'threshold'" json:"kind"` // threshold, projected
+	Threshold   int              `gorm:"not null;default:0" json:"threshold"`
 	Percentage  int              `gorm:"not null" json:"percentage"`
-	SpentAmount int              `gorm:"not null" json:"spent_amount"`
+	SpentAmount money.Amount     `gorm:"type:decimal(19,4);not null" json:"spent_amount"`
 	Message     string           `gorm:"size:500" json:"message"`
 	IsRead      bool             `gorm:"default:false" json:"is_read"`
 	CreatedAt   utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
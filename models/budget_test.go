@@ -0,0 +1,65 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBudgetThresholds(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored string
+		want   []int
+	}{
+		{name: "defaults to the alert level and 100", stored: "", want: []int{80, 100}},
+		{name: "sorted", stored: "50,80,100,120", want: []int{50, 80, 100, 120}},
+		{name: "unsorted with spaces", stored: "120, 50,80", want: []int{50, 80, 120}},
+		{name: "invalid entries are skipped", stored: "abc,0,-5,90", want: []int{90}},
+		{name: "only invalid entries fall back to the default", stored: "abc,0", want: []int{80, 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := Budget{AlertAt: 80, AlertThresholds: tt.stored}
+			if got := budget.Thresholds(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBudgetSetThresholds(t *testing.T) {
+	tests := []struct {
+		name       string
+		thresholds []int
+		wantStored string
+		want       []int
+	}{
+		{name: "sorted", thresholds: []int{50, 80, 100}, wantStored: "50,80,100", want: []int{50, 80, 100}},
+		{name: "unsorted", thresholds: []int{150, 50, 100}, wantStored: "50,100,150", want: []int{50, 100, 150}},
+		{name: "duplicates", thresholds: []int{100, 50, 80, 50, 100}, wantStored: "50,80,100", want: []int{50, 80, 100}},
+		{name: "empty goes back to the default", thresholds: nil, wantStored: "", want: []int{90, 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := Budget{AlertAt: 90}
+			budget.SetThresholds(tt.thresholds)
+			if budget.AlertThresholds != tt.wantStored {
+				t.Errorf("expected stored %q, got %q", tt.wantStored, budget.AlertThresholds)
+			}
+			if got := budget.Thresholds(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSetThresholdsKeepsInputUntouched(t *testing.T) {
+	input := []int{100, 50}
+	var budget Budget
+	budget.SetThresholds(input)
+	if input[0] != 100 || input[1] != 50 {
+		t.Errorf("input was reordered: %v", input)
+	}
+}
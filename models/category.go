@@ -2,13 +2,16 @@ package models
 
 import (
     "time"
+
+    "gorm.io/gorm"
 )
 
 type Category struct {
-    ID           uint      `gorm:"primaryKey;autoIncrement;type:int unsigned"`
-    CategoryName string    `gorm:"size:200;not null"`
-    Description  string    `gorm:"size:200;not null"`
-    UserID       uint      `gorm:"not null;type:int unsigned"`
-    CreatedAt    time.Time `gorm:"autoCreateTime"`
-    UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+    ID           uint           `gorm:"primaryKey;autoIncrement;type:int unsigned"`
+    CategoryName string         `gorm:"size:200;not null"`
+    Description  string         `gorm:"size:200;not null"`
+    UserID       uint           `gorm:"not null;type:int unsigned"`
+    CreatedAt    time.Time      `gorm:"autoCreateTime"`
+    UpdatedAt    time.Time      `gorm:"autoUpdateTime"`
+    DeletedAt    gorm.DeletedAt `gorm:"index"`
 }
\ No newline at end of file
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"my-api/money"
+	"my-api/utils"
+)
+
+// CategoryRule assigns a category, payee and tags to transactions that meet
+// all of its conditions. Empty conditions are ignored; the amount range is
+// min_amount <= amount < max_amount.
+type CategoryRule struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID   uint   `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	Name     string `gorm:"size:100;not null" json:"name"`
+	Priority int    `gorm:"not null;default:100" json:"priority"` // lower runs first
+	IsActive bool   `gorm:"default:true" json:"is_active"`
+
+	// Conditions
+	MatchType       utils.MatchType `gorm:"size:20" json:"match_type,omitempty"` // exact, prefix, contains, regex
+	Pattern         string          `gorm:"size:255" json:"pattern,omitempty"`
+	MinAmount       *money.Amount   `gorm:"type:decimal(19,4)" json:"min_amount,omitempty"`
+	MaxAmount       *money.Amount   `gorm:"type:decimal(19,4)" json:"max_amount,omitempty"`
+	TransactionType *int            `json:"transaction_type,omitempty"` // 1=income, 2=expense
+	AssetID         *uint64         `gorm:"type:int unsigned" json:"asset_id,omitempty"`
+
+	// Actions
+	CategoryID *uint `gorm:"type:int unsigned" json:"category_id,omitempty"`
+	PayeeID    *uint `gorm:"type:int unsigned" json:"payee_id,omitempty"`
+
+	CreatedAt utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+
+	// Relations
+	Category *Category `gorm:"foreignKey:CategoryID" json:"-"`
+	Payee    *Payee    `gorm:"foreignKey:PayeeID" json:"-"`
+	Tags     []Tag     `gorm:"many2many:category_rule_tags;joinForeignKey:RuleID;joinReferences:TagID" json:"tags,omitempty"`
+}
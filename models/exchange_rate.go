@@ -0,0 +1,22 @@
+package models
+
+import (
+	"my-api/utils"
+)
+
+// DefaultBaseCurrency is used for users who have not picked a base currency
+const DefaultBaseCurrency = "IDR"
+
+// ExchangeRate records that one unit of BaseCurrency was worth Rate units of
+// QuoteCurrency from RateDate on, until a newer rate for the pair exists
+type ExchangeRate struct {
+	ID            uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID        uint             `gorm:"not null;uniqueIndex:idx_exchange_rates_pair_date;type:int unsigned" json:"user_id"`
+	BaseCurrency  string           `gorm:"size:10;not null;uniqueIndex:idx_exchange_rates_pair_date" json:"base_currency"`
+	QuoteCurrency string           `gorm:"size:10;not null;uniqueIndex:idx_exchange_rates_pair_date" json:"quote_currency"`
+	RateDate      utils.CustomTime `gorm:"not null;type:date;uniqueIndex:idx_exchange_rates_pair_date" json:"rate_date"`
+	Rate          float64          `gorm:"type:decimal(20,8);not null" json:"rate"`
+	Source        string           `gorm:"size:20;not null;default:manual" json:"source"` // manual, csv
+	CreatedAt     utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt     utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"my-api/utils"
+)
+
+// ImportProfile is a saved CSV column mapping for a user's bank export
+type ImportProfile struct {
+	ID                uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID            uint             `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	Name              string           `gorm:"size:100;not null" json:"name"`
+	Delimiter         string           `gorm:"size:1;not null;default:','" json:"delimiter"`
+	HasHeader         bool             `gorm:"default:true" json:"has_header"`
+	SkipRows          int              `gorm:"not null;default:0" json:"skip_rows"`
+	DateColumn        string           `gorm:"size:100;not null" json:"date_column"` // header name or 1-based column number
+	DateFormat        string           `gorm:"size:50" json:"date_format"`           // e.g. DD/MM/YYYY, empty to auto-detect
+	DescriptionColumn string           `gorm:"size:100" json:"description_column"`
+	AmountColumn      string           `gorm:"size:100" json:"amount_column"`
+	DebitColumn       string           `gorm:"size:100" json:"debit_column"`
+	CreditColumn      string           `gorm:"size:100" json:"credit_column"`
+	TypeColumn        string           `gorm:"size:100" json:"type_column"`
+	DebitValue        string           `gorm:"size:20" json:"debit_value"`  // e.g. DB
+	CreditValue       string           `gorm:"size:20" json:"credit_value"` // e.g. CR
+	DecimalSeparator  string           `gorm:"size:1;not null;default:'.'" json:"decimal_separator"`
+	AssetID           *uint64          `gorm:"type:bigint unsigned" json:"asset_id"` // default target asset
+	IncomeCategoryID  *uint            `gorm:"type:int unsigned" json:"income_category_id"`
+	ExpenseCategoryID *uint            `gorm:"type:int unsigned" json:"expense_category_id"`
+	CreatedAt         utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt         utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"my-api/money"
+	"my-api/utils"
+)
+
+// Instrument kinds
+const (
+	InstrumentKindStock = "stock"
+	InstrumentKindFund  = "fund"
+	InstrumentKindBond  = "bond"
+	InstrumentKindGold  = "gold"
+	InstrumentKindOther = "other"
+)
+
+// Lot sides
+const (
+	LotSideBuy  = "buy"
+	LotSideSell = "sell"
+)
+
+// Instrument is something a user invests in: a stock, a mutual fund, gold.
+// Symbol is unique per user and is what price imports refer to.
+type Instrument struct {
+	ID        uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID    uint             `gorm:"not null;uniqueIndex:idx_instruments_user_symbol;type:int unsigned" json:"user_id"`
+	Symbol    string           `gorm:"size:50;not null;uniqueIndex:idx_instruments_user_symbol" json:"symbol"`
+	Name      string           `gorm:"size:255" json:"name"`
+	Kind      string           `gorm:"size:20;not null;default:other" json:"kind"`
+	Currency  string           `gorm:"size:10;not null" json:"currency"`
+	Unit      string           `gorm:"size:20" json:"unit"` // shares, units, grams
+	CreatedAt utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+}
+
+// HoldingLot is one buy or sell of an instrument inside an investment asset.
+// Price is per unit and Fees are paid on top of a buy or taken off the
+// proceeds of a sell.
+type HoldingLot struct {
+	ID           uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID       uint             `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	AssetID      uint64           `gorm:"not null;index:idx_holding_lots_asset_instrument;type:bigint unsigned" json:"asset_id"`
+	InstrumentID uint             `gorm:"not null;index:idx_holding_lots_asset_instrument;type:int unsigned" json:"instrument_id"`
+	Side         string           `gorm:"size:4;not null" json:"side"`
+	Quantity     float64          `gorm:"type:decimal(20,8);not null" json:"quantity"`
+	Price        money.Amount     `gorm:"type:decimal(19,4);not null" json:"price"`
+	Fees         money.Amount     `gorm:"type:decimal(19,4);not null;default:0" json:"fees"`
+	Date         utils.CustomTime `gorm:"not null;type:date" json:"date"`
+	Note         string           `gorm:"size:255" json:"note"`
+	CreatedAt    utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+
+	// Relations
+	Instrument Instrument `gorm:"foreignKey:InstrumentID" json:"instrument,omitempty"`
+	Asset      Asset      `gorm:"foreignKey:AssetID" json:"-"`
+}
+
+// InstrumentPrice is the closing price of one unit of an instrument on
+// PriceDate, in the instrument's currency
+type InstrumentPrice struct {
+	ID           uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID       uint             `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	InstrumentID uint             `gorm:"not null;uniqueIndex:idx_instrument_prices_date;type:int unsigned" json:"instrument_id"`
+	PriceDate    utils.CustomTime `gorm:"not null;type:date;uniqueIndex:idx_instrument_prices_date" json:"price_date"`
+	Price        money.Amount     `gorm:"type:decimal(19,4);not null" json:"price"`
+	Source       string           `gorm:"size:20;not null;default:manual" json:"source"` // manual, csv
+	CreatedAt    utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt    utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+}
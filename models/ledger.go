@@ -0,0 +1,63 @@
+package models
+
+import (
+	"my-api/money"
+	"my-api/utils"
+)
+
+// Journal entry types
+const (
+	EntryTypeTransaction    = "transaction"
+	EntryTypeTransfer       = "transfer"
+	EntryTypeOpeningBalance = "opening_balance"
+	EntryTypeAdjustment     = "adjustment"
+	EntryTypeReversal       = "reversal"
+)
+
+// Ledger accounts a posting can hit. Asset postings carry the AssetID; the
+// other accounts are the nominal side of an entry.
+const (
+	AccountAsset   = "asset"
+	AccountIncome  = "income"
+	AccountExpense = "expense"
+	AccountEquity  = "equity"
+)
+
+// JournalEntry is one balanced ledger event. Its postings always sum to zero.
+// Entries are never edited or removed: a changed or deleted transaction is
+// undone by a reversal entry pointing at the original through ReversesID, and
+// the original only gets ReversedAt stamped.
+type JournalEntry struct {
+	ID            uint              `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID        uint              `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	EntryType     string            `gorm:"size:20;not null" json:"entry_type"`
+	TransactionID *uint             `gorm:"index;type:int unsigned" json:"transaction_id,omitempty"`
+	TransferID    *uint             `gorm:"index;type:int unsigned" json:"transfer_id,omitempty"`
+	AssetID       *uint64           `gorm:"index;type:bigint unsigned" json:"asset_id,omitempty"` // unset on transfers, which touch two assets
+	ReversesID    *uint             `gorm:"index;type:int unsigned" json:"reverses_id,omitempty"`
+	ReversedAt    *utils.CustomTime `gorm:"type:datetime" json:"reversed_at,omitempty"`
+	Description   string            `gorm:"size:200" json:"description"`
+	Date          utils.CustomTime  `gorm:"not null;type:datetime" json:"date"`
+	CreatedAt     utils.CustomTime  `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+
+	// Relations
+	Postings []Posting `gorm:"foreignKey:EntryID" json:"postings,omitempty"`
+}
+
+// Posting is one leg of a journal entry. Amount is signed: positive debits
+// the account and negative credits it, so an asset's balance is the sum of
+// its postings.
+type Posting struct {
+	ID        uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	EntryID   uint             `gorm:"not null;index;type:int unsigned" json:"entry_id"`
+	UserID    uint             `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	Account   string           `gorm:"size:20;not null" json:"account"`
+	AssetID   *uint64          `gorm:"type:bigint unsigned" json:"asset_id,omitempty"`
+	Amount    money.Amount     `gorm:"type:decimal(19,4);not null" json:"amount"`
+	Currency  string           `gorm:"size:10;not null" json:"currency"`
+	Date      utils.CustomTime `gorm:"not null;type:datetime" json:"date"` // copied from the entry for statement ordering
+	CreatedAt utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+
+	// Relations
+	Entry JournalEntry `gorm:"foreignKey:EntryID" json:"-"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"my-api/utils"
+)
+
+// Payee is the merchant or person on the other side of a transaction
+type Payee struct {
+	ID        uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID    uint             `gorm:"not null;uniqueIndex:idx_payees_user_name;type:int unsigned" json:"user_id"`
+	Name      string           `gorm:"size:100;not null;uniqueIndex:idx_payees_user_name" json:"name"`
+	CreatedAt utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+
+	// Relations
+	User  User        `gorm:"foreignKey:UserID" json:"-"`
+	Rules []PayeeRule `gorm:"foreignKey:PayeeID" json:"rules,omitempty"`
+}
+
+// PayeeRule maps raw transaction descriptions onto a payee. Rules are tried
+// in ascending priority and the first match wins.
+type PayeeRule struct {
+	ID        uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID    uint             `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	PayeeID   uint             `gorm:"not null;index;type:int unsigned" json:"payee_id"`
+	MatchType utils.MatchType  `gorm:"size:20;not null" json:"match_type"` // exact, prefix, contains, regex
+	Pattern   string           `gorm:"size:255;not null" json:"pattern"`
+	Priority  int              `gorm:"not null;default:100" json:"priority"`
+	CreatedAt utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+}
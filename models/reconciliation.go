@@ -0,0 +1,24 @@
+package models
+
+import (
+	"my-api/money"
+	"my-api/utils"
+)
+
+// Reconciliation records that a wallet was checked against a bank statement.
+// ComputedBalance is what the app held as of StatementDate; a non-zero
+// Adjustment was posted to the ledger to make the two agree.
+type Reconciliation struct {
+	ID               uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID           uint             `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	AssetID          uint64           `gorm:"not null;index;type:bigint unsigned" json:"asset_id"`
+	StatementDate    utils.CustomTime `gorm:"not null;type:date" json:"statement_date"`
+	StatementBalance money.Amount     `gorm:"type:decimal(19,4);not null" json:"statement_balance"`
+	ComputedBalance  money.Amount     `gorm:"type:decimal(19,4);not null" json:"computed_balance"`
+	Adjustment       money.Amount     `gorm:"type:decimal(19,4);not null;default:0" json:"adjustment"`
+	Note             string           `gorm:"size:255" json:"note"`
+	CreatedAt        utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+
+	// Relations
+	Asset Asset `gorm:"foreignKey:AssetID" json:"-"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"my-api/money"
+	"my-api/utils"
+)
+
+const (
+	OccurrenceStatusPending = "pending" // Claimed by the worker, not posted yet
+	OccurrenceStatusPosted  = "posted"
+	OccurrenceStatusSkipped = "skipped"
+	OccurrenceStatusFailed  = "failed"
+)
+
+// RecurringTransaction is a template that posts TransactionV2 rows on a schedule
+type RecurringTransaction struct {
+	ID              uint                      `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID          uint                      `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	Description     string                    `gorm:"size:200;not null" json:"description"`
+	CategoryID      uint                      `gorm:"not null;index;type:int unsigned" json:"category_id"`
+	AssetID         uint64                    `gorm:"not null;index;type:bigint unsigned" json:"asset_id"`
+	Amount          money.Amount              `gorm:"type:decimal(19,4);not null" json:"amount"`
+	TransactionType int                       `gorm:"not null" json:"transaction_type"`  // 1=income, 2=expense
+	Frequency       utils.RecurrenceFrequency `gorm:"size:20;not null" json:"frequency"` // daily, weekly, monthly, yearly, pay_cycle
+	Interval        int                       `gorm:"not null;default:1" json:"interval"`
+	StartDate       utils.CustomTime          `gorm:"not null;type:datetime" json:"start_date"`
+	EndDate         *utils.CustomTime         `gorm:"type:datetime" json:"end_date"`
+	NextOccurrence  *utils.CustomTime         `gorm:"index;type:datetime" json:"next_occurrence"` // NULL once the schedule has ended
+	IsActive        bool                      `gorm:"default:true" json:"is_active"`
+	CreatedAt       utils.CustomTime          `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt       utils.CustomTime          `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+
+	// Relations
+	User     User     `gorm:"foreignKey:UserID" json:"-"`
+	Category Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Asset    Asset    `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
+}
+
+// Rule returns the schedule of the recurring transaction
+func (r *RecurringTransaction) Rule() utils.RecurrenceRule {
+	rule := utils.RecurrenceRule{
+		Frequency: r.Frequency,
+		Interval:  r.Interval,
+		StartDate: r.StartDate.Time,
+	}
+	if r.EndDate != nil {
+		rule.EndDate = &r.EndDate.Time
+	}
+	return rule
+}
+
+// RecurringOccurrence records what happened to a single scheduled date. The
+// unique index on (recurring_transaction_id, occurrence_date) guarantees an
+// occurrence is never posted twice.
+type RecurringOccurrence struct {
+	ID                     uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	RecurringTransactionID uint             `gorm:"not null;uniqueIndex:idx_recurring_occurrence;type:int unsigned" json:"recurring_transaction_id"`
+	UserID                 uint             `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	OccurrenceDate         utils.CustomTime `gorm:"not null;uniqueIndex:idx_recurring_occurrence;type:datetime" json:"occurrence_date"`
+	Status                 string           `gorm:"size:20;not null" json:"status"` // pending, posted, skipped, failed
+	TransactionID          *uint            `gorm:"index;type:int unsigned" json:"transaction_id"`
+	Message                string           `gorm:"size:255" json:"message"`
+	CreatedAt              utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt              utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"my-api/utils"
+)
+
+// Tag is a free-form label that can be attached to any number of transactions
+type Tag struct {
+	ID        uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID    uint             `gorm:"not null;uniqueIndex:idx_tags_user_name;type:int unsigned" json:"user_id"`
+	Name      string           `gorm:"size:50;not null;uniqueIndex:idx_tags_user_name" json:"name"`
+	Color     string           `gorm:"size:7" json:"color"` // e.g. #FF8800
+	CreatedAt utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"my-api/money"
 	"my-api/utils"
 )
 
@@ -10,7 +11,7 @@ type Transaction struct {
 	UserID          uint             `gorm:"not null;index;type:int unsigned" json:"user_id"`
 	CategoryID      uint             `gorm:"not null;index;type:int unsigned" json:"category_id"`
 	BankID          uint             `gorm:"index;type:int unsigned" json:"bank_id"`
-	Amount          int              `gorm:"not null" json:"amount"`
+	Amount          money.Amount     `gorm:"type:decimal(19,4);not null" json:"amount"`
 	TransactionType int              `gorm:"not null" json:"transaction_type"` // 1=income, 2=expense
 	Date            utils.CustomTime `gorm:"not null;index;type:datetime" json:"date"`
 	CreatedAt       utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
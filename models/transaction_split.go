@@ -0,0 +1,22 @@
+package models
+
+import (
+	"my-api/money"
+	"my-api/utils"
+)
+
+// TransactionSplit is one category line of a TransactionV2. When a transaction
+// has splits their amounts add up to the parent amount, and category analytics
+// use the split lines instead of the parent category.
+type TransactionSplit struct {
+	ID            uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	TransactionID uint             `gorm:"not null;index;type:int unsigned" json:"transaction_id"`
+	CategoryID    uint             `gorm:"not null;index;type:int unsigned" json:"category_id"`
+	Amount        money.Amount     `gorm:"type:decimal(19,4);not null" json:"amount"`
+	Description   string           `gorm:"size:200" json:"description"`
+	CreatedAt     utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt     utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+
+	// Relations
+	Category Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+}
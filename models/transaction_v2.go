@@ -1,7 +1,18 @@
 package models
 
 import (
+	"my-api/money"
 	"my-api/utils"
+
+	"gorm.io/gorm"
+)
+
+// Transaction statuses. Pending rows have not posted at the bank yet;
+// reconciled rows were matched to a statement and are locked.
+const (
+	TransactionStatusPending    = "pending"
+	TransactionStatusCleared    = "cleared"
+	TransactionStatusReconciled = "reconciled"
 )
 
 func (TransactionV2) TableName() string {
@@ -13,18 +24,27 @@ type TransactionV2 struct {
 	ID              uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
 	Description     string           `gorm:"size:200;not null" json:"description"`
 	UserID          uint             `gorm:"not null;index;type:int unsigned" json:"user_id"`
-	CategoryID      uint             `gorm:"not null;index;type:int unsigned" json:"category_id"`
+	CategoryID      uint             `gorm:"index;type:int unsigned" json:"category_id"` // NULL on transfer legs without a category
 	BankID          uint             `gorm:"index;type:int unsigned" json:"bank_id"`
 	AssetID         uint64           `gorm:"not null;index;type:bigint unsigned" json:"asset_id"`
-	Amount          int              `gorm:"not null" json:"amount"`
+	Amount          money.Amount     `gorm:"type:decimal(19,4);not null" json:"amount"`
 	TransactionType int              `gorm:"not null" json:"transaction_type"` // 1=income, 2=expense
+	Status          string           `gorm:"size:20;not null;default:cleared" json:"status"`
+	TransferID      *uint            `gorm:"index;type:int unsigned" json:"transfer_id,omitempty"` // set on transfer legs
+	ImportKey       *string          `gorm:"size:255" json:"-"`                                    // FITID or statement tuple hash, set on imported rows
+	PayeeID         *uint            `gorm:"index;type:int unsigned" json:"payee_id,omitempty"`
 	Date            utils.CustomTime `gorm:"not null;index;type:datetime" json:"date"`
 	CreatedAt       utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
 	UpdatedAt       utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+	DeletedAt       gorm.DeletedAt   `gorm:"index" json:"-"` // set while the transaction is in the trash
 
 	// Relations
 	User     User     `gorm:"foreignKey:UserID" json:"-"`
 	Category Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
 	Bank     Bank     `gorm:"foreignKey:BankID" json:"bank,omitempty"`
 	Asset    Asset    `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
+	Payee    *Payee   `gorm:"foreignKey:PayeeID" json:"payee,omitempty"`
+
+	Splits []TransactionSplit `gorm:"foreignKey:TransactionID" json:"splits,omitempty"`
+	Tags   []Tag              `gorm:"many2many:transaction_tags;joinForeignKey:TransactionID;joinReferences:TagID" json:"tags,omitempty"`
 }
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"my-api/money"
+	"my-api/utils"
+)
+
+// Transfer moves money from one asset to another. Each transfer owns two
+// TransactionV2 legs (an expense on the source and an income on the target)
+// which carry its ID in TransferID so analytics can leave them out.
+type Transfer struct {
+	ID                  uint             `gorm:"primaryKey;autoIncrement;type:int unsigned" json:"id"`
+	UserID              uint             `gorm:"not null;index;type:int unsigned" json:"user_id"`
+	SourceAssetID       uint64           `gorm:"not null;index;type:bigint unsigned" json:"source_asset_id"`
+	TargetAssetID       uint64           `gorm:"not null;index;type:bigint unsigned" json:"target_asset_id"`
+	Amount              money.Amount     `gorm:"type:decimal(19,4);not null" json:"amount"`
+	Description         string           `gorm:"size:200" json:"description"`
+	Date                utils.CustomTime `gorm:"not null;index;type:datetime" json:"date"`
+	SourceTransactionID uint             `gorm:"index;type:int unsigned" json:"source_transaction_id"`
+	TargetTransactionID uint             `gorm:"index;type:int unsigned" json:"target_transaction_id"`
+	CreatedAt           utils.CustomTime `gorm:"autoCreateTime;type:datetime" json:"created_at"`
+	UpdatedAt           utils.CustomTime `gorm:"autoUpdateTime;type:datetime" json:"updated_at"`
+
+	// Relations
+	User        User  `gorm:"foreignKey:UserID" json:"-"`
+	SourceAsset Asset `gorm:"foreignKey:SourceAssetID" json:"source_asset,omitempty"`
+	TargetAsset Asset `gorm:"foreignKey:TargetAssetID" json:"target_asset,omitempty"`
+}
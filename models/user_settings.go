@@ -20,6 +20,7 @@ type UserSettings struct {
 	PayCycleType     PayCycleType `json:"pay_cycle_type" gorm:"type:enum('calendar','last_weekday','custom_day','bi_weekly');default:'calendar';not null"`
 	PayDay           *int         `json:"pay_day" gorm:"type:int;default:null"` // Day of month (1-31) or day of week (0-6)
 	CycleStartOffset int          `json:"cycle_start_offset" gorm:"type:int;default:1;not null"`
+	BaseCurrency     string       `json:"base_currency" gorm:"size:10;default:'IDR';not null"`
 	CreatedAt        time.Time    `json:"created_at" gorm:"type:timestamp;default:CURRENT_TIMESTAMP;not null"`
 	UpdatedAt        *time.Time   `json:"updated_at" gorm:"type:timestamp;default:null;onUpdate:CURRENT_TIMESTAMP"`
 	User             User         `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
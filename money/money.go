@@ -0,0 +1,302 @@
+// Package money provides exact decimal amounts for balances and transactions.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of decimal places an Amount keeps
+const Scale = 4
+
+// unit is the number of stored units in 1.0
+const unit = 10000
+
+// SQLType is the column type money columns are stored in
+const SQLType = "decimal(19,4)"
+
+var (
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrAmountOverflow   = errors.New("amount is out of range")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+// Amount is an exact decimal number with Scale decimal places, stored as a
+// count of 1/10000 units. Amounts can be added, subtracted and compared with
+// the ordinary operators; the zero value is 0.
+type Amount int64
+
+// FromInt returns the amount of n whole units
+func FromInt(n int64) Amount {
+	return Amount(n * unit)
+}
+
+// FromFloat converts f, rounding half away from zero to Scale places. It is
+// meant for values that are inexact anyway, such as converted amounts.
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * unit))
+}
+
+// Parse reads a decimal string such as "1250000", "-12.5" or "0.0001".
+// Digits beyond Scale decimal places are rounded half away from zero.
+func Parse(value string) (Amount, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return 0, ErrInvalidAmount
+	}
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsInf(f, 0) || math.IsNaN(f) || math.Abs(f) > math.MaxInt64/unit {
+			return 0, ErrInvalidAmount
+		}
+		return FromFloat(f), nil
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" && fraction == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	var units int64
+	for _, ch := range whole {
+		if ch < '0' || ch > '9' {
+			return 0, ErrInvalidAmount
+		}
+		if units > (math.MaxInt64-9)/10/unit {
+			return 0, ErrAmountOverflow
+		}
+		units = units*10 + int64(ch-'0')
+	}
+	units *= unit
+
+	var scaled int64
+	roundUp := false
+	for i, ch := range fraction {
+		if ch < '0' || ch > '9' {
+			return 0, ErrInvalidAmount
+		}
+		switch {
+		case i < Scale:
+			scaled = scaled*10 + int64(ch-'0')
+		case i == Scale:
+			roundUp = ch >= '5'
+		}
+	}
+	for i := len(fraction); i < Scale; i++ {
+		scaled *= 10
+	}
+
+	units += scaled
+	if roundUp {
+		units++
+	}
+	if units < 0 {
+		return 0, ErrAmountOverflow
+	}
+	if negative {
+		units = -units
+	}
+	return Amount(units), nil
+}
+
+// String formats the amount without trailing zeros, e.g. "1250000" or "-12.5"
+func (a Amount) String() string {
+	units := int64(a)
+	sign := ""
+	if units < 0 {
+		sign = "-"
+	}
+	whole := uint64(units)
+	if units < 0 {
+		whole = uint64(-units)
+	}
+
+	fraction := whole % unit
+	whole /= unit
+	if fraction == 0 {
+		return sign + strconv.FormatUint(whole, 10)
+	}
+	digits := strings.TrimRight(fmt.Sprintf("%0*d", Scale, fraction), "0")
+	return sign + strconv.FormatUint(whole, 10) + "." + digits
+}
+
+// Float64 returns the nearest float64, for ratios and percentages
+func (a Amount) Float64() float64 {
+	return float64(a) / unit
+}
+
+// Whole returns the integer part, truncated toward zero
+func (a Amount) Whole() int64 {
+	return int64(a) / unit
+}
+
+func (a Amount) IsZero() bool     { return a == 0 }
+func (a Amount) IsNegative() bool { return a < 0 }
+func (a Amount) IsPositive() bool { return a > 0 }
+
+func (a Amount) Neg() Amount {
+	return -a
+}
+
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Mul multiplies by a whole number
+func (a Amount) Mul(n int64) Amount {
+	return a * Amount(n)
+}
+
+// Div divides by a whole number, rounding half away from zero. Dividing by
+// zero returns zero.
+func (a Amount) Div(n int64) Amount {
+	if n == 0 {
+		return 0
+	}
+	num, den := int64(a), n
+	if den < 0 {
+		num, den = -num, -den
+	}
+	q, r := num/den, num%den
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= den {
+		if num < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return Amount(q)
+}
+
+// MulRate multiplies by an exchange rate or ratio, rounding the result to
+// Scale places
+func (a Amount) MulRate(rate float64) Amount {
+	return FromFloat(a.Float64() * rate)
+}
+
+// Value implements driver.Valuer. The amount is written as a decimal string
+// so the database never sees a float.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan implements sql.Scanner for DECIMAL and integer columns, and for
+// aggregates such as SUM that come back as decimal strings
+func (a *Amount) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case int64:
+		*a = FromInt(v)
+		return nil
+	case float64:
+		*a = FromFloat(v)
+		return nil
+	case []byte:
+		parsed, err := Parse(string(v))
+		if err != nil {
+			return fmt.Errorf("cannot scan %q into Amount: %w", v, err)
+		}
+		*a = parsed
+		return nil
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return fmt.Errorf("cannot scan %q into Amount: %w", v, err)
+		}
+		*a = parsed
+		return nil
+	default:
+		return fmt.Errorf("cannot scan type %T into Amount", value)
+	}
+}
+
+// MarshalJSON writes the amount as a JSON number with its exact digits
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a quoted decimal string
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid amount %s", data)
+	}
+	*a = parsed
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for form and query binding
+func (a *Amount) UnmarshalText(data []byte) error {
+	parsed, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Money is an amount together with the currency it is in. Arithmetic
+// between two values only succeeds when their currencies match.
+type Money struct {
+	Amount   Amount `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// New returns amount in currency
+func New(amount Amount, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// Add returns m + other, or ErrCurrencyMismatch
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return m, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns m - other, or ErrCurrencyMismatch
+func (m Money) Sub(other Money) (Money, error) {
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+// Convert returns the value in currency at the given rate, where rate is the
+// number of units of currency per unit of m.Currency
+func (m Money) Convert(rate float64, currency string) Money {
+	if strings.EqualFold(m.Currency, currency) {
+		return m
+	}
+	return New(m.Amount.MulRate(rate), currency)
+}
+
+func (m Money) String() string {
+	return m.Amount.String() + " " + m.Currency
+}
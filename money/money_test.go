@@ -0,0 +1,145 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseAndString(t *testing.T) {
+	cases := []struct {
+		input string
+		units Amount
+		text  string
+	}{
+		{"1250000", 12500000000, "1250000"},
+		{"-12.5", -125000, "-12.5"},
+		{"0.0001", 1, "0.0001"},
+		{"+3.10", 31000, "3.1"},
+		{".5", 5000, "0.5"},
+		{"100.00000000", 1000000, "100"},
+		{"0.00005", 1, "0.0001"},
+		{"-0.00005", -1, "-0.0001"},
+		{"1e3", 10000000, "1000"},
+	}
+
+	for _, tc := range cases {
+		got, err := Parse(tc.input)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tc.input, err)
+		}
+		if got != tc.units {
+			t.Errorf("Parse(%q): expected %d units, got %d", tc.input, tc.units, got)
+		}
+		if got.String() != tc.text {
+			t.Errorf("Parse(%q).String(): expected %q, got %q", tc.input, tc.text, got.String())
+		}
+	}
+}
+
+func TestParseRejectsInvalidInput(t *testing.T) {
+	for _, input := range []string{"", "-", ".", "1,000", "12a", "1.2.3"} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q): expected an error", input)
+		}
+	}
+	if _, err := Parse("99999999999999999999"); !errors.Is(err, ErrAmountOverflow) {
+		t.Errorf("expected ErrAmountOverflow, got %v", err)
+	}
+}
+
+func TestArithmeticIsExact(t *testing.T) {
+	// 0.1 added ten times drifts as a float but not as an Amount
+	var total Amount
+	tenCents, _ := Parse("0.1")
+	for i := 0; i < 10; i++ {
+		total += tenCents
+	}
+	if total != FromInt(1) {
+		t.Errorf("expected exactly 1, got %s", total)
+	}
+
+	if got := FromInt(10).Div(3); got.String() != "3.3333" {
+		t.Errorf("10 / 3: expected 3.3333, got %s", got)
+	}
+	if got := FromInt(-2).Div(3); got.String() != "-0.6667" {
+		t.Errorf("-2 / 3: expected -0.6667, got %s", got)
+	}
+	if got := FromInt(100).MulRate(0.00006135); got.String() != "0.0061" {
+		t.Errorf("100 * 0.00006135: expected 0.0061, got %s", got)
+	}
+}
+
+func TestScan(t *testing.T) {
+	cases := []struct {
+		value interface{}
+		want  string
+	}{
+		{[]byte("1500.2500"), "1500.25"},
+		{"-75.00", "-75"},
+		{int64(42), "42"},
+		{float64(2.5), "2.5"},
+		{nil, "0"},
+	}
+
+	for _, tc := range cases {
+		var a Amount
+		if err := a.Scan(tc.value); err != nil {
+			t.Fatalf("Scan(%v) failed: %v", tc.value, err)
+		}
+		if a.String() != tc.want {
+			t.Errorf("Scan(%v): expected %s, got %s", tc.value, tc.want, a)
+		}
+	}
+
+	var a Amount
+	if err := a.Scan(true); err == nil {
+		t.Error("expected an error scanning a bool")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var payload struct {
+		Amount Amount `json:"amount"`
+		Fee    Amount `json:"fee"`
+	}
+	if err := json.Unmarshal([]byte(`{"amount": 1250.75, "fee": "0.5"}`), &payload); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if payload.Amount.String() != "1250.75" || payload.Fee.String() != "0.5" {
+		t.Errorf("unexpected amounts %s and %s", payload.Amount, payload.Fee)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != `{"amount":1250.75,"fee":0.5}` {
+		t.Errorf("unexpected JSON %s", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"amount": "abc"}`), &payload); err == nil {
+		t.Error("expected an error for a non-numeric amount")
+	}
+}
+
+func TestMoney(t *testing.T) {
+	a := New(FromInt(10), "usd")
+	b := New(FromInt(5), "USD")
+
+	sum, err := a.Add(b)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if sum.String() != "15 USD" {
+		t.Errorf("expected 15 USD, got %s", sum)
+	}
+
+	if _, err := a.Sub(New(FromInt(1), "IDR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+
+	if got := a.Convert(16250, "IDR"); got.String() != "162500 IDR" {
+		t.Errorf("expected 162500 IDR, got %s", got)
+	}
+}
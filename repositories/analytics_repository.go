@@ -3,20 +3,54 @@ package repositories
 import (
 	"gorm.io/gorm"
 	"my-api/models"
+	"my-api/money"
 	"my-api/utils"
 	"time"
 )
 
+// Category analytics work on transaction lines: a transaction without splits is
+// a single line with its own category and amount, a split transaction yields
+// one line per split.
+const (
+	splitLinesJoin     = "LEFT JOIN transaction_splits ON transaction_splits.transaction_id = transactions.id"
+	lineCategoryColumn = "COALESCE(transaction_splits.category_id, transactions.category_id)"
+	lineAmountColumn   = "COALESCE(transaction_splits.amount, transactions.amount)"
+)
+
+// Amounts are summed both as stored, per currency, and converted into the
+// user's base currency, rounded to money.Scale places. Queries that use these run against fxTransactions,
+// which adds the currency, fx_rate and rate_missing columns. rateMissingFlag
+// marks rows grouped by currency that were converted at 1 for lack of a rate,
+// ratesMissingList lists those currencies for any other grouping.
+const (
+	convertedAmountSum     = "ROUND(SUM(transactions.amount * transactions.fx_rate), 4)"
+	convertedLineAmountSum = "ROUND(SUM(" + lineAmountColumn + " * transactions.fx_rate), 4)"
+	rateMissingFlag        = "MAX(transactions.rate_missing)"
+	ratesMissingList       = "GROUP_CONCAT(DISTINCT CASE WHEN transactions.rate_missing = 1 THEN transactions.currency END)"
+)
+
+// Transactions on a wallet in the trash are left out of analytics and budget
+// spending until the wallet is restored. Transactions in the trash themselves
+// are excluded by the soft delete scope, or explicitly on raw table queries.
+const trashedAssetsCondition = "transactions.asset_id NOT IN (SELECT id FROM assets WHERE deleted_at IS NOT NULL)"
+
+// Before transfers had their own table, both legs were booked as ordinary
+// transactions in category 18. Those legacy rows have no transfer_id and are
+// kept out of the income and expense trends as before.
+const legacyTransferCategoryID = 18
+
 type AnalyticsRepository interface {
 	GetTransactionsByDateRange(userID uint, startDate, endDate time.Time, assetID *uint64) ([]models.TransactionV2, error)
-	GetSpendingByCategory(userID uint, startDate, endDate time.Time, transactionType int, assetID *uint64) ([]map[string]interface{}, error)
-	GetSpendingByBank(userID uint, startDate, endDate time.Time, assetID *uint64) ([]map[string]interface{}, error)
-	GetSpendingByAsset(userID uint, startDate, endDate time.Time) ([]map[string]interface{}, error)
-	GetIncomeVsExpense(userID uint, startDate, endDate time.Time, assetID *uint64) (map[string]interface{}, error)
-	GetMonthlyTrend(userID uint, startDate, endDate time.Time, assetID *uint64) ([]map[string]interface{}, error)
-	GetMonthlyTrendByPayCycle(userID uint, startDate, endDate time.Time, assetID *uint64, settings *models.UserSettings) ([]map[string]interface{}, error)
+	GetSpendingByCategory(userID uint, startDate, endDate time.Time, transactionType int, assetID *uint64, baseCurrency string) ([]map[string]interface{}, error)
+	GetSpendingByTag(userID uint, startDate, endDate time.Time, transactionType int, assetID *uint64, baseCurrency string) ([]map[string]interface{}, error)
+	GetSpendingByPayee(userID uint, startDate, endDate time.Time, transactionType int, assetID *uint64, baseCurrency string) ([]map[string]interface{}, error)
+	GetSpendingByBank(userID uint, startDate, endDate time.Time, assetID *uint64, baseCurrency string) ([]map[string]interface{}, error)
+	GetSpendingByAsset(userID uint, startDate, endDate time.Time, baseCurrency string) ([]map[string]interface{}, error)
+	GetIncomeVsExpense(userID uint, startDate, endDate time.Time, assetID *uint64, baseCurrency string) (map[string]interface{}, error)
+	GetMonthlyTrend(userID uint, startDate, endDate time.Time, assetID *uint64, baseCurrency string) ([]map[string]interface{}, error)
+	GetMonthlyTrendByPayCycle(userID uint, startDate, endDate time.Time, assetID *uint64, settings *models.UserSettings, baseCurrency string) ([]map[string]interface{}, error)
 	GetRecentTransactions(userID uint, limit int, assetID *uint64) ([]models.TransactionV2, error)
-	GetCategoryTrend(userID uint, categoryID uint, startDate, endDate time.Time, assetID *uint64) ([]map[string]interface{}, error)
+	GetCategoryTrend(userID uint, categoryID uint, startDate, endDate time.Time, assetID *uint64, baseCurrency string) ([]map[string]interface{}, error)
 }
 
 type analyticsRepository struct {
@@ -27,9 +61,40 @@ func NewAnalyticsRepository(db *gorm.DB) AnalyticsRepository {
 	return &analyticsRepository{db: db}
 }
 
+// fxTransactions selects the user's transactions with three extra columns:
+// currency, the currency of the transaction's asset, fx_rate, the rate into
+// baseCurrency in effect on the transaction date, and rate_missing. A direct
+// rate is preferred over the inverse of the reverse pair; without either the
+// rate is 1 and rate_missing is set, so callers can report the currencies
+// whose totals were not really converted. Transactions without an asset are
+// already in baseCurrency. Analytics queries use it aliased as "transactions"
+// so their conditions stay unchanged. Transactions in the trash, or on a
+// wallet in the trash, are left out.
+func (r *analyticsRepository) fxTransactions(userID uint, baseCurrency string) *gorm.DB {
+	rated := r.db.Table("transactions").
+		Select(`transactions.*,
+			COALESCE(assets.currency, ?) AS currency,
+			CASE WHEN assets.currency IS NULL OR assets.currency = ? THEN 1 ELSE COALESCE(
+				(SELECT er.rate FROM exchange_rates er
+				 WHERE er.user_id = transactions.user_id AND er.base_currency = assets.currency AND er.quote_currency = ?
+				   AND er.rate_date <= DATE(transactions.date)
+				 ORDER BY er.rate_date DESC LIMIT 1),
+				(SELECT 1 / er.rate FROM exchange_rates er
+				 WHERE er.user_id = transactions.user_id AND er.base_currency = ? AND er.quote_currency = assets.currency
+				   AND er.rate_date <= DATE(transactions.date)
+				 ORDER BY er.rate_date DESC LIMIT 1)) END AS found_rate`, baseCurrency, baseCurrency, baseCurrency, baseCurrency).
+		Joins("LEFT JOIN assets ON assets.id = transactions.asset_id").
+		Where("transactions.user_id = ? AND transactions.deleted_at IS NULL", userID).
+		Where("assets.deleted_at IS NULL")
+
+	return r.db.Table("(?) AS transactions", rated).
+		Select("transactions.*, COALESCE(transactions.found_rate, 1) AS fx_rate, transactions.found_rate IS NULL AS rate_missing")
+}
+
 func (r *analyticsRepository) GetTransactionsByDateRange(userID uint, startDate, endDate time.Time, assetID *uint64) ([]models.TransactionV2, error) {
 	var transactions []models.TransactionV2
-	query := r.db.Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate)
+	query := r.db.Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate).
+		Where(trashedAssetsCondition)
 	if assetID != nil {
 		query = query.Where("asset_id = ?", *assetID)
 	}
@@ -38,46 +103,91 @@ func (r *analyticsRepository) GetTransactionsByDateRange(userID uint, startDate,
 	return transactions, err
 }
 
-func (r *analyticsRepository) GetSpendingByCategory(userID uint, startDate, endDate time.Time, transactionType int, assetID *uint64) ([]map[string]interface{}, error) {
+// GetSpendingByCategory returns one row per category and currency
+func (r *analyticsRepository) GetSpendingByCategory(userID uint, startDate, endDate time.Time, transactionType int, assetID *uint64, baseCurrency string) ([]map[string]interface{}, error) {
 	var results []map[string]interface{}
 
-	query := r.db.Table("transactions").
-		Select("categories.id as category_id, categories.category_name, SUM(transactions.amount) as total_amount, COUNT(*) as count").
-		Joins("JOIN categories ON transactions.category_id = categories.id").
-		Where("transactions.user_id = ? AND transactions.transaction_type = ? AND transactions.date BETWEEN ? AND ?",
+	query := r.db.Table("(?) AS transactions", r.fxTransactions(userID, baseCurrency)).
+		Select("categories.id as category_id, categories.category_name, transactions.currency, SUM(" + lineAmountColumn + ") as original_amount, " + convertedLineAmountSum + " as total_amount, COUNT(DISTINCT transactions.id) as count, " + rateMissingFlag + " as rate_missing").
+		Joins(splitLinesJoin).
+		Joins("JOIN categories ON " + lineCategoryColumn + " = categories.id").
+		Where("transactions.user_id = ? AND transactions.transaction_type = ? AND transactions.date BETWEEN ? AND ? AND transactions.transfer_id IS NULL",
 			userID, transactionType, startDate, endDate)
 	if assetID != nil {
 		query = query.Where("transactions.asset_id = ?", *assetID)
 	}
-	err := query.Group("categories.id, categories.category_name").
+	err := query.Group("categories.id, categories.category_name, transactions.currency").
 		Order("total_amount DESC").
 		Scan(&results).Error
 
 	return results, err
 }
 
-func (r *analyticsRepository) GetSpendingByBank(userID uint, startDate, endDate time.Time, assetID *uint64) ([]map[string]interface{}, error) {
+// GetSpendingByTag groups transactions by tag. A transaction with several tags
+// counts towards each of them, so the totals can add up to more than the
+// overall spending. Rows are split by currency.
+func (r *analyticsRepository) GetSpendingByTag(userID uint, startDate, endDate time.Time, transactionType int, assetID *uint64, baseCurrency string) ([]map[string]interface{}, error) {
 	var results []map[string]interface{}
 
-	query := r.db.Table("transactions").
-		Select("COALESCE(banks.id, 0) as bank_id, COALESCE(banks.bank_name, 'No Bank') as bank_name, SUM(transactions.amount) as total_amount, COUNT(*) as count").
+	query := r.db.Table("(?) AS transactions", r.fxTransactions(userID, baseCurrency)).
+		Select("tags.id as tag_id, tags.name as tag_name, tags.color, transactions.currency, SUM(transactions.amount) as original_amount, " + convertedAmountSum + " as total_amount, COUNT(*) as count, " + rateMissingFlag + " as rate_missing").
+		Joins("JOIN transaction_tags ON transaction_tags.transaction_id = transactions.id").
+		Joins("JOIN tags ON tags.id = transaction_tags.tag_id").
+		Where("transactions.user_id = ? AND transactions.transaction_type = ? AND transactions.date BETWEEN ? AND ? AND transactions.transfer_id IS NULL",
+			userID, transactionType, startDate, endDate)
+	if assetID != nil {
+		query = query.Where("transactions.asset_id = ?", *assetID)
+	}
+	err := query.Group("tags.id, tags.name, tags.color, transactions.currency").
+		Order("total_amount DESC").
+		Scan(&results).Error
+
+	return results, err
+}
+
+// GetSpendingByPayee groups transactions by payee. Transactions without a
+// payee are reported together with payee_id 0. Rows are split by currency.
+func (r *analyticsRepository) GetSpendingByPayee(userID uint, startDate, endDate time.Time, transactionType int, assetID *uint64, baseCurrency string) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+
+	query := r.db.Table("(?) AS transactions", r.fxTransactions(userID, baseCurrency)).
+		Select("COALESCE(payees.id, 0) as payee_id, COALESCE(payees.name, 'Unassigned') as payee_name, transactions.currency, SUM(transactions.amount) as original_amount, " + convertedAmountSum + " as total_amount, COUNT(*) as count, " + rateMissingFlag + " as rate_missing").
+		Joins("LEFT JOIN payees ON payees.id = transactions.payee_id").
+		Where("transactions.user_id = ? AND transactions.transaction_type = ? AND transactions.date BETWEEN ? AND ? AND transactions.transfer_id IS NULL",
+			userID, transactionType, startDate, endDate)
+	if assetID != nil {
+		query = query.Where("transactions.asset_id = ?", *assetID)
+	}
+	err := query.Group("payees.id, payees.name, transactions.currency").
+		Order("total_amount DESC").
+		Scan(&results).Error
+
+	return results, err
+}
+
+// GetSpendingByBank returns one row per bank and currency
+func (r *analyticsRepository) GetSpendingByBank(userID uint, startDate, endDate time.Time, assetID *uint64, baseCurrency string) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+
+	query := r.db.Table("(?) AS transactions", r.fxTransactions(userID, baseCurrency)).
+		Select("COALESCE(banks.id, 0) as bank_id, COALESCE(banks.bank_name, 'No Bank') as bank_name, transactions.currency, SUM(transactions.amount) as original_amount, " + convertedAmountSum + " as total_amount, COUNT(*) as count, " + rateMissingFlag + " as rate_missing").
 		Joins("LEFT JOIN banks ON transactions.bank_id = banks.id").
-		Where("transactions.user_id = ? AND transactions.date BETWEEN ? AND ?",
+		Where("transactions.user_id = ? AND transactions.date BETWEEN ? AND ? AND transactions.transfer_id IS NULL",
 			userID, startDate, endDate)
 	if assetID != nil {
 		query = query.Where("transactions.asset_id = ?", *assetID)
 	}
-	err := query.Group("banks.id, banks.bank_name").
+	err := query.Group("banks.id, banks.bank_name, transactions.currency").
 		Order("total_amount DESC").
 		Scan(&results).Error
 
 	return results, err
 }
 // GetSpendingByAsset returns spending grouped by asset/wallet
-func (r *analyticsRepository) GetSpendingByAsset(userID uint, startDate, endDate time.Time) ([]map[string]interface{}, error) {
+func (r *analyticsRepository) GetSpendingByAsset(userID uint, startDate, endDate time.Time, baseCurrency string) ([]map[string]interface{}, error) {
 	var results []map[string]interface{}
 
-	err := r.db.Table("transactions").
+	err := r.db.Table("(?) AS transactions", r.fxTransactions(userID, baseCurrency)).
 		Select(`
 			assets.id as asset_id, 
 			assets.name as asset_name, 
@@ -85,36 +195,42 @@ func (r *analyticsRepository) GetSpendingByAsset(userID uint, startDate, endDate
 			assets.currency as asset_currency,
 			SUM(CASE WHEN transactions.transaction_type = 1 THEN transactions.amount ELSE 0 END) as total_income,
 			SUM(CASE WHEN transactions.transaction_type = 2 THEN transactions.amount ELSE 0 END) as total_expense,
-			COUNT(*) as transaction_count
+			ROUND(SUM(CASE WHEN transactions.transaction_type = 1 THEN transactions.amount * transactions.fx_rate ELSE 0 END), 4) as converted_income,
+			ROUND(SUM(CASE WHEN transactions.transaction_type = 2 THEN transactions.amount * transactions.fx_rate ELSE 0 END), 4) as converted_expense,
+			COUNT(*) as transaction_count,
+			` + rateMissingFlag + ` as rate_missing
 		`).
 		Joins("INNER JOIN assets ON transactions.asset_id = assets.id").
-		Where("transactions.user_id = ? AND transactions.date BETWEEN ? AND ?", userID, startDate, endDate).
+		Where("transactions.user_id = ? AND transactions.date BETWEEN ? AND ? AND transactions.transfer_id IS NULL", userID, startDate, endDate).
 		Group("assets.id, assets.name, assets.type, assets.currency").
-		Order("total_expense DESC").
+		Order("converted_expense DESC").
 		Scan(&results).Error
 
 	return results, err
 }
-func (r *analyticsRepository) GetIncomeVsExpense(userID uint, startDate, endDate time.Time, assetID *uint64) (map[string]interface{}, error) {
+// GetIncomeVsExpense returns totals converted into baseCurrency, plus
+// original_income and original_expense maps keyed by currency and
+// rates_missing, the currencies converted at 1.
+func (r *analyticsRepository) GetIncomeVsExpense(userID uint, startDate, endDate time.Time, assetID *uint64, baseCurrency string) (map[string]interface{}, error) {
 	result := make(map[string]interface{})
 
 	// Optimize with a single query instead of 4 separate queries
 	type QueryResult struct {
-		TotalIncome   int64 `gorm:"column:total_income"`
-		TotalExpense  int64 `gorm:"column:total_expense"`
-		IncomeCount   int64 `gorm:"column:income_count"`
-		ExpenseCount  int64 `gorm:"column:expense_count"`
+		TotalIncome   money.Amount `gorm:"column:total_income"`
+		TotalExpense  money.Amount `gorm:"column:total_expense"`
+		IncomeCount   int64        `gorm:"column:income_count"`
+		ExpenseCount  int64        `gorm:"column:expense_count"`
 	}
 
 	var queryResult QueryResult
-	query := r.db.Model(&models.TransactionV2{}).
+	query := r.db.Table("(?) AS transactions", r.fxTransactions(userID, baseCurrency)).
 		Select(`
-			COALESCE(SUM(CASE WHEN transaction_type = 1 THEN amount ELSE 0 END), 0) as total_income,
-			COALESCE(SUM(CASE WHEN transaction_type = 2 THEN amount ELSE 0 END), 0) as total_expense,
+			COALESCE(ROUND(SUM(CASE WHEN transaction_type = 1 THEN amount * fx_rate ELSE 0 END), 4), 0) as total_income,
+			COALESCE(ROUND(SUM(CASE WHEN transaction_type = 2 THEN amount * fx_rate ELSE 0 END), 4), 0) as total_expense,
 			COUNT(CASE WHEN transaction_type = 1 THEN 1 END) as income_count,
 			COUNT(CASE WHEN transaction_type = 2 THEN 1 END) as expense_count
 		`).
-		Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate)
+		Where("date BETWEEN ? AND ? AND transfer_id IS NULL", startDate, endDate)
 
 	if assetID != nil {
 		query = query.Where("asset_id = ?", *assetID)
@@ -125,27 +241,70 @@ func (r *analyticsRepository) GetIncomeVsExpense(userID uint, startDate, endDate
 		return nil, err
 	}
 
+	type CurrencyResult struct {
+		Currency     string       `gorm:"column:currency"`
+		TotalIncome  money.Amount `gorm:"column:total_income"`
+		TotalExpense money.Amount `gorm:"column:total_expense"`
+		RateMissing  bool         `gorm:"column:rate_missing"`
+	}
+
+	var currencyResults []CurrencyResult
+	currencyQuery := r.db.Table("(?) AS transactions", r.fxTransactions(userID, baseCurrency)).
+		Select(`
+			currency,
+			COALESCE(SUM(CASE WHEN transaction_type = 1 THEN amount ELSE 0 END), 0) as total_income,
+			COALESCE(SUM(CASE WHEN transaction_type = 2 THEN amount ELSE 0 END), 0) as total_expense,
+			` + rateMissingFlag + ` as rate_missing
+		`).
+		Where("date BETWEEN ? AND ? AND transfer_id IS NULL", startDate, endDate)
+
+	if assetID != nil {
+		currencyQuery = currencyQuery.Where("asset_id = ?", *assetID)
+	}
+
+	if err := currencyQuery.Group("currency").Scan(&currencyResults).Error; err != nil {
+		return nil, err
+	}
+
+	originalIncome := make(map[string]money.Amount)
+	originalExpense := make(map[string]money.Amount)
+	ratesMissing := []string{}
+	for _, row := range currencyResults {
+		if row.RateMissing {
+			ratesMissing = append(ratesMissing, row.Currency)
+		}
+		if row.TotalIncome != 0 {
+			originalIncome[row.Currency] = row.TotalIncome
+		}
+		if row.TotalExpense != 0 {
+			originalExpense[row.Currency] = row.TotalExpense
+		}
+	}
+
 	result["total_income"] = queryResult.TotalIncome
 	result["total_expense"] = queryResult.TotalExpense
 	result["income_count"] = queryResult.IncomeCount
 	result["expense_count"] = queryResult.ExpenseCount
 	result["net_amount"] = queryResult.TotalIncome - queryResult.TotalExpense
+	result["original_income"] = originalIncome
+	result["original_expense"] = originalExpense
+	result["rates_missing"] = ratesMissing
 
 	return result, nil
 }
 
-func (r *analyticsRepository) GetMonthlyTrend(userID uint, startDate, endDate time.Time, assetID *uint64) ([]map[string]interface{}, error) {
+func (r *analyticsRepository) GetMonthlyTrend(userID uint, startDate, endDate time.Time, assetID *uint64, baseCurrency string) ([]map[string]interface{}, error) {
 	var results []map[string]interface{}
 
 	query := `SELECT 
 		DATE_FORMAT(date, '%Y-%m') as month,
-		SUM(CASE WHEN transaction_type = 1 THEN amount ELSE 0 END) as income,
-		SUM(CASE WHEN transaction_type = 2 THEN amount ELSE 0 END) as expense
-	FROM transactions
-	WHERE user_id = ? AND date BETWEEN ? AND ? AND category_id != ?`
+		ROUND(SUM(CASE WHEN transaction_type = 1 THEN amount * fx_rate ELSE 0 END), 4) as income,
+		ROUND(SUM(CASE WHEN transaction_type = 2 THEN amount * fx_rate ELSE 0 END), 4) as expense
+	FROM (?) AS transactions
+	WHERE date BETWEEN ? AND ? AND transfer_id IS NULL AND category_id != ?`
 
 	var args []interface{}
-	args = append(args, userID, startDate, endDate,18)
+	args = append(args, r.fxTransactions(userID, baseCurrency), startDate, endDate, legacyTransferCategoryID)
 
 	if assetID != nil {
 		query += ` AND asset_id = ?`
@@ -162,7 +321,8 @@ func (r *analyticsRepository) GetMonthlyTrend(userID uint, startDate, endDate ti
 func (r *analyticsRepository) GetRecentTransactions(userID uint, limit int, assetID *uint64) ([]models.TransactionV2, error) {
 	var transactions []models.TransactionV2
 	query := r.db.Preload("Category").Preload("Bank").Preload("Asset").
-		Where("user_id = ?", userID)
+		Where("user_id = ?", userID).
+		Where(trashedAssetsCondition)
 	if assetID != nil {
 		query = query.Where("asset_id = ?", *assetID)
 	}
@@ -172,24 +332,26 @@ func (r *analyticsRepository) GetRecentTransactions(userID uint, limit int, asse
 	return transactions, err
 }
 
-func (r *analyticsRepository) GetCategoryTrend(userID uint, categoryID uint, startDate, endDate time.Time, assetID *uint64) ([]map[string]interface{}, error) {
+func (r *analyticsRepository) GetCategoryTrend(userID uint, categoryID uint, startDate, endDate time.Time, assetID *uint64, baseCurrency string) ([]map[string]interface{}, error) {
 	var results []map[string]interface{}
 
 	query := `SELECT 
-		DATE(date) as date,
-		SUM(amount) as amount
-	FROM transactions
-	WHERE user_id = ? AND category_id = ? AND date BETWEEN ? AND ?`
+		DATE(transactions.date) as date,
+		` + convertedLineAmountSum + ` as amount,
+		` + ratesMissingList + ` as rates_missing
+	FROM (?) AS transactions
+	` + splitLinesJoin + `
+	WHERE ` + lineCategoryColumn + ` = ? AND transactions.date BETWEEN ? AND ? AND transactions.transfer_id IS NULL`
 
 	var args []interface{}
-	args = append(args, userID, categoryID, startDate, endDate)
+	args = append(args, r.fxTransactions(userID, baseCurrency), categoryID, startDate, endDate)
 
 	if assetID != nil {
-		query += ` AND asset_id = ?`
+		query += ` AND transactions.asset_id = ?`
 		args = append(args, *assetID)
 	}
 
-	query += ` GROUP BY DATE(date) ORDER BY date ASC`
+	query += ` GROUP BY DATE(transactions.date) ORDER BY date ASC`
 
 	err := r.db.Raw(query, args...).Scan(&results).Error
 
@@ -197,7 +359,7 @@ func (r *analyticsRepository) GetCategoryTrend(userID uint, categoryID uint, sta
 }
 
 // GetMonthlyTrendByPayCycle returns monthly trends based on user's financial periods
-func (r *analyticsRepository) GetMonthlyTrendByPayCycle(userID uint, startDate, endDate time.Time, assetID *uint64, settings *models.UserSettings) ([]map[string]interface{}, error) {
+func (r *analyticsRepository) GetMonthlyTrendByPayCycle(userID uint, startDate, endDate time.Time, assetID *uint64, settings *models.UserSettings, baseCurrency string) ([]map[string]interface{}, error) {
 	var results []map[string]interface{}
 
 	// Get all financial periods in the date range
@@ -205,25 +367,25 @@ func (r *analyticsRepository) GetMonthlyTrendByPayCycle(userID uint, startDate,
 
 	// Query transactions for each period
 	for _, period := range periods {
-		var income, expense int64
+		var income, expense money.Amount
 
 		// Get income for this period
-		queryIncome := r.db.Model(&models.TransactionV2{}).
-			Where("user_id = ? AND transaction_type = ? AND date BETWEEN ? AND ? AND category_id != ?",
-				userID, 1, period.StartDate, period.EndDate, 18)
+		queryIncome := r.db.Table("(?) AS transactions", r.fxTransactions(userID, baseCurrency)).
+			Where("transaction_type = ? AND date BETWEEN ? AND ? AND transfer_id IS NULL AND category_id != ?",
+				1, period.StartDate, period.EndDate, legacyTransferCategoryID)
 		if assetID != nil {
 			queryIncome = queryIncome.Where("asset_id = ?", *assetID)
 		}
-		queryIncome.Select("COALESCE(SUM(amount), 0)").Scan(&income)
+		queryIncome.Select("COALESCE(ROUND(SUM(amount * fx_rate), 4), 0)").Scan(&income)
 
 		// Get expense for this period
-		queryExpense := r.db.Model(&models.TransactionV2{}).
-			Where("user_id = ? AND transaction_type = ? AND date BETWEEN ? AND ? AND category_id != ?",
-				userID, 2, period.StartDate, period.EndDate, 18)
+		queryExpense := r.db.Table("(?) AS transactions", r.fxTransactions(userID, baseCurrency)).
+			Where("transaction_type = ? AND date BETWEEN ? AND ? AND transfer_id IS NULL AND category_id != ?",
+				2, period.StartDate, period.EndDate, legacyTransferCategoryID)
 		if assetID != nil {
 			queryExpense = queryExpense.Where("asset_id = ?", *assetID)
 		}
-		queryExpense.Select("COALESCE(SUM(amount), 0)").Scan(&expense)
+		queryExpense.Select("COALESCE(ROUND(SUM(amount * fx_rate), 4), 0)").Scan(&expense)
 
 		results = append(results, map[string]interface{}{
 			"period":       period.PeriodLabel,
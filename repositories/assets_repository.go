@@ -5,6 +5,8 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"my-api/models"
+	"my-api/money"
+	"time"
 )
 
 type AssetRepository struct {
@@ -19,6 +21,17 @@ func (r *AssetRepository) CreateAsset(asset *models.Asset) error {
 	return r.DB.Create(asset).Error
 }
 
+// CreateWithOpeningBalance creates the asset and posts its starting balance
+// to the ledger
+func (r *AssetRepository) CreateWithOpeningBalance(asset *models.Asset) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(asset).Error; err != nil {
+			return err
+		}
+		return postAdjustment(tx, asset, asset.Balance, models.EntryTypeOpeningBalance, "Opening balance", time.Now())
+	})
+}
+
 func (r *AssetRepository) GetAssetsByUser(userID uint64) ([]models.Asset, error) {
 	var assets []models.Asset
 	if err := r.DB.Where("user_id = ?", userID).Find(&assets).Error; err != nil {
@@ -42,8 +55,75 @@ func (r *AssetRepository) UpdateAsset(asset *models.Asset) error {
 	return r.DB.Save(asset).Error
 }
 
+// UpdateWithAdjustment saves the asset. When balance is set, the difference
+// from the locked current balance is posted as a manual adjustment; otherwise
+// the current balance is kept so concurrent transactions are not overwritten.
+func (r *AssetRepository) UpdateWithAdjustment(asset *models.Asset, balance *money.Amount) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.Asset
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&current, "id = ?", asset.ID).Error; err != nil {
+			return err
+		}
+
+		asset.Balance = current.Balance
+		if balance != nil {
+			asset.Balance = *balance
+			if err := postAdjustment(tx, asset, *balance-current.Balance, models.EntryTypeAdjustment, "Manual balance adjustment", time.Now()); err != nil {
+				return err
+			}
+		}
+		return tx.Save(asset).Error
+	})
+}
+
+// DeleteAsset moves the asset to the trash. Its transactions stay, but are
+// left out of analytics until the asset is restored. Recurring transactions
+// posting to it are paused, so the worker stops trying to post to a wallet in
+// the trash; they have to be reactivated after a restore.
 func (r *AssetRepository) DeleteAsset(id uint64) error {
-	return r.DB.Delete(&models.Asset{}, id).Error
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deactivateRecurringTransactions(tx, []uint64{id}); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Asset{}, id).Error
+	})
+}
+
+// deactivateRecurringTransactions stops the recurring transactions of the
+// assets from being posted
+func deactivateRecurringTransactions(tx *gorm.DB, assetIDs []uint64) error {
+	return tx.Model(&models.RecurringTransaction{}).
+		Where("asset_id IN ?", assetIDs).
+		Updates(map[string]interface{}{"is_active": false, "next_occurrence": nil}).Error
+}
+
+// GetDeletedAssetsByUser lists the user's assets in the trash, most recently
+// deleted first
+func (r *AssetRepository) GetDeletedAssetsByUser(userID uint64) ([]models.Asset, error) {
+	var assets []models.Asset
+	if err := r.DB.Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC, id DESC").
+		Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// RestoreAsset takes one of the user's assets out of the trash, stamping
+// restored_at so budgets know its transactions count again
+func (r *AssetRepository) RestoreAsset(id uint64, userID uint64) error {
+	result := r.DB.Unscoped().Model(&models.Asset{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		Updates(map[string]interface{}{"deleted_at": nil, "restored_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("asset not found in trash")
+	}
+	return nil
 }
 
 func (r *AssetRepository) GetByID(id uint64) (*models.Asset, error) {
@@ -65,3 +145,27 @@ func (r *AssetRepository) GetByIDForUpdate(id uint64) (*models.Asset, error) {
 	}
 	return &asset, nil
 }
+
+// GetPendingNet returns, per asset, the net effect of its pending
+// transactions (income minus expense) that the stored balance already holds
+func (r *AssetRepository) GetPendingNet(assetIDs []uint64) (map[uint64]money.Amount, error) {
+	var rows []struct {
+		AssetID uint64
+		Net     money.Amount
+	}
+	if len(assetIDs) > 0 {
+		if err := r.DB.Model(&models.TransactionV2{}).
+			Select("asset_id, COALESCE(SUM(CASE WHEN transaction_type = 1 THEN amount ELSE -amount END), 0) AS net").
+			Where("asset_id IN ? AND status = ?", assetIDs, models.TransactionStatusPending).
+			Group("asset_id").
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	pending := make(map[uint64]money.Amount, len(rows))
+	for _, row := range rows {
+		pending[row.AssetID] = row.Net
+	}
+	return pending, nil
+}
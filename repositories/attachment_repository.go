@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"my-api/models"
+)
+
+type AttachmentRepository interface {
+	Create(attachment *models.Attachment) error
+	FindByID(id uint, userID uint) (*models.Attachment, error)
+	FindByTransaction(transactionID uint, userID uint) ([]models.Attachment, error)
+	Delete(id uint, userID uint) error
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(attachment *models.Attachment) error {
+	return r.db.Create(attachment).Error
+}
+
+func (r *attachmentRepository) FindByID(id uint, userID uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&attachment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) FindByTransaction(transactionID uint, userID uint) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.Where("transaction_id = ? AND user_id = ?", transactionID, userID).
+		Order("created_at ASC, id ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *attachmentRepository) Delete(id uint, userID uint) error {
+	return r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Attachment{}).Error
+}
@@ -1,9 +1,12 @@
 package repositories
 
 import (
+	"errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"my-api/dto"
 	"my-api/models"
+	"my-api/money"
 	"time"
 )
 
@@ -14,8 +17,19 @@ type BudgetRepository interface {
 	Update(budget *models.Budget) error
 	Delete(id uint, userID uint) error
 	FindActiveBudgets(userID uint) ([]models.Budget, error)
-	GetSpentAmount(budgetID uint, startDate, endDate time.Time) (int, error)
-	FindBudgetByCategory(userID, categoryID uint, startDate, endDate time.Time, assetID *uint64) (*models.Budget, error)
+	FindByPeriod(userID uint, period string) ([]models.Budget, error)
+	FindByUser(userID uint) ([]models.Budget, error)
+	GetSpentAmount(budgetID uint, startDate, endDate time.Time) (money.Amount, error)
+	GetDailySpending(budget *models.Budget, from, to time.Time) (map[string]money.Amount, error)
+	FindOverlappingBudget(userID uint, categoryIDs []uint, assetIDs []uint64, startDate time.Time, endDate *time.Time, excludeID uint) (*models.Budget, error)
+	FindPreviousBudget(budget *models.Budget) (*models.Budget, error)
+	FindPeriods(budgetID uint) ([]models.BudgetPeriod, error)
+	UpsertPeriods(periods []models.BudgetPeriod) error
+	DeletePeriods(ids []uint) error
+	EarliestChangeSince(userID uint, since time.Time) (*time.Time, error)
+	MarkPeriodsSynced(budgetID uint, at time.Time) error
+	CreateThresholdAlert(periodStart time.Time, alert *models.BudgetAlert) (bool, error)
+	CreateProjectedAlert(periodStart time.Time, alert *models.BudgetAlert) (bool, error)
 
 	// Budget Alerts
 	CreateAlert(alert *models.BudgetAlert) error
@@ -34,12 +48,27 @@ func NewBudgetRepository(db *gorm.DB) BudgetRepository {
 }
 
 func (r *budgetRepository) Create(budget *models.Budget) error {
-	return r.db.Create(budget).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(budget).Error; err != nil {
+			return err
+		}
+		return replaceBudgetScope(tx, budget)
+	})
+}
+
+// preloadBudgetScope loads the budget's primary category and the categories
+// and wallets it covers. Trashed ones are kept so moving a wallet to the
+// trash does not widen a budget to every wallet.
+func preloadBudgetScope(db *gorm.DB) *gorm.DB {
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+	return db.Preload("Category").
+		Preload("Categories", unscoped).
+		Preload("Assets", unscoped)
 }
 
 func (r *budgetRepository) FindByID(id uint, userID uint) (*models.Budget, error) {
 	var budget models.Budget
-	err := r.db.Preload("Category").
+	err := preloadBudgetScope(r.db).
 		Where("id = ? AND user_id = ?", id, userID).
 		First(&budget).Error
 	return &budget, err
@@ -52,7 +81,7 @@ func (r *budgetRepository) FindAll(userID uint, filter *dto.BudgetFilterRequest)
 	query := r.db.Model(&models.Budget{}).Where("user_id = ?", userID)
 
 	if filter.CategoryID != 0 {
-		query = query.Where("category_id = ?", filter.CategoryID)
+		query = query.Where("id IN (SELECT budget_id FROM budget_categories WHERE category_id = ?)", filter.CategoryID)
 	}
 	if filter.Period != "" {
 		query = query.Where("period = ?", filter.Period)
@@ -75,58 +104,252 @@ func (r *budgetRepository) FindAll(userID uint, filter *dto.BudgetFilterRequest)
 	query = query.Order(sortBy + " " + filter.SortDir)
 	query = query.Offset(filter.GetOffset()).Limit(filter.PageSize)
 
-	err := query.Preload("Category").Find(&budgets).Error
+	err := preloadBudgetScope(query).Find(&budgets).Error
 	return budgets, total, err
 }
 
 func (r *budgetRepository) Update(budget *models.Budget) error {
-	return r.db.Save(budget).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(budget).Error; err != nil {
+			return err
+		}
+		return replaceBudgetScope(tx, budget)
+	})
 }
 
 func (r *budgetRepository) Delete(id uint, userID uint) error {
 	return r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Budget{}).Error
 }
 
+// FindActiveBudgets returns the user's active budgets that have started.
+// Recurring budgets stay in the list after their first window closes.
 func (r *budgetRepository) FindActiveBudgets(userID uint) ([]models.Budget, error) {
 	var budgets []models.Budget
 	now := time.Now()
-	err := r.db.Preload("Category").
-		Where("user_id = ? AND is_active = ? AND start_date <= ? AND end_date >= ?",
-			userID, true, now, now).
+	err := preloadBudgetScope(r.db).
+		Where("user_id = ? AND is_active = ? AND start_date <= ?", userID, true, now).
+		Where("recurring = ? OR end_date >= ?", true, now).
 		Find(&budgets).Error
 	return budgets, err
 }
 
-func (r *budgetRepository) GetSpentAmount(budgetID uint, startDate, endDate time.Time) (int, error) {
+// FindByPeriod returns all of the user's budgets with the given period
+func (r *budgetRepository) FindByPeriod(userID uint, period string) ([]models.Budget, error) {
+	var budgets []models.Budget
+	err := preloadBudgetScope(r.db).
+		Where("user_id = ? AND period = ?", userID, period).
+		Find(&budgets).Error
+	return budgets, err
+}
+
+// FindByUser returns all of the user's budgets, active or not
+func (r *budgetRepository) FindByUser(userID uint) ([]models.Budget, error) {
+	var budgets []models.Budget
+	err := preloadBudgetScope(r.db).
+		Where("user_id = ?", userID).
+		Find(&budgets).Error
+	return budgets, err
+}
+
+// spendingQuery selects the expense lines in the budget's categories, on
+// its wallets when it is limited to some, leaving out transfers and
+// anything in the trash
+func (r *budgetRepository) spendingQuery(budget *models.Budget) *gorm.DB {
+	query := r.db.Table("transactions").
+		Joins(splitLinesJoin).
+		Where("transactions.user_id = ? AND "+lineCategoryColumn+" IN ? AND transactions.transaction_type = ? AND transactions.transfer_id IS NULL",
+			budget.UserID, budget.CategoryIDs(), 2).
+		Where("transactions.deleted_at IS NULL AND " + trashedAssetsCondition)
+	if assetIDs := budget.AssetIDs(); assetIDs != nil {
+		query = query.Where("transactions.asset_id IN ?", assetIDs)
+	}
+	return query
+}
+
+func (r *budgetRepository) GetSpentAmount(budgetID uint, startDate, endDate time.Time) (money.Amount, error) {
 	var budget models.Budget
-	if err := r.db.First(&budget, budgetID).Error; err != nil {
+	if err := preloadBudgetScope(r.db).First(&budget, budgetID).Error; err != nil {
 		return 0, err
 	}
 
-	var total int64
-	err := r.db.Model(&models.TransactionV2{}).
-		Where("user_id = ? AND category_id = ? AND transaction_type = ? AND date BETWEEN ? AND ?",
-			budget.UserID, budget.CategoryID, 2, startDate, endDate).
-		Select("COALESCE(SUM(amount), 0)").
+	var total money.Amount
+	err := r.spendingQuery(&budget).
+		Where("transactions.date BETWEEN ? AND ?", startDate, endDate).
+		Select("COALESCE(SUM(" + lineAmountColumn + "), 0)").
 		Scan(&total).Error
 
-	return int(total), err
+	return total, err
 }
 
-func (r *budgetRepository) FindBudgetByCategory(userID, categoryID uint, startDate, endDate time.Time, assetID *uint64) (*models.Budget, error) {
+// GetDailySpending returns what was spent against the budget on each day
+// between from and to (both inclusive) that had any spending, keyed by
+// YYYY-MM-DD
+func (r *budgetRepository) GetDailySpending(budget *models.Budget, from, to time.Time) (map[string]money.Amount, error) {
+	var rows []struct {
+		Day    string
+		Amount money.Amount
+	}
+	err := r.spendingQuery(budget).
+		Where("transactions.date >= ? AND transactions.date < ?", from, to.AddDate(0, 0, 1)).
+		Select("DATE_FORMAT(transactions.date, '%Y-%m-%d') AS day, SUM(" + lineAmountColumn + ") AS amount").
+		Group("day").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]money.Amount, len(rows))
+	for _, row := range rows {
+		days[row.Day] = row.Amount
+	}
+	return days, nil
+}
+
+// FindOverlappingBudget returns an active budget, other than excludeID, that
+// shares a category and a wallet with the given scope during the window from
+// startDate to endDate. Empty assetIDs, like a budget without wallets, cover
+// every wallet. A nil endDate means the window never ends, as for a
+// recurring budget, and existing recurring budgets overlap anything after
+// they start.
+func (r *budgetRepository) FindOverlappingBudget(userID uint, categoryIDs []uint, assetIDs []uint64, startDate time.Time, endDate *time.Time, excludeID uint) (*models.Budget, error) {
 	var budget models.Budget
-	err := r.db.Where("user_id = ? AND category_id = ? AND start_date <= ? AND end_date >= ?",
-		userID, categoryID, endDate, startDate).First(&budget).Error
+	query := r.db.Where("user_id = ? AND is_active = ? AND id <> ?", userID, true, excludeID).
+		Where("id IN (SELECT budget_id FROM budget_categories WHERE category_id IN ?)", categoryIDs).
+		Where("recurring = ? OR end_date >= ?", true, startDate)
+	if endDate != nil {
+		query = query.Where("start_date <= ?", *endDate)
+	}
+	if len(assetIDs) > 0 {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM budget_assets WHERE budget_assets.budget_id = budgets.id) OR id IN (SELECT budget_id FROM budget_assets WHERE asset_id IN ?)", assetIDs)
+	}
+	err := query.First(&budget).Error
 	return &budget, err
 }
 
+// FindPreviousBudget returns the one-off budget covering the same categories
+// and wallets whose window ends on the day before this one starts, the period
+// a one-off rollover budget carries over from. It returns nil when there is
+// none.
+func (r *budgetRepository) FindPreviousBudget(budget *models.Budget) (*models.Budget, error) {
+	var budgets []models.Budget
+	err := preloadBudgetScope(r.db).
+		Where("user_id = ? AND id <> ? AND recurring = ?", budget.UserID, budget.ID, false).
+		Where("id IN (SELECT budget_id FROM budget_categories WHERE category_id IN ?)", budget.CategoryIDs()).
+		Where("end_date >= ? AND end_date < ?", budget.StartDate.AddDate(0, 0, -1), budget.StartDate.Time).
+		Order("end_date DESC, id DESC").
+		Find(&budgets).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range budgets {
+		if budgets[i].SameScope(budget) {
+			return &budgets[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// FindPeriods lists the stored periods of the budget, oldest first
+func (r *budgetRepository) FindPeriods(budgetID uint) ([]models.BudgetPeriod, error) {
+	var periods []models.BudgetPeriod
+	err := r.db.Where("budget_id = ?", budgetID).
+		Order("start_date ASC").
+		Find(&periods).Error
+	return periods, err
+}
+
+// UpsertPeriods stores the periods, replacing the figures of any existing
+// period of the same budget and start date
+func (r *budgetRepository) UpsertPeriods(periods []models.BudgetPeriod) error {
+	if len(periods) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"end_date", "amount", "carry_in", "spent", "remaining", "updated_at"}),
+	}).CreateInBatches(periods, 500).Error
+}
+
+// DeletePeriods removes stored periods by ID
+func (r *budgetRepository) DeletePeriods(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Delete(&models.BudgetPeriod{}, ids).Error
+}
+
+// EarliestChangeSince returns the oldest date among the user's transactions
+// added, edited, deleted or restored since the given time, including those on
+// a wallet moved to or out of the trash since then, or nil when there are
+// none. Any of them may have changed what a budget period spent. Wallets are
+// matched on deleted_at and restored_at only: their updated_at moves with
+// every balance change and would mark their whole history as changed.
+func (r *budgetRepository) EarliestChangeSince(userID uint, since time.Time) (*time.Time, error) {
+	var earliest *time.Time
+	err := r.db.Table("transactions").
+		Where("transactions.user_id = ?", userID).
+		Where("(transactions.updated_at >= ? OR transactions.deleted_at >= ? OR transactions.asset_id IN (?))", since, since,
+			r.db.Table("assets").Select("id").Where("user_id = ? AND (deleted_at >= ? OR restored_at >= ?)", userID, since, since)).
+		Select("MIN(transactions.date)").
+		Scan(&earliest).Error
+	return earliest, err
+}
+
+// MarkPeriodsSynced records when the budget's stored periods were last
+// brought up to date. It leaves updated_at alone, which tells when the budget
+// itself last changed.
+func (r *budgetRepository) MarkPeriodsSynced(budgetID uint, at time.Time) error {
+	return r.db.Model(&models.Budget{}).
+		Where("id = ?", budgetID).
+		UpdateColumn("periods_synced_at", at).Error
+}
+
+// CreateThresholdAlert records that the budget period fired alert.Threshold
+// and creates the alert in one DB transaction, so a claimed threshold always
+// has its alert. It returns false when the period already fired that
+// threshold or a higher one.
+func (r *budgetRepository) CreateThresholdAlert(periodStart time.Time, alert *models.BudgetAlert) (bool, error) {
+	return r.claimAndCreateAlert(alert, func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.BudgetPeriod{}).
+			Where("budget_id = ? AND start_date = ? AND alerted_threshold < ?", alert.BudgetID, periodStart, alert.Threshold).
+			Update("alerted_threshold", alert.Threshold)
+	})
+}
+
+// CreateProjectedAlert records that the budget period fired its projected
+// overspend alert and creates the alert in one DB transaction. It returns
+// false when the period already fired it.
+func (r *budgetRepository) CreateProjectedAlert(periodStart time.Time, alert *models.BudgetAlert) (bool, error) {
+	return r.claimAndCreateAlert(alert, func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.BudgetPeriod{}).
+			Where("budget_id = ? AND start_date = ? AND projected_alerted = ?", alert.BudgetID, periodStart, false).
+			Update("projected_alerted", true)
+	})
+}
+
+// claimAndCreateAlert creates the alert when claim updates a row
+func (r *budgetRepository) claimAndCreateAlert(alert *models.BudgetAlert, claim func(tx *gorm.DB) *gorm.DB) (bool, error) {
+	claimed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := claim(tx)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		claimed = true
+		return tx.Create(alert).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
 func (r *budgetRepository) CreateAlert(alert *models.BudgetAlert) error {
 	return r.db.Create(alert).Error
 }
 
 func (r *budgetRepository) GetUserAlerts(userID uint, unreadOnly bool) ([]models.BudgetAlert, error) {
 	var alerts []models.BudgetAlert
-	query := r.db.Preload("Budget.Category").Where("user_id = ?", userID)
+	query := r.db.Preload("Budget.Category").Preload("Budget.Categories").Where("user_id = ?", userID)
 
 	if unreadOnly {
 		query = query.Where("is_read = ?", false)
@@ -160,7 +383,7 @@ func (r *budgetRepository) GetUserAlertsPaginated(userID uint, filter *dto.Alert
 	query = query.Order(sortBy + " " + filter.SortDir)
 	query = query.Offset(filter.GetOffset()).Limit(filter.PageSize)
 
-	err := query.Preload("Budget.Category").Find(&alerts).Error
+	err := query.Preload("Budget.Category").Preload("Budget.Categories").Find(&alerts).Error
 	return alerts, total, err
 }
 
@@ -175,3 +398,52 @@ func (r *budgetRepository) MarkAllAlertsAsRead(userID uint) error {
 		Where("user_id = ? AND is_read = ?", userID, false).
 		Update("is_read", true).Error
 }
+
+// replaceBudgetScope writes the budget's category and wallet links after
+// checking that every category and wallet belongs to the budget's owner
+func replaceBudgetScope(tx *gorm.DB, budget *models.Budget) error {
+	if err := tx.Exec("DELETE FROM budget_categories WHERE budget_id = ?", budget.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM budget_assets WHERE budget_id = ?", budget.ID).Error; err != nil {
+		return err
+	}
+
+	categoryIDs := budget.CategoryIDs()
+	var count int64
+	if err := tx.Unscoped().Model(&models.Category{}).
+		Where("id IN ? AND user_id = ?", categoryIDs, budget.UserID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != len(categoryIDs) {
+		return errors.New("category not found")
+	}
+
+	rows := make([]map[string]interface{}, len(categoryIDs))
+	for i, categoryID := range categoryIDs {
+		rows[i] = map[string]interface{}{"budget_id": budget.ID, "category_id": categoryID}
+	}
+	if err := tx.Table("budget_categories").Create(&rows).Error; err != nil {
+		return err
+	}
+
+	assetIDs := budget.AssetIDs()
+	if len(assetIDs) == 0 {
+		return nil
+	}
+	if err := tx.Unscoped().Model(&models.Asset{}).
+		Where("id IN ? AND user_id = ?", assetIDs, budget.UserID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != len(assetIDs) {
+		return errors.New("asset not found")
+	}
+
+	rows = make([]map[string]interface{}, len(assetIDs))
+	for i, assetID := range assetIDs {
+		rows[i] = map[string]interface{}{"budget_id": budget.ID, "asset_id": assetID}
+	}
+	return tx.Table("budget_assets").Create(&rows).Error
+}
@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"my-api/models"
+)
+
+// TransactionRuleUpdate is the change a category rule makes to one transaction
+type TransactionRuleUpdate struct {
+	TransactionID uint
+	CategoryID    *uint
+	PayeeID       *uint
+	AddTagIDs     []uint
+}
+
+type CategoryRuleRepository interface {
+	Create(rule *models.CategoryRule) error
+	FindByID(id uint, userID uint) (*models.CategoryRule, error)
+	FindAll(userID uint) ([]models.CategoryRule, error)
+	FindActive(userID uint) ([]models.CategoryRule, error)
+	Update(rule *models.CategoryRule) error
+	Delete(id uint, userID uint) error
+	CategoryExists(categoryID uint, userID uint) (bool, error)
+
+	// Transactions
+	FindTransactions(userID uint, afterID uint, limit int) ([]models.TransactionV2, error)
+	ApplyUpdates(userID uint, updates []TransactionRuleUpdate) error
+}
+
+type categoryRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRuleRepository(db *gorm.DB) CategoryRuleRepository {
+	return &categoryRuleRepository{db: db}
+}
+
+func (r *categoryRuleRepository) Create(rule *models.CategoryRule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Category", "Payee", "Tags").Create(rule).Error; err != nil {
+			return err
+		}
+		// is_active defaults to true in the database, so a false value is not inserted
+		if !rule.IsActive {
+			if err := tx.Model(rule).Update("is_active", false).Error; err != nil {
+				return err
+			}
+		}
+		return replaceRuleTags(tx, rule)
+	})
+}
+
+func (r *categoryRuleRepository) FindByID(id uint, userID uint) (*models.CategoryRule, error) {
+	var rule models.CategoryRule
+	err := r.db.Preload("Tags").
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *categoryRuleRepository) FindAll(userID uint) ([]models.CategoryRule, error) {
+	var rules []models.CategoryRule
+	err := r.db.Preload("Tags").
+		Where("user_id = ?", userID).
+		Order("priority ASC, id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *categoryRuleRepository) FindActive(userID uint) ([]models.CategoryRule, error) {
+	var rules []models.CategoryRule
+	err := r.db.Preload("Tags").
+		Where("user_id = ? AND is_active = ?", userID, true).
+		Order("priority ASC, id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *categoryRuleRepository) Update(rule *models.CategoryRule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Category", "Payee", "Tags").Save(rule).Error; err != nil {
+			return err
+		}
+		return replaceRuleTags(tx, rule)
+	})
+}
+
+func (r *categoryRuleRepository) Delete(id uint, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM category_rule_tags WHERE rule_id IN (SELECT id FROM category_rules WHERE id = ? AND user_id = ?)", id, userID).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.CategoryRule{}).Error
+	})
+}
+
+func (r *categoryRuleRepository) CategoryExists(categoryID uint, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Category{}).
+		Where("id = ? AND user_id = ?", categoryID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindTransactions pages through the user's non-transfer transactions in id
+// order with the fields rules look at. Reconciled transactions are locked and
+// left out, so rules never rewrite them.
+func (r *categoryRuleRepository) FindTransactions(userID uint, afterID uint, limit int) ([]models.TransactionV2, error) {
+	var transactions []models.TransactionV2
+	err := r.db.Preload("Category").
+		Preload("Splits").
+		Preload("Tags").
+		Where("user_id = ? AND transfer_id IS NULL AND id > ?", userID, afterID).
+		Where("status <> ?", models.TransactionStatusReconciled).
+		Order("id ASC").
+		Limit(limit).
+		Find(&transactions).Error
+	return transactions, err
+}
+
+// ApplyUpdates writes the category, payee and tag changes in one DB
+// transaction. Balances are not touched since amounts do not change.
+func (r *categoryRuleRepository) ApplyUpdates(userID uint, updates []TransactionRuleUpdate) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, update := range updates {
+			fields := map[string]interface{}{}
+			if update.CategoryID != nil {
+				fields["category_id"] = *update.CategoryID
+			}
+			if update.PayeeID != nil {
+				fields["payee_id"] = *update.PayeeID
+			}
+			if len(fields) > 0 {
+				if err := tx.Model(&models.TransactionV2{}).
+					Where("id = ? AND user_id = ? AND status <> ?", update.TransactionID, userID, models.TransactionStatusReconciled).
+					Updates(fields).Error; err != nil {
+					return err
+				}
+			}
+
+			if len(update.AddTagIDs) == 0 {
+				continue
+			}
+			rows := make([]map[string]interface{}, len(update.AddTagIDs))
+			for i, tagID := range update.AddTagIDs {
+				rows[i] = map[string]interface{}{"transaction_id": update.TransactionID, "tag_id": tagID}
+			}
+			if err := tx.Table("transaction_tags").Create(&rows).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// replaceRuleTags writes the rule's tag links after checking that every tag
+// belongs to the rule's owner
+func replaceRuleTags(tx *gorm.DB, rule *models.CategoryRule) error {
+	if err := tx.Exec("DELETE FROM category_rule_tags WHERE rule_id = ?", rule.ID).Error; err != nil {
+		return err
+	}
+	if len(rule.Tags) == 0 {
+		return nil
+	}
+
+	seen := make(map[uint]bool, len(rule.Tags))
+	var tagIDs []uint
+	for _, tag := range rule.Tags {
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			tagIDs = append(tagIDs, tag.ID)
+		}
+	}
+
+	var count int64
+	if err := tx.Model(&models.Tag{}).
+		Where("id IN ? AND user_id = ?", tagIDs, rule.UserID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != len(tagIDs) {
+		return errors.New("tag not found")
+	}
+
+	rows := make([]map[string]interface{}, len(tagIDs))
+	for i, tagID := range tagIDs {
+		rows[i] = map[string]interface{}{"rule_id": rule.ID, "tag_id": tagID}
+	}
+	return tx.Table("category_rule_tags").Create(&rows).Error
+}
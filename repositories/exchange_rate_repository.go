@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"my-api/models"
+	"time"
+)
+
+type ExchangeRateRepository interface {
+	Upsert(rates []models.ExchangeRate) error
+	FindByID(id uint, userID uint) (*models.ExchangeRate, error)
+	FindAll(userID uint, currency string, startDate, endDate *time.Time) ([]models.ExchangeRate, error)
+	FindLatest(userID uint, baseCurrency, quoteCurrency string, date time.Time) (*models.ExchangeRate, error)
+	Delete(id uint, userID uint) error
+}
+
+type exchangeRateRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeRateRepository(db *gorm.DB) ExchangeRateRepository {
+	return &exchangeRateRepository{db: db}
+}
+
+// Upsert inserts the rates, replacing any existing rate for the same pair and date
+func (r *exchangeRateRepository) Upsert(rates []models.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).CreateInBatches(rates, 200).Error
+}
+
+func (r *exchangeRateRepository) FindByID(id uint, userID uint) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&rate).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// FindAll lists rates newest first, optionally limited to pairs involving currency
+func (r *exchangeRateRepository) FindAll(userID uint, currency string, startDate, endDate *time.Time) ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	query := r.db.Where("user_id = ?", userID)
+	if currency != "" {
+		query = query.Where("base_currency = ? OR quote_currency = ?", currency, currency)
+	}
+	if startDate != nil {
+		query = query.Where("rate_date >= ?", startDate)
+	}
+	if endDate != nil {
+		query = query.Where("rate_date <= ?", endDate)
+	}
+	err := query.Order("rate_date DESC, base_currency ASC, quote_currency ASC").Find(&rates).Error
+	return rates, err
+}
+
+// FindLatest returns the rate for the pair in effect on date
+func (r *exchangeRateRepository) FindLatest(userID uint, baseCurrency, quoteCurrency string, date time.Time) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	err := r.db.Where("user_id = ? AND base_currency = ? AND quote_currency = ? AND rate_date <= ?",
+		userID, baseCurrency, quoteCurrency, date.Format("2006-01-02")).
+		Order("rate_date DESC").
+		First(&rate).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+func (r *exchangeRateRepository) Delete(id uint, userID uint) error {
+	return r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.ExchangeRate{}).Error
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"my-api/models"
+)
+
+type ImportProfileRepository interface {
+	Create(profile *models.ImportProfile) error
+	FindByID(id uint, userID uint) (*models.ImportProfile, error)
+	FindAll(userID uint) ([]models.ImportProfile, error)
+	Update(profile *models.ImportProfile) error
+	Delete(id uint, userID uint) error
+}
+
+type importProfileRepository struct {
+	db *gorm.DB
+}
+
+func NewImportProfileRepository(db *gorm.DB) ImportProfileRepository {
+	return &importProfileRepository{db: db}
+}
+
+func (r *importProfileRepository) Create(profile *models.ImportProfile) error {
+	return r.db.Create(profile).Error
+}
+
+func (r *importProfileRepository) FindByID(id uint, userID uint) (*models.ImportProfile, error) {
+	var profile models.ImportProfile
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&profile).Error
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *importProfileRepository) FindAll(userID uint) ([]models.ImportProfile, error) {
+	var profiles []models.ImportProfile
+	err := r.db.Where("user_id = ?", userID).Order("name ASC").Find(&profiles).Error
+	return profiles, err
+}
+
+func (r *importProfileRepository) Update(profile *models.ImportProfile) error {
+	return r.db.Save(profile).Error
+}
+
+func (r *importProfileRepository) Delete(id uint, userID uint) error {
+	return r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.ImportProfile{}).Error
+}
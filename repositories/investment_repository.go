@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"my-api/models"
+	"time"
+)
+
+type InvestmentRepository interface {
+	CreateInstrument(instrument *models.Instrument) error
+	UpdateInstrument(instrument *models.Instrument) error
+	DeleteInstrument(id, userID uint) error
+	FindInstrumentByID(id, userID uint) (*models.Instrument, error)
+	FindInstruments(userID uint) ([]models.Instrument, error)
+	FindInstrumentsBySymbol(userID uint, symbols []string) ([]models.Instrument, error)
+
+	CreateLot(lot *models.HoldingLot) error
+	DeleteLot(id, userID uint) error
+	FindLots(userID uint, assetID *uint64, asOf *time.Time) ([]models.HoldingLot, error)
+
+	UpsertPrices(prices []models.InstrumentPrice) error
+	FindPrices(userID, instrumentID uint, startDate, endDate *time.Time) ([]models.InstrumentPrice, error)
+	FindLatestPrices(userID uint, instrumentIDs []uint, date time.Time) (map[uint]models.InstrumentPrice, error)
+	DeletePrice(id, userID uint) error
+}
+
+type investmentRepository struct {
+	db *gorm.DB
+}
+
+func NewInvestmentRepository(db *gorm.DB) InvestmentRepository {
+	return &investmentRepository{db: db}
+}
+
+func (r *investmentRepository) CreateInstrument(instrument *models.Instrument) error {
+	return r.db.Create(instrument).Error
+}
+
+func (r *investmentRepository) UpdateInstrument(instrument *models.Instrument) error {
+	return r.db.Save(instrument).Error
+}
+
+// DeleteInstrument removes an instrument and its prices. Instruments that
+// still have lots are kept so holdings do not silently change.
+func (r *investmentRepository) DeleteInstrument(id, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var lots int64
+		if err := tx.Model(&models.HoldingLot{}).
+			Where("instrument_id = ? AND user_id = ?", id, userID).
+			Count(&lots).Error; err != nil {
+			return err
+		}
+		if lots > 0 {
+			return errors.New("instrument has holding lots")
+		}
+
+		if err := tx.Where("instrument_id = ? AND user_id = ?", id, userID).
+			Delete(&models.InstrumentPrice{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Instrument{}).Error
+	})
+}
+
+func (r *investmentRepository) FindInstrumentByID(id, userID uint) (*models.Instrument, error) {
+	var instrument models.Instrument
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&instrument).Error
+	if err != nil {
+		return nil, err
+	}
+	return &instrument, nil
+}
+
+func (r *investmentRepository) FindInstruments(userID uint) ([]models.Instrument, error) {
+	var instruments []models.Instrument
+	err := r.db.Where("user_id = ?", userID).Order("symbol ASC").Find(&instruments).Error
+	return instruments, err
+}
+
+func (r *investmentRepository) FindInstrumentsBySymbol(userID uint, symbols []string) ([]models.Instrument, error) {
+	var instruments []models.Instrument
+	if len(symbols) == 0 {
+		return instruments, nil
+	}
+	err := r.db.Where("user_id = ? AND symbol IN ?", userID, symbols).Find(&instruments).Error
+	return instruments, err
+}
+
+// CreateLot records a buy or sell in an investment asset. The asset row is
+// locked so two sells cannot both spend the same units.
+func (r *investmentRepository) CreateLot(lot *models.HoldingLot) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var asset models.Asset
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&asset, lot.AssetID).Error; err != nil {
+			return errors.New("asset not found")
+		}
+		if asset.UserID != uint64(lot.UserID) {
+			return errors.New("asset not found")
+		}
+		if asset.Type != models.AssetTypeInvestment {
+			return errors.New("holdings can only be added to investment assets")
+		}
+
+		var instrument models.Instrument
+		if err := tx.Where("id = ? AND user_id = ?", lot.InstrumentID, lot.UserID).
+			First(&instrument).Error; err != nil {
+			return errors.New("instrument not found")
+		}
+		if instrument.Currency != asset.Currency {
+			return errors.New("currency mismatch")
+		}
+
+		if lot.Side == models.LotSideSell {
+			held, err := heldQuantity(tx, lot.AssetID, lot.InstrumentID, 0)
+			if err != nil {
+				return err
+			}
+			if held < lot.Quantity {
+				return errors.New("insufficient quantity")
+			}
+		}
+
+		return tx.Create(lot).Error
+	})
+}
+
+// DeleteLot removes a lot unless that would leave more units sold than bought
+func (r *investmentRepository) DeleteLot(id, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var lot models.HoldingLot
+		if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&lot).Error; err != nil {
+			return errors.New("lot not found")
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&models.Asset{}, lot.AssetID).Error; err != nil {
+			return errors.New("asset not found")
+		}
+
+		if lot.Side == models.LotSideBuy {
+			held, err := heldQuantity(tx, lot.AssetID, lot.InstrumentID, lot.ID)
+			if err != nil {
+				return err
+			}
+			if held < 0 {
+				return errors.New("insufficient quantity")
+			}
+		}
+
+		return tx.Delete(&lot).Error
+	})
+}
+
+// heldQuantity returns the units of an instrument held in an asset, leaving
+// out the lot with ID exclude
+func heldQuantity(tx *gorm.DB, assetID uint64, instrumentID, exclude uint) (float64, error) {
+	var held float64
+	err := tx.Model(&models.HoldingLot{}).
+		Where("asset_id = ? AND instrument_id = ? AND id <> ?", assetID, instrumentID, exclude).
+		Select("COALESCE(SUM(CASE WHEN side = ? THEN quantity ELSE -quantity END), 0)", models.LotSideBuy).
+		Scan(&held).Error
+	return held, err
+}
+
+// FindLots lists lots oldest first, optionally for one asset and up to the
+// end of the asOf day
+func (r *investmentRepository) FindLots(userID uint, assetID *uint64, asOf *time.Time) ([]models.HoldingLot, error) {
+	var lots []models.HoldingLot
+	query := r.db.Preload("Instrument").Where("user_id = ?", userID)
+	if assetID != nil {
+		query = query.Where("asset_id = ?", *assetID)
+	}
+	if asOf != nil {
+		query = query.Where("date <= ?", asOf.Format("2006-01-02"))
+	}
+	err := query.Order("date ASC, id ASC").Find(&lots).Error
+	return lots, err
+}
+
+// UpsertPrices inserts the prices, replacing any existing price for the same
+// instrument and date
+func (r *investmentRepository) UpsertPrices(prices []models.InstrumentPrice) error {
+	if len(prices) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"price", "source", "updated_at"}),
+	}).CreateInBatches(prices, 200).Error
+}
+
+// FindPrices lists an instrument's prices newest first
+func (r *investmentRepository) FindPrices(userID, instrumentID uint, startDate, endDate *time.Time) ([]models.InstrumentPrice, error) {
+	var prices []models.InstrumentPrice
+	query := r.db.Where("user_id = ? AND instrument_id = ?", userID, instrumentID)
+	if startDate != nil {
+		query = query.Where("price_date >= ?", startDate.Format("2006-01-02"))
+	}
+	if endDate != nil {
+		query = query.Where("price_date <= ?", endDate.Format("2006-01-02"))
+	}
+	err := query.Order("price_date DESC").Find(&prices).Error
+	return prices, err
+}
+
+// FindLatestPrices returns the price in effect on date for each instrument
+// that has one, keyed by instrument ID
+func (r *investmentRepository) FindLatestPrices(userID uint, instrumentIDs []uint, date time.Time) (map[uint]models.InstrumentPrice, error) {
+	latest := make(map[uint]models.InstrumentPrice)
+	if len(instrumentIDs) == 0 {
+		return latest, nil
+	}
+
+	dates := r.db.Model(&models.InstrumentPrice{}).
+		Select("instrument_id, MAX(price_date) AS price_date").
+		Where("user_id = ? AND instrument_id IN ? AND price_date <= ?", userID, instrumentIDs, date.Format("2006-01-02")).
+		Group("instrument_id")
+
+	var prices []models.InstrumentPrice
+	err := r.db.Model(&models.InstrumentPrice{}).
+		Joins("JOIN (?) AS latest ON latest.instrument_id = instrument_prices.instrument_id AND latest.price_date = instrument_prices.price_date", dates).
+		Find(&prices).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, price := range prices {
+		latest[price.InstrumentID] = price
+	}
+	return latest, nil
+}
+
+func (r *investmentRepository) DeletePrice(id, userID uint) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.InstrumentPrice{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("price not found")
+	}
+	return nil
+}
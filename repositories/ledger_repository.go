@@ -0,0 +1,318 @@
+package repositories
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"my-api/dto"
+	"my-api/models"
+	"my-api/money"
+	"my-api/utils"
+	"time"
+)
+
+type LedgerRepository interface {
+	GetAssetPostings(assetID uint64, filter *dto.LedgerFilter, page, limit int) ([]models.Posting, int64, money.Amount, error)
+	GetAssetBalance(assetID uint64) (money.Amount, error)
+	GetAssetBalanceBefore(assetID uint64, before time.Time) (money.Amount, error)
+	GetAssetFlows(assetID uint64, from, to time.Time) (money.Amount, money.Amount, error)
+	GetRunningBalances(assetID uint64, transactions []models.TransactionV2) (map[uint]money.Amount, error)
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+// GetAssetPostings returns one page of the asset's postings in statement
+// order (date, then posting ID) together with the balance just before the
+// first row of that page
+func (r *ledgerRepository) GetAssetPostings(assetID uint64, filter *dto.LedgerFilter, page, limit int) ([]models.Posting, int64, money.Amount, error) {
+	var postings []models.Posting
+	var total int64
+
+	query := r.assetPostings(assetID)
+	if filter != nil && filter.StartDate != nil {
+		query = query.Where("date >= ?", *filter.StartDate)
+	}
+	if filter != nil && filter.EndDate != nil {
+		query = query.Where("date < ?", filter.EndDate.AddDate(0, 0, 1))
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Session(&gorm.Session{}).
+		Preload("Entry").
+		Order("date ASC, id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&postings).Error
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	// Opening balance = everything before the window plus the rows of the
+	// window that earlier pages already showed
+	var before money.Amount
+	if filter != nil && filter.StartDate != nil {
+		if err := r.assetPostings(assetID).
+			Where("date < ?", *filter.StartDate).
+			Select("COALESCE(SUM(amount), 0)").
+			Scan(&before).Error; err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	var skipped money.Amount
+	if offset > 0 {
+		shown := query.Session(&gorm.Session{}).
+			Select("amount").
+			Order("date ASC, id ASC").
+			Limit(offset)
+		if err := r.db.Table("(?) AS shown", shown).
+			Select("COALESCE(SUM(amount), 0)").
+			Scan(&skipped).Error; err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	return postings, total, before + skipped, nil
+}
+
+// GetAssetBalance returns the balance the ledger holds for the asset
+func (r *ledgerRepository) GetAssetBalance(assetID uint64) (money.Amount, error) {
+	var balance money.Amount
+	err := r.assetPostings(assetID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&balance).Error
+	return balance, err
+}
+
+// GetAssetBalanceBefore returns the ledger balance of the asset from the
+// postings dated before the given time
+func (r *ledgerRepository) GetAssetBalanceBefore(assetID uint64, before time.Time) (money.Amount, error) {
+	var balance money.Amount
+	err := r.assetPostings(assetID).
+		Where("date < ?", before).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&balance).Error
+	return balance, err
+}
+
+// GetAssetFlows returns the money that went into and out of the asset
+// between from (inclusive) and to (exclusive). Both totals are positive.
+func (r *ledgerRepository) GetAssetFlows(assetID uint64, from, to time.Time) (money.Amount, money.Amount, error) {
+	var flows struct {
+		In  money.Amount
+		Out money.Amount
+	}
+	err := r.assetPostings(assetID).
+		Where("date >= ? AND date < ?", from, to).
+		Select("COALESCE(SUM(CASE WHEN amount > 0 THEN amount ELSE 0 END), 0) AS `in`, " +
+			"COALESCE(SUM(CASE WHEN amount < 0 THEN -amount ELSE 0 END), 0) AS `out`").
+		Scan(&flows).Error
+	return flows.In, flows.Out, err
+}
+
+// statementOrderKey places a posting within its date: postings of a
+// transaction (or of the transfer leg it belongs to) sort by that transaction
+// ID, and opening balances and adjustments come first
+const statementOrderKey = "COALESCE(journal_entries.transaction_id, " +
+	"CASE WHEN transfers.source_asset_id = postings.asset_id THEN transfers.source_transaction_id ELSE transfers.target_transaction_id END, 0)"
+
+// GetRunningBalances returns the asset's balance just after each of the given
+// transactions, keyed by transaction ID. Transactions are ordered by date and
+// then ID, so a back-dated transaction changes the balances of every later
+// row. The transactions need not be adjacent, which keeps it right for any
+// page of a list.
+func (r *ledgerRepository) GetRunningBalances(assetID uint64, transactions []models.TransactionV2) (map[uint]money.Amount, error) {
+	balances := make(map[uint]money.Amount, len(transactions))
+	if len(transactions) == 0 {
+		return balances, nil
+	}
+
+	type position struct {
+		date int64
+		id   uint
+	}
+	wanted := make(map[position]uint, len(transactions))
+	first, last := transactions[0], transactions[0]
+	for _, t := range transactions {
+		wanted[position{t.Date.Unix(), t.ID}] = t.ID
+		if t.Date.Before(first.Date.Time) || (t.Date.Equal(first.Date.Time) && t.ID < first.ID) {
+			first = t
+		}
+		if t.Date.After(last.Date.Time) || (t.Date.Equal(last.Date.Time) && t.ID > last.ID) {
+			last = t
+		}
+	}
+
+	postings := func() *gorm.DB {
+		return r.db.Table("postings").
+			Joins("JOIN journal_entries ON journal_entries.id = postings.entry_id").
+			Joins("LEFT JOIN transfers ON transfers.id = journal_entries.transfer_id").
+			Where("postings.account = ? AND postings.asset_id = ?", models.AccountAsset, assetID)
+	}
+
+	var balance money.Amount
+	if err := postings().
+		Where("(postings.date, "+statementOrderKey+") < (?, ?)", first.Date.Time, first.ID).
+		Select("COALESCE(SUM(postings.amount), 0)").
+		Scan(&balance).Error; err != nil {
+		return nil, err
+	}
+
+	var steps []struct {
+		PostedAt time.Time
+		TxnKey   uint
+		Amount   money.Amount
+	}
+	if err := postings().
+		Where("(postings.date, "+statementOrderKey+") >= (?, ?)", first.Date.Time, first.ID).
+		Where("(postings.date, "+statementOrderKey+") <= (?, ?)", last.Date.Time, last.ID).
+		Select("postings.date AS posted_at, " + statementOrderKey + " AS txn_key, SUM(postings.amount) AS amount").
+		Group("posted_at, txn_key").
+		Order("posted_at ASC, txn_key ASC").
+		Scan(&steps).Error; err != nil {
+		return nil, err
+	}
+
+	for _, step := range steps {
+		balance += step.Amount
+		if id, ok := wanted[position{step.PostedAt.Unix(), step.TxnKey}]; ok {
+			balances[id] = balance
+		}
+	}
+	return balances, nil
+}
+
+func (r *ledgerRepository) assetPostings(assetID uint64) *gorm.DB {
+	return r.db.Model(&models.Posting{}).
+		Where("account = ? AND asset_id = ?", models.AccountAsset, assetID)
+}
+
+// createEntry stores a journal entry with its postings. Every posting takes
+// the entry's user and date, and the amounts must add up to zero.
+func createEntry(tx *gorm.DB, entry *models.JournalEntry) error {
+	var sum money.Amount
+	for i := range entry.Postings {
+		entry.Postings[i].UserID = entry.UserID
+		entry.Postings[i].Date = entry.Date
+		sum += entry.Postings[i].Amount
+	}
+	if sum != 0 {
+		return errors.New("unbalanced journal entry")
+	}
+	return tx.Create(entry).Error
+}
+
+// postTransaction records a wallet transaction against its asset and the
+// income or expense account
+func postTransaction(tx *gorm.DB, transaction *models.TransactionV2, asset *models.Asset) error {
+	amount, nominal := transaction.Amount, models.AccountIncome
+	if transaction.TransactionType != 1 {
+		amount, nominal = -transaction.Amount, models.AccountExpense
+	}
+
+	transactionID, assetID := transaction.ID, asset.ID
+	return createEntry(tx, &models.JournalEntry{
+		UserID:        transaction.UserID,
+		EntryType:     models.EntryTypeTransaction,
+		TransactionID: &transactionID,
+		AssetID:       &assetID,
+		Description:   transaction.Description,
+		Date:          transaction.Date,
+		Postings: []models.Posting{
+			{Account: models.AccountAsset, AssetID: &assetID, Amount: amount, Currency: asset.Currency},
+			{Account: nominal, Amount: -amount, Currency: asset.Currency},
+		},
+	})
+}
+
+// postTransfer records a transfer as one entry moving the amount between the
+// two assets
+func postTransfer(tx *gorm.DB, transfer *models.Transfer, source, target *models.Asset) error {
+	transferID, sourceID, targetID := transfer.ID, source.ID, target.ID
+	return createEntry(tx, &models.JournalEntry{
+		UserID:      transfer.UserID,
+		EntryType:   models.EntryTypeTransfer,
+		TransferID:  &transferID,
+		Description: transfer.Description,
+		Date:        transfer.Date,
+		Postings: []models.Posting{
+			{Account: models.AccountAsset, AssetID: &sourceID, Amount: -transfer.Amount, Currency: source.Currency},
+			{Account: models.AccountAsset, AssetID: &targetID, Amount: transfer.Amount, Currency: target.Currency},
+		},
+	})
+}
+
+// postAdjustment records a change to an asset's balance that no transaction
+// explains, such as an opening balance or a manual correction, against equity
+func postAdjustment(tx *gorm.DB, asset *models.Asset, delta money.Amount, entryType, description string, date time.Time) error {
+	if delta == 0 {
+		return nil
+	}
+
+	assetID := asset.ID
+	return createEntry(tx, &models.JournalEntry{
+		UserID:      uint(asset.UserID),
+		EntryType:   entryType,
+		AssetID:     &assetID,
+		Description: description,
+		Date:        utils.CustomTime{Time: date},
+		Postings: []models.Posting{
+			{Account: models.AccountAsset, AssetID: &assetID, Amount: delta, Currency: asset.Currency},
+			{Account: models.AccountEquity, Amount: -delta, Currency: asset.Currency},
+		},
+	})
+}
+
+// reverseEntries undoes every live entry matching the condition with a
+// reversal entry dated like the original, so historical balances stay right
+func reverseEntries(tx *gorm.DB, query interface{}, args ...interface{}) error {
+	var entries []models.JournalEntry
+	if err := tx.Preload("Postings").
+		Where(query, args...).
+		Where("reversed_at IS NULL AND entry_type <> ?", models.EntryTypeReversal).
+		Find(&entries).Error; err != nil {
+		return err
+	}
+
+	now := utils.CustomTime{Time: time.Now()}
+	for _, original := range entries {
+		originalID := original.ID
+		reversal := &models.JournalEntry{
+			UserID:        original.UserID,
+			EntryType:     models.EntryTypeReversal,
+			TransactionID: original.TransactionID,
+			TransferID:    original.TransferID,
+			AssetID:       original.AssetID,
+			ReversesID:    &originalID,
+			Description:   original.Description,
+			Date:          original.Date,
+		}
+		for _, posting := range original.Postings {
+			reversal.Postings = append(reversal.Postings, models.Posting{
+				Account:  posting.Account,
+				AssetID:  posting.AssetID,
+				Amount:   -posting.Amount,
+				Currency: posting.Currency,
+			})
+		}
+		if err := createEntry(tx, reversal); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.JournalEntry{}).
+			Where("id = ?", original.ID).
+			Update("reversed_at", now).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
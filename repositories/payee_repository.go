@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"my-api/models"
+)
+
+type PayeeRepository interface {
+	Create(payee *models.Payee) error
+	FindByID(id uint, userID uint) (*models.Payee, error)
+	FindByName(name string, userID uint) (*models.Payee, error)
+	FindAll(userID uint, search string) ([]models.Payee, error)
+	Update(payee *models.Payee) error
+	Delete(id uint, userID uint) error
+
+	// Rules
+	CreateRule(rule *models.PayeeRule) error
+	FindRuleByID(id uint, userID uint) (*models.PayeeRule, error)
+	FindRules(userID uint) ([]models.PayeeRule, error)
+	UpdateRule(rule *models.PayeeRule) error
+	DeleteRule(id uint, userID uint) error
+
+	// Transactions
+	FindUnassignedTransactions(userID uint, afterID uint, limit int) ([]models.TransactionV2, error)
+	AssignPayee(transactionIDs []uint, payeeID uint) error
+}
+
+type payeeRepository struct {
+	db *gorm.DB
+}
+
+func NewPayeeRepository(db *gorm.DB) PayeeRepository {
+	return &payeeRepository{db: db}
+}
+
+func (r *payeeRepository) Create(payee *models.Payee) error {
+	return r.db.Omit("Rules").Create(payee).Error
+}
+
+func (r *payeeRepository) FindByID(id uint, userID uint) (*models.Payee, error) {
+	var payee models.Payee
+	err := r.db.Preload("Rules", func(db *gorm.DB) *gorm.DB {
+		return db.Order("priority ASC, id ASC")
+	}).Where("id = ? AND user_id = ?", id, userID).First(&payee).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payee, nil
+}
+
+func (r *payeeRepository) FindByName(name string, userID uint) (*models.Payee, error) {
+	var payee models.Payee
+	err := r.db.Where("name = ? AND user_id = ?", name, userID).First(&payee).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payee, nil
+}
+
+func (r *payeeRepository) FindAll(userID uint, search string) ([]models.Payee, error) {
+	var payees []models.Payee
+	query := r.db.Where("user_id = ?", userID)
+	if search != "" {
+		query = query.Where("name LIKE ?", "%"+search+"%")
+	}
+	err := query.Preload("Rules", func(db *gorm.DB) *gorm.DB {
+		return db.Order("priority ASC, id ASC")
+	}).Order("name ASC").Find(&payees).Error
+	return payees, err
+}
+
+func (r *payeeRepository) Update(payee *models.Payee) error {
+	return r.db.Omit("Rules").Save(payee).Error
+}
+
+// Delete removes the payee and its rules and unlinks its transactions
+func (r *payeeRepository) Delete(id uint, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.TransactionV2{}).
+			Where("payee_id = ? AND user_id = ?", id, userID).
+			Update("payee_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("payee_id = ? AND user_id = ?", id, userID).
+			Delete(&models.PayeeRule{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Payee{}).Error
+	})
+}
+
+func (r *payeeRepository) CreateRule(rule *models.PayeeRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *payeeRepository) FindRuleByID(id uint, userID uint) (*models.PayeeRule, error) {
+	var rule models.PayeeRule
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *payeeRepository) FindRules(userID uint) ([]models.PayeeRule, error) {
+	var rules []models.PayeeRule
+	err := r.db.Where("user_id = ?", userID).
+		Order("priority ASC, id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *payeeRepository) UpdateRule(rule *models.PayeeRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *payeeRepository) DeleteRule(id uint, userID uint) error {
+	return r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.PayeeRule{}).Error
+}
+
+// FindUnassignedTransactions pages through transactions without a payee in id order
+func (r *payeeRepository) FindUnassignedTransactions(userID uint, afterID uint, limit int) ([]models.TransactionV2, error) {
+	var transactions []models.TransactionV2
+	err := r.db.Select("id, description").
+		Where("user_id = ? AND payee_id IS NULL AND transfer_id IS NULL AND id > ?", userID, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&transactions).Error
+	return transactions, err
+}
+
+func (r *payeeRepository) AssignPayee(transactionIDs []uint, payeeID uint) error {
+	if len(transactionIDs) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.TransactionV2{}).
+		Where("id IN ?", transactionIDs).
+		Update("payee_id", payeeID).Error
+}
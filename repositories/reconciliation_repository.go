@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"my-api/models"
+	"my-api/money"
+	"time"
+)
+
+type ReconciliationRepository interface {
+	FindAssets(userID *uint, assetIDs []uint64) ([]models.Asset, error)
+	GetExpectedBalance(assetID uint64, asOf *time.Time) (money.Amount, error)
+	AdjustToStoredBalance(assetID uint64) (money.Amount, error)
+	CreateWithAdjustment(reconciliation *models.Reconciliation, adjust bool) (int64, error)
+	FindByAsset(assetID uint64, userID uint) ([]models.Reconciliation, error)
+}
+
+type reconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepository(db *gorm.DB) ReconciliationRepository {
+	return &reconciliationRepository{db: db}
+}
+
+// FindAssets returns the assets to check, optionally limited to some IDs. A
+// nil userID means every user's assets, which only the reconcile command asks
+// for.
+func (r *reconciliationRepository) FindAssets(userID *uint, assetIDs []uint64) ([]models.Asset, error) {
+	var assets []models.Asset
+	query := r.db.Model(&models.Asset{})
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if len(assetIDs) > 0 {
+		query = query.Where("id IN ?", assetIDs)
+	}
+	err := query.Order("user_id ASC, id ASC").Find(&assets).Error
+	return assets, err
+}
+
+func (r *reconciliationRepository) GetExpectedBalance(assetID uint64, asOf *time.Time) (money.Amount, error) {
+	return expectedBalance(r.db, assetID, asOf, false)
+}
+
+// expectedBalance recomputes an asset's balance from its opening balance and
+// manual adjustments plus income minus expenses, up to and including the
+// asOf day when one is given. clearedOnly leaves out pending transactions,
+// which a bank statement does not show yet.
+func expectedBalance(db *gorm.DB, assetID uint64, asOf *time.Time, clearedOnly bool) (money.Amount, error) {
+	adjustments := db.Table("postings").
+		Joins("JOIN journal_entries ON journal_entries.id = postings.entry_id").
+		Where("postings.account = ? AND postings.asset_id = ?", models.AccountAsset, assetID).
+		Where("journal_entries.entry_type IN ?", []string{models.EntryTypeOpeningBalance, models.EntryTypeAdjustment})
+	transactions := db.Model(&models.TransactionV2{}).
+		Where("asset_id = ?", assetID)
+	if asOf != nil {
+		cutoff := asOf.AddDate(0, 0, 1)
+		adjustments = adjustments.Where("postings.date < ?", cutoff)
+		transactions = transactions.Where("date < ?", cutoff)
+	}
+	if clearedOnly {
+		transactions = transactions.Where("status <> ?", models.TransactionStatusPending)
+	}
+
+	var opening, net money.Amount
+	if err := adjustments.Select("COALESCE(SUM(postings.amount), 0)").Scan(&opening).Error; err != nil {
+		return 0, err
+	}
+	if err := transactions.
+		Select("COALESCE(SUM(CASE WHEN transaction_type = 1 THEN amount ELSE -amount END), 0)").
+		Scan(&net).Error; err != nil {
+		return 0, err
+	}
+	return opening + net, nil
+}
+
+// AdjustToStoredBalance posts whatever part of the stored balance the
+// history does not explain as an adjustment, so the recomputed balance
+// matches it again. It returns the amount posted.
+func (r *reconciliationRepository) AdjustToStoredBalance(assetID uint64) (money.Amount, error) {
+	var delta money.Amount
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var asset models.Asset
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&asset, assetID).Error; err != nil {
+			return errors.New("asset not found")
+		}
+
+		expected, err := expectedBalance(tx, assetID, nil, false)
+		if err != nil {
+			return err
+		}
+		delta = asset.Balance - expected
+		return postAdjustment(tx, &asset, delta, models.EntryTypeAdjustment, "Reconciliation adjustment", time.Now())
+	})
+	return delta, err
+}
+
+// CreateWithAdjustment stores a statement reconciliation. ComputedBalance is
+// filled in from the cleared history as of the statement date. When it
+// differs from the statement, the difference is posted to the wallet at the
+// end of that day if adjust is set, and the reconciliation is rejected
+// otherwise. Cleared transactions up to the statement date become reconciled;
+// their number is returned.
+func (r *reconciliationRepository) CreateWithAdjustment(reconciliation *models.Reconciliation, adjust bool) (int64, error) {
+	var reconciled int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var asset models.Asset
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&asset, reconciliation.AssetID).Error; err != nil {
+			return errors.New("asset not found")
+		}
+		if asset.UserID != uint64(reconciliation.UserID) {
+			return errors.New("asset not found")
+		}
+
+		statementDate := reconciliation.StatementDate.Time
+		computed, err := expectedBalance(tx, asset.ID, &statementDate, true)
+		if err != nil {
+			return err
+		}
+		reconciliation.ComputedBalance = computed
+
+		difference := reconciliation.StatementBalance - computed
+		if difference != 0 {
+			if !adjust {
+				return fmt.Errorf("statement balance differs from the computed balance by %s", difference)
+			}
+
+			endOfDay := statementDate.AddDate(0, 0, 1).Add(-time.Second)
+			if err := postAdjustment(tx, &asset, difference, models.EntryTypeAdjustment, "Statement reconciliation adjustment", endOfDay); err != nil {
+				return err
+			}
+			asset.Balance += difference
+			if err := tx.Save(&asset).Error; err != nil {
+				return err
+			}
+			reconciliation.Adjustment = difference
+		}
+
+		if err := tx.Create(reconciliation).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.TransactionV2{}).
+			Where("asset_id = ? AND status = ? AND date < ?", asset.ID, models.TransactionStatusCleared, statementDate.AddDate(0, 0, 1)).
+			Update("status", models.TransactionStatusReconciled)
+		reconciled = result.RowsAffected
+		return result.Error
+	})
+	return reconciled, err
+}
+
+func (r *reconciliationRepository) FindByAsset(assetID uint64, userID uint) ([]models.Reconciliation, error) {
+	var reconciliations []models.Reconciliation
+	err := r.db.
+		Where("asset_id = ? AND user_id = ?", assetID, userID).
+		Order("statement_date DESC, id DESC").
+		Find(&reconciliations).Error
+	return reconciliations, err
+}
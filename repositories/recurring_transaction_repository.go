@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"my-api/dto"
+	"my-api/models"
+	"time"
+)
+
+type RecurringTransactionRepository interface {
+	Create(recurring *models.RecurringTransaction) error
+	FindByID(id uint, userID uint) (*models.RecurringTransaction, error)
+	FindAll(userID uint, filter *dto.RecurringTransactionFilterRequest) ([]models.RecurringTransaction, int64, error)
+	Update(recurring *models.RecurringTransaction) error
+	Delete(id uint, userID uint) error
+	FindDue(now time.Time) ([]models.RecurringTransaction, error)
+
+	// Occurrences
+	ClaimOccurrence(occurrence *models.RecurringOccurrence) (bool, error)
+	PostOccurrence(occurrence *models.RecurringOccurrence, transaction *models.TransactionV2, create func(tx *gorm.DB, transaction *models.TransactionV2) error) (bool, error)
+	UpdateOccurrence(occurrence *models.RecurringOccurrence) error
+	FindOccurrence(recurringID uint, date time.Time) (*models.RecurringOccurrence, error)
+	GetOccurrences(recurringID uint, startDate, endDate time.Time) ([]models.RecurringOccurrence, error)
+}
+
+// recurringSortColumns maps the sort_by values FindAll accepts to columns, so
+// user input never reaches ORDER BY
+var recurringSortColumns = map[string]string{
+	"date":          "start_date",
+	"amount":        "amount",
+	"next_run_date": "next_occurrence",
+	"created_at":    "created_at",
+}
+
+type recurringTransactionRepository struct {
+	db *gorm.DB
+}
+
+func NewRecurringTransactionRepository(db *gorm.DB) RecurringTransactionRepository {
+	return &recurringTransactionRepository{db: db}
+}
+
+func (r *recurringTransactionRepository) Create(recurring *models.RecurringTransaction) error {
+	return r.db.Create(recurring).Error
+}
+
+func (r *recurringTransactionRepository) FindByID(id uint, userID uint) (*models.RecurringTransaction, error) {
+	var recurring models.RecurringTransaction
+	err := r.db.Preload("Category").Preload("Asset").
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&recurring).Error
+	if err != nil {
+		return nil, err
+	}
+	return &recurring, nil
+}
+
+func (r *recurringTransactionRepository) FindAll(userID uint, filter *dto.RecurringTransactionFilterRequest) ([]models.RecurringTransaction, int64, error) {
+	var recurrings []models.RecurringTransaction
+	var total int64
+
+	query := r.db.Model(&models.RecurringTransaction{}).Where("user_id = ?", userID)
+
+	if filter.AssetID != 0 {
+		query = query.Where("asset_id = ?", filter.AssetID)
+	}
+	if filter.Frequency != "" {
+		query = query.Where("frequency = ?", filter.Frequency)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.Search != "" {
+		query = query.Where("description LIKE ?", "%"+filter.Search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	sortBy, ok := recurringSortColumns[filter.SortBy]
+	if !ok {
+		sortBy = "created_at"
+	}
+	sortDir := "DESC"
+	if filter.SortDir == "asc" {
+		sortDir = "ASC"
+	}
+	query = query.Order(sortBy + " " + sortDir)
+	query = query.Offset(filter.GetOffset()).Limit(filter.PageSize)
+
+	err := query.Preload("Category").Preload("Asset").Find(&recurrings).Error
+	return recurrings, total, err
+}
+
+func (r *recurringTransactionRepository) Update(recurring *models.RecurringTransaction) error {
+	return r.db.Save(recurring).Error
+}
+
+func (r *recurringTransactionRepository) Delete(id uint, userID uint) error {
+	return r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.RecurringTransaction{}).Error
+}
+
+// FindDue returns active recurring transactions whose next occurrence is on or before now
+func (r *recurringTransactionRepository) FindDue(now time.Time) ([]models.RecurringTransaction, error) {
+	var recurrings []models.RecurringTransaction
+	err := r.db.Where("is_active = ? AND next_occurrence IS NOT NULL AND next_occurrence <= ?", true, now).
+		Order("next_occurrence ASC").
+		Find(&recurrings).Error
+	return recurrings, err
+}
+
+// ClaimOccurrence inserts the occurrence unless one already exists for the same
+// date. It returns false when the date was already posted, skipped or claimed.
+func (r *recurringTransactionRepository) ClaimOccurrence(occurrence *models.RecurringOccurrence) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(occurrence)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// PostOccurrence claims the occurrence, creates its transaction with create
+// and records the outcome on the occurrence in one DB transaction, so an
+// occurrence is never left claimed without a result. The transaction is
+// created in a savepoint: when it fails, the occurrence is stored as failed
+// with the reason. A failed occurrence can be claimed again, so it is retried
+// the next time it is posted. It returns false when the date was already
+// posted, skipped or claimed.
+func (r *recurringTransactionRepository) PostOccurrence(occurrence *models.RecurringOccurrence, transaction *models.TransactionV2, create func(tx *gorm.DB, transaction *models.TransactionV2) error) (bool, error) {
+	claimed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(occurrence)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			reclaimed, err := reclaimFailedOccurrence(tx, occurrence)
+			if err != nil || !reclaimed {
+				return err
+			}
+		}
+		claimed = true
+
+		if err := tx.Transaction(func(tx *gorm.DB) error {
+			return create(tx, transaction)
+		}); err != nil {
+			occurrence.Status = models.OccurrenceStatusFailed
+			occurrence.Message = err.Error()
+		} else {
+			occurrence.Status = models.OccurrenceStatusPosted
+			occurrence.TransactionID = &transaction.ID
+		}
+		return tx.Save(occurrence).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
+// reclaimFailedOccurrence moves a failed occurrence for the same date back to
+// pending and loads it into occurrence. It returns false when the existing
+// occurrence is not failed.
+func reclaimFailedOccurrence(tx *gorm.DB, occurrence *models.RecurringOccurrence) (bool, error) {
+	result := tx.Model(&models.RecurringOccurrence{}).
+		Where("recurring_transaction_id = ? AND occurrence_date = ? AND status = ?",
+			occurrence.RecurringTransactionID, occurrence.OccurrenceDate.Time, models.OccurrenceStatusFailed).
+		Updates(map[string]interface{}{"status": models.OccurrenceStatusPending, "message": ""})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	err := tx.Where("recurring_transaction_id = ? AND occurrence_date = ?",
+		occurrence.RecurringTransactionID, occurrence.OccurrenceDate.Time).
+		First(occurrence).Error
+	return err == nil, err
+}
+
+func (r *recurringTransactionRepository) UpdateOccurrence(occurrence *models.RecurringOccurrence) error {
+	return r.db.Save(occurrence).Error
+}
+
+func (r *recurringTransactionRepository) FindOccurrence(recurringID uint, date time.Time) (*models.RecurringOccurrence, error) {
+	var occurrence models.RecurringOccurrence
+	err := r.db.Where("recurring_transaction_id = ? AND occurrence_date = ?", recurringID, date).
+		First(&occurrence).Error
+	if err != nil {
+		return nil, err
+	}
+	return &occurrence, nil
+}
+
+func (r *recurringTransactionRepository) GetOccurrences(recurringID uint, startDate, endDate time.Time) ([]models.RecurringOccurrence, error) {
+	var occurrences []models.RecurringOccurrence
+	err := r.db.Where("recurring_transaction_id = ? AND occurrence_date BETWEEN ? AND ?", recurringID, startDate, endDate).
+		Order("occurrence_date ASC").
+		Find(&occurrences).Error
+	return occurrences, err
+}
@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"my-api/models"
+	"my-api/money"
+	"time"
+)
+
+type SnapshotRepository interface {
+	FindAssets(userID *uint) ([]models.Asset, error)
+	LastSnapshotDates(assetIDs []uint64) (map[uint64]time.Time, error)
+	EarliestChangedDates(assetIDs []uint64) (map[uint64]time.Time, error)
+	FirstPostingDate(assetID uint64) (*time.Time, error)
+	DailyBalances(assetID uint64, from, to time.Time) (money.Amount, map[string]money.Amount, error)
+	Upsert(snapshots []models.BalanceSnapshot) error
+	FindLatestBefore(userID uint, date time.Time) ([]models.BalanceSnapshot, error)
+	FindBetween(userID uint, start, end time.Time) ([]models.BalanceSnapshot, error)
+}
+
+type snapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewSnapshotRepository(db *gorm.DB) SnapshotRepository {
+	return &snapshotRepository{db: db}
+}
+
+// FindAssets returns the assets to snapshot. A nil userID means every user's
+// assets.
+func (r *snapshotRepository) FindAssets(userID *uint) ([]models.Asset, error) {
+	var assets []models.Asset
+	query := r.db.Model(&models.Asset{})
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	err := query.Order("user_id ASC, id ASC").Find(&assets).Error
+	return assets, err
+}
+
+// LastSnapshotDates returns the date of each asset's newest snapshot, for the
+// assets that have one
+func (r *snapshotRepository) LastSnapshotDates(assetIDs []uint64) (map[uint64]time.Time, error) {
+	dates := make(map[uint64]time.Time)
+	if len(assetIDs) == 0 {
+		return dates, nil
+	}
+
+	var rows []struct {
+		AssetID      uint64
+		SnapshotDate time.Time
+	}
+	err := r.db.Model(&models.BalanceSnapshot{}).
+		Select("asset_id, MAX(snapshot_date) AS snapshot_date").
+		Where("asset_id IN ?", assetIDs).
+		Group("asset_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		dates[row.AssetID] = row.SnapshotDate
+	}
+	return dates, nil
+}
+
+// EarliestChangedDates returns, for the assets with snapshots, the oldest
+// date among the asset postings recorded since their newest snapshot was
+// written. The ledger is append-only, so every added, edited, deleted or
+// restored transaction shows up here with the date it affects.
+func (r *snapshotRepository) EarliestChangedDates(assetIDs []uint64) (map[uint64]time.Time, error) {
+	dates := make(map[uint64]time.Time)
+	if len(assetIDs) == 0 {
+		return dates, nil
+	}
+
+	written := r.db.Model(&models.BalanceSnapshot{}).
+		Select("asset_id, MAX(updated_at) AS written_at").
+		Where("asset_id IN ?", assetIDs).
+		Group("asset_id")
+
+	var rows []struct {
+		AssetID     uint64
+		PostingDate time.Time
+	}
+	err := r.db.Model(&models.Posting{}).
+		Select("postings.asset_id, MIN(postings.date) AS posting_date").
+		Joins("JOIN (?) AS written ON written.asset_id = postings.asset_id", written).
+		Where("postings.account = ? AND postings.created_at >= written.written_at", models.AccountAsset).
+		Group("postings.asset_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		dates[row.AssetID] = row.PostingDate
+	}
+	return dates, nil
+}
+
+// FirstPostingDate returns the date of the asset's oldest ledger posting, or
+// nil when it has none
+func (r *snapshotRepository) FirstPostingDate(assetID uint64) (*time.Time, error) {
+	var first *time.Time
+	err := r.db.Model(&models.Posting{}).
+		Select("MIN(date)").
+		Where("account = ? AND asset_id = ?", models.AccountAsset, assetID).
+		Scan(&first).Error
+	return first, err
+}
+
+// DailyBalances returns the asset's ledger balance before from, and the net
+// of its postings for each day between from and to (both inclusive) that had
+// any, keyed by YYYY-MM-DD
+func (r *snapshotRepository) DailyBalances(assetID uint64, from, to time.Time) (money.Amount, map[string]money.Amount, error) {
+	postings := func() *gorm.DB {
+		return r.db.Model(&models.Posting{}).
+			Where("account = ? AND asset_id = ?", models.AccountAsset, assetID)
+	}
+
+	var opening money.Amount
+	if err := postings().
+		Where("date < ?", from).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&opening).Error; err != nil {
+		return 0, nil, err
+	}
+
+	var rows []struct {
+		Day    string
+		Amount money.Amount
+	}
+	if err := postings().
+		Where("date >= ? AND date < ?", from, to.AddDate(0, 0, 1)).
+		Select("DATE_FORMAT(date, '%Y-%m-%d') AS day, SUM(amount) AS amount").
+		Group("day").
+		Scan(&rows).Error; err != nil {
+		return 0, nil, err
+	}
+
+	days := make(map[string]money.Amount, len(rows))
+	for _, row := range rows {
+		days[row.Day] = row.Amount
+	}
+	return opening, days, nil
+}
+
+// Upsert inserts the snapshots, replacing any existing snapshot of the same
+// asset and day
+func (r *snapshotRepository) Upsert(snapshots []models.BalanceSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"balance", "holdings_value", "currency", "updated_at"}),
+	}).CreateInBatches(snapshots, 500).Error
+}
+
+// FindLatestBefore returns each asset's newest snapshot dated before date.
+// Like FindBetween it skips assets that no longer exist.
+func (r *snapshotRepository) FindLatestBefore(userID uint, date time.Time) ([]models.BalanceSnapshot, error) {
+	dates := r.db.Model(&models.BalanceSnapshot{}).
+		Select("asset_id, MAX(snapshot_date) AS snapshot_date").
+		Where("user_id = ? AND snapshot_date < ?", userID, date.Format("2006-01-02")).
+		Where("asset_id IN (?)", r.db.Model(&models.Asset{}).Select("id").Where("user_id = ?", userID)).
+		Group("asset_id")
+
+	var snapshots []models.BalanceSnapshot
+	err := r.db.Model(&models.BalanceSnapshot{}).
+		Joins("JOIN (?) AS latest ON latest.asset_id = balance_snapshots.asset_id AND latest.snapshot_date = balance_snapshots.snapshot_date", dates).
+		Find(&snapshots).Error
+	return snapshots, err
+}
+
+// FindBetween lists the user's snapshots from start to end (both inclusive),
+// oldest first. Snapshots of deleted assets are left out so they do not
+// linger in net worth.
+func (r *snapshotRepository) FindBetween(userID uint, start, end time.Time) ([]models.BalanceSnapshot, error) {
+	var snapshots []models.BalanceSnapshot
+	err := r.db.
+		Where("user_id = ? AND snapshot_date >= ? AND snapshot_date <= ?",
+			userID, start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Where("asset_id IN (?)", r.db.Model(&models.Asset{}).Select("id").Where("user_id = ?", userID)).
+		Order("snapshot_date ASC, asset_id ASC").
+		Find(&snapshots).Error
+	return snapshots, err
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"my-api/models"
+)
+
+type TagRepository interface {
+	Create(tag *models.Tag) error
+	FindByID(id uint, userID uint) (*models.Tag, error)
+	FindByName(name string, userID uint) (*models.Tag, error)
+	FindAll(userID uint) ([]models.Tag, error)
+	Update(tag *models.Tag) error
+	Delete(id uint, userID uint) error
+	CountTransactions(id uint) (int64, error)
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) Create(tag *models.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+func (r *tagRepository) FindByID(id uint, userID uint) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) FindByName(name string, userID uint) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.Where("name = ? AND user_id = ?", name, userID).First(&tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) FindAll(userID uint) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.db.Where("user_id = ?", userID).Order("name ASC").Find(&tags).Error
+	return tags, err
+}
+
+func (r *tagRepository) Update(tag *models.Tag) error {
+	return r.db.Save(tag).Error
+}
+
+func (r *tagRepository) Delete(id uint, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM transaction_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Tag{}).Error
+	})
+}
+
+func (r *tagRepository) CountTransactions(id uint) (int64, error) {
+	var count int64
+	err := r.db.Table("transaction_tags").Where("tag_id = ?", id).Count(&count).Error
+	return count, err
+}
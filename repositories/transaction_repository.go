@@ -27,7 +27,9 @@ func (r *transactionRepository) GetAll(userID uint, page, limit int, startDate,
 	var transactions []models.Transaction
 	var total int64
 
-	query := r.db.Model(&models.Transaction{}).Where("user_id = ?", userID)
+	// The legacy model shares the transactions table but has no soft delete
+	// scope, so rows in the trash are filtered out by hand
+	query := r.db.Model(&models.Transaction{}).Where("user_id = ? AND deleted_at IS NULL", userID)
 
 	// Apply filters
 	if startDate != nil {
@@ -68,7 +70,7 @@ func (r *transactionRepository) GetByID(id, userID uint) (*models.Transaction, e
 	err := r.db.
 		Preload("Category").
 		Preload("Bank").
-		Where("id = ? AND user_id = ?", id, userID).
+		Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).
 		First(&transaction).Error
 	
 	if err != nil {
@@ -86,5 +88,5 @@ func (r *transactionRepository) Update(transaction *models.Transaction) error {
 }
 
 func (r *transactionRepository) Delete(id, userID uint) error {
-	return r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Transaction{}).Error
+	return r.db.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).Delete(&models.Transaction{}).Error
 }
@@ -4,17 +4,26 @@ import (
 	"errors"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"my-api/dto"
 	"my-api/models"
+	"my-api/money"
 	"time"
 )
 
 type TransactionV2Repository interface {
-	GetAll(userID uint, page, limit int, startDate, endDate *time.Time, transactionType *int, categoryID *uint, assetID *uint64) ([]models.TransactionV2, int64, error)
+	GetAll(userID uint, page, limit int, filter *dto.TransactionV2Filter) ([]models.TransactionV2, int64, error)
+	StreamAll(userID uint, filter *dto.TransactionV2Filter, batchSize int, fn func([]models.TransactionV2) error) error
 	GetByID(id, userID uint) (*models.TransactionV2, error)
 	GetByIDWithAsset(id, userID uint) (*models.TransactionV2, error)
 	CreateWithBalanceUpdate(transaction *models.TransactionV2) error
-	UpdateWithBalanceUpdate(transaction *models.TransactionV2, oldAmount int, oldType int) error
+	CreateWithBalanceUpdateTx(tx *gorm.DB, transaction *models.TransactionV2) error
+	CreateBatchWithBalanceUpdate(assetID uint64, userID uint, transactions []models.TransactionV2) error
+	FindExistingImportKeys(assetID uint64, keys []string) (map[string]bool, error)
+	UpdateWithBalanceUpdate(transaction *models.TransactionV2, oldAmount money.Amount, oldType int) error
 	DeleteWithBalanceRollback(id, userID uint) error
+	GetDeleted(userID uint, page, limit int) ([]models.TransactionV2, int64, error)
+	RestoreWithBalanceUpdate(id, userID uint) error
+	UpdateStatus(id, userID uint, status string, unlock bool) error
 	GetByAssetID(assetID uint64, userID uint, page, limit int) ([]models.TransactionV2, int64, error)
 }
 
@@ -26,27 +35,11 @@ func NewTransactionV2Repository(db *gorm.DB) TransactionV2Repository {
 	return &transactionV2Repository{db: db}
 }
 
-func (r *transactionV2Repository) GetAll(userID uint, page, limit int, startDate, endDate *time.Time, transactionType *int, categoryID *uint, assetID *uint64) ([]models.TransactionV2, int64, error) {
+func (r *transactionV2Repository) GetAll(userID uint, page, limit int, filter *dto.TransactionV2Filter) ([]models.TransactionV2, int64, error) {
 	var transactions []models.TransactionV2
 	var total int64
 
-	query := r.db.Model(&models.TransactionV2{}).Where("user_id = ?", userID)
-
-	if startDate != nil {
-		query = query.Where("date >= ?", startDate)
-	}
-	if endDate != nil {
-		query = query.Where("date <= ?", endDate)
-	}
-	if transactionType != nil {
-		query = query.Where("transaction_type = ?", *transactionType)
-	}
-	if categoryID != nil {
-		query = query.Where("category_id = ?", *categoryID)
-	}
-	if assetID != nil {
-		query = query.Where("asset_id = ?", *assetID)
-	}
+	query := r.filteredQuery(userID, filter)
 
 	query.Count(&total)
 
@@ -55,6 +48,9 @@ func (r *transactionV2Repository) GetAll(userID uint, page, limit int, startDate
 		Preload("Category").
 		Preload("Bank").
 		Preload("Asset").
+		Preload("Splits.Category").
+		Preload("Tags").
+		Preload("Payee").
 		Order("date DESC, id DESC").
 		Limit(limit).
 		Offset(offset).
@@ -63,12 +59,90 @@ func (r *transactionV2Repository) GetAll(userID uint, page, limit int, startDate
 	return transactions, total, err
 }
 
+// StreamAll walks every matching transaction in date order, batchSize rows at
+// a time, so large exports never hold the full result in memory. Batches are
+// fetched by (date, id) keyset rather than offset to stay fast on deep pages.
+func (r *transactionV2Repository) StreamAll(userID uint, filter *dto.TransactionV2Filter, batchSize int, fn func([]models.TransactionV2) error) error {
+	var lastDate time.Time
+	var lastID uint
+
+	for {
+		var batch []models.TransactionV2
+		query := r.filteredQuery(userID, filter)
+		if lastID != 0 {
+			query = query.Where("(date > ? OR (date = ? AND id > ?))", lastDate, lastDate, lastID)
+		}
+
+		err := query.
+			Preload("Category").
+			Preload("Asset").
+			Preload("Tags").
+			Preload("Payee").
+			Order("date ASC, id ASC").
+			Limit(batchSize).
+			Find(&batch).Error
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+
+		last := batch[len(batch)-1]
+		lastDate, lastID = last.Date.Time, last.ID
+	}
+}
+
+// filteredQuery selects the user's transactions matching filter. Transactions
+// of a wallet in the trash are left out until the wallet is restored.
+func (r *transactionV2Repository) filteredQuery(userID uint, filter *dto.TransactionV2Filter) *gorm.DB {
+	query := r.db.Model(&models.TransactionV2{}).
+		Where("user_id = ?", userID).
+		Where(trashedAssetsCondition)
+	if filter == nil {
+		return query
+	}
+
+	if filter.StartDate != nil {
+		query = query.Where("date >= ?", filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("date <= ?", filter.EndDate)
+	}
+	if filter.TransactionType != nil {
+		query = query.Where("transaction_type = ?", *filter.TransactionType)
+	}
+	if filter.CategoryID != nil {
+		query = query.Where("category_id = ?", *filter.CategoryID)
+	}
+	if filter.AssetID != nil {
+		query = query.Where("asset_id = ?", *filter.AssetID)
+	}
+	if len(filter.TagIDs) > 0 {
+		query = query.Where("id IN (SELECT transaction_id FROM transaction_tags WHERE tag_id IN ?)", filter.TagIDs)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	return query
+}
+
 func (r *transactionV2Repository) GetByID(id, userID uint) (*models.TransactionV2, error) {
 	var transaction models.TransactionV2
 	err := r.db.
 		Preload("Category").
 		Preload("Bank").
 		Preload("Asset").
+		Preload("Splits.Category").
+		Preload("Tags").
+		Preload("Payee").
 		Where("id = ? AND user_id = ?", id, userID).
 		First(&transaction).Error
 
@@ -83,41 +157,174 @@ func (r *transactionV2Repository) GetByIDWithAsset(id, userID uint) (*models.Tra
 }
 
 func (r *transactionV2Repository) CreateWithBalanceUpdate(transaction *models.TransactionV2) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createWithBalanceUpdate(tx, transaction)
+	})
+}
+
+// CreateWithBalanceUpdateTx is CreateWithBalanceUpdate within a DB
+// transaction owned by the caller
+func (r *transactionV2Repository) CreateWithBalanceUpdateTx(tx *gorm.DB, transaction *models.TransactionV2) error {
+	return createWithBalanceUpdate(tx, transaction)
+}
+
+// createWithBalanceUpdate creates the transaction, applies it to its asset's
+// balance and posts it to the ledger within tx
+func createWithBalanceUpdate(tx *gorm.DB, transaction *models.TransactionV2) error {
+	var asset models.Asset
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&asset, transaction.AssetID).Error; err != nil {
+		return errors.New("asset not found")
+	}
+
+	if asset.UserID != uint64(transaction.UserID) {
+		return errors.New("unauthorized: asset does not belong to user")
+	}
+
+	if transaction.TransactionType == 2 && !asset.CanSpend(transaction.Amount) {
+		return errors.New("insufficient balance")
+	}
+
+	if transaction.TransactionType == 1 {
+		asset.Balance += transaction.Amount
+	} else {
+		asset.Balance -= transaction.Amount
+	}
+
+	if err := tx.Save(&asset).Error; err != nil {
+		return err
+	}
+
+	if transaction.Status == "" {
+		transaction.Status = models.TransactionStatusCleared
+	}
+	if err := tx.Omit("Tags").Create(transaction).Error; err != nil {
+		return err
+	}
+	if err := postTransaction(tx, transaction, &asset); err != nil {
+		return err
+	}
+
+	if len(transaction.Tags) == 0 {
+		return nil
+	}
+	return replaceTags(tx, transaction)
+}
+
+// CreateBatchWithBalanceUpdate posts all transactions to one asset in a single
+// DB transaction and applies their net effect to the asset balance
+func (r *transactionV2Repository) CreateBatchWithBalanceUpdate(assetID uint64, userID uint, transactions []models.TransactionV2) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		var asset models.Asset
 		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-			First(&asset, transaction.AssetID).Error; err != nil {
+			First(&asset, assetID).Error; err != nil {
 			return errors.New("asset not found")
 		}
 
-		if asset.UserID != uint64(transaction.UserID) {
+		if asset.UserID != uint64(userID) {
 			return errors.New("unauthorized: asset does not belong to user")
 		}
 
-		if transaction.TransactionType == 2 && asset.Balance < float64(transaction.Amount) {
-			return errors.New("insufficient balance")
+		for i := range transactions {
+			transactions[i].AssetID = assetID
+			transactions[i].UserID = userID
+			if transactions[i].TransactionType == 1 {
+				asset.Balance += transactions[i].Amount
+			} else {
+				asset.Balance -= transactions[i].Amount
+			}
 		}
 
-		if transaction.TransactionType == 1 {
-			asset.Balance += float64(transaction.Amount)
-		} else {
-			asset.Balance -= float64(transaction.Amount)
+		if !asset.CanSpend(0) {
+			return errors.New("insufficient balance")
+		}
+		for i := range transactions {
+			if transactions[i].Status == "" {
+				transactions[i].Status = models.TransactionStatusCleared
+			}
 		}
 
 		if err := tx.Save(&asset).Error; err != nil {
 			return err
 		}
 
-		return tx.Create(transaction).Error
+		if err := tx.Omit("Tags").CreateInBatches(transactions, 100).Error; err != nil {
+			return err
+		}
+		for i := range transactions {
+			if err := postTransaction(tx, &transactions[i], &asset); err != nil {
+				return err
+			}
+			if len(transactions[i].Tags) == 0 {
+				continue
+			}
+			if err := replaceTags(tx, &transactions[i]); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 }
 
-func (r *transactionV2Repository) UpdateWithBalanceUpdate(transaction *models.TransactionV2, oldAmount int, oldType int) error {
+// FindExistingImportKeys returns which of the given import keys were already
+// posted to the asset
+func (r *transactionV2Repository) FindExistingImportKeys(assetID uint64, keys []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	for start := 0; start < len(keys); start += 500 {
+		end := start + 500
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		// Transactions in the trash still hold their import key
+		var found []string
+		if err := r.db.Unscoped().Model(&models.TransactionV2{}).
+			Where("asset_id = ? AND import_key IN ?", assetID, keys[start:end]).
+			Pluck("import_key", &found).Error; err != nil {
+			return nil, err
+		}
+		for _, key := range found {
+			existing[key] = true
+		}
+	}
+	return existing, nil
+}
+
+func (r *transactionV2Repository) UpdateWithBalanceUpdate(transaction *models.TransactionV2, oldAmount money.Amount, oldType int) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		var asset models.Asset
-		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-			First(&asset, transaction.AssetID).Error; err != nil {
-			return errors.New("asset not found")
+		var existing models.TransactionV2
+		if err := tx.Where("id = ? AND user_id = ?", transaction.ID, transaction.UserID).
+			First(&existing).Error; err != nil {
+			return err
+		}
+		if existing.TransferID != nil {
+			return errors.New("transaction belongs to a transfer")
+		}
+		if existing.Status == models.TransactionStatusReconciled {
+			return errors.New("transaction is reconciled")
+		}
+		// Status only changes through UpdateStatus
+		transaction.Status = existing.Status
+
+		// The old amount comes off the asset the transaction was on, which
+		// differs from the new one when the transaction is moved
+		var oldAsset, asset *models.Asset
+		if existing.AssetID == transaction.AssetID {
+			asset = &models.Asset{}
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				First(asset, transaction.AssetID).Error; err != nil {
+				return errors.New("asset not found")
+			}
+			oldAsset = asset
+		} else {
+			var err error
+			if oldAsset, asset, err = lockAssetPair(tx, existing.AssetID, transaction.AssetID); err != nil {
+				return err
+			}
 		}
 
 		if asset.UserID != uint64(transaction.UserID) {
@@ -125,29 +332,104 @@ func (r *transactionV2Repository) UpdateWithBalanceUpdate(transaction *models.Tr
 		}
 
 		if oldType == 1 {
-			asset.Balance -= float64(oldAmount)
+			oldAsset.Balance -= oldAmount
 		} else {
-			asset.Balance += float64(oldAmount)
+			oldAsset.Balance += oldAmount
 		}
 
-		if transaction.TransactionType == 2 && asset.Balance < float64(transaction.Amount) {
+		if transaction.TransactionType == 2 && !asset.CanSpend(transaction.Amount) {
 			return errors.New("insufficient balance")
 		}
 
 		if transaction.TransactionType == 1 {
-			asset.Balance += float64(transaction.Amount)
+			asset.Balance += transaction.Amount
 		} else {
-			asset.Balance -= float64(transaction.Amount)
+			asset.Balance -= transaction.Amount
 		}
 
-		if err := tx.Save(&asset).Error; err != nil {
+		if oldAsset != asset {
+			if err := tx.Save(oldAsset).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(asset).Error; err != nil {
 			return err
 		}
 
-		return tx.Save(transaction).Error
+		if err := tx.Omit("Splits", "Tags").Save(transaction).Error; err != nil {
+			return err
+		}
+		if err := reverseEntries(tx, "transaction_id = ?", transaction.ID); err != nil {
+			return err
+		}
+		if err := postTransaction(tx, transaction, asset); err != nil {
+			return err
+		}
+
+		if err := replaceSplits(tx, transaction); err != nil {
+			return err
+		}
+		return replaceTags(tx, transaction)
 	})
 }
 
+// replaceSplits swaps the stored split lines of a transaction for the ones on
+// the given model
+func replaceSplits(tx *gorm.DB, transaction *models.TransactionV2) error {
+	if err := tx.Where("transaction_id = ?", transaction.ID).
+		Delete(&models.TransactionSplit{}).Error; err != nil {
+		return err
+	}
+	if len(transaction.Splits) == 0 {
+		return nil
+	}
+
+	for i := range transaction.Splits {
+		transaction.Splits[i].ID = 0
+		transaction.Splits[i].TransactionID = transaction.ID
+	}
+	return tx.Omit("Category").Create(&transaction.Splits).Error
+}
+
+// replaceTags swaps the tags of a transaction for the ones on the given model.
+// Every tag must belong to the owner of the transaction.
+func replaceTags(tx *gorm.DB, transaction *models.TransactionV2) error {
+	if err := tx.Exec("DELETE FROM transaction_tags WHERE transaction_id = ?", transaction.ID).Error; err != nil {
+		return err
+	}
+	if len(transaction.Tags) == 0 {
+		return nil
+	}
+
+	seen := make(map[uint]bool, len(transaction.Tags))
+	var tagIDs []uint
+	for _, tag := range transaction.Tags {
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			tagIDs = append(tagIDs, tag.ID)
+		}
+	}
+
+	var count int64
+	if err := tx.Model(&models.Tag{}).
+		Where("id IN ? AND user_id = ?", tagIDs, transaction.UserID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != len(tagIDs) {
+		return errors.New("tag not found")
+	}
+
+	rows := make([]map[string]interface{}, len(tagIDs))
+	for i, tagID := range tagIDs {
+		rows[i] = map[string]interface{}{"transaction_id": transaction.ID, "tag_id": tagID}
+	}
+	return tx.Table("transaction_tags").Create(&rows).Error
+}
+
+// DeleteWithBalanceRollback moves the transaction to the trash. Its effect on
+// the asset balance and the ledger is undone now; splits, tags and
+// attachments are kept so RestoreWithBalanceUpdate can bring it back whole.
 func (r *transactionV2Repository) DeleteWithBalanceRollback(id, userID uint) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		var transaction models.TransactionV2
@@ -155,6 +437,12 @@ func (r *transactionV2Repository) DeleteWithBalanceRollback(id, userID uint) err
 			First(&transaction).Error; err != nil {
 			return err
 		}
+		if transaction.TransferID != nil {
+			return errors.New("transaction belongs to a transfer")
+		}
+		if transaction.Status == models.TransactionStatusReconciled {
+			return errors.New("transaction is reconciled")
+		}
 
 		var asset models.Asset
 		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
@@ -163,19 +451,109 @@ func (r *transactionV2Repository) DeleteWithBalanceRollback(id, userID uint) err
 		}
 
 		if transaction.TransactionType == 1 {
-			asset.Balance -= float64(transaction.Amount)
+			asset.Balance -= transaction.Amount
 		} else {
-			asset.Balance += float64(transaction.Amount)
+			asset.Balance += transaction.Amount
 		}
 
 		if err := tx.Save(&asset).Error; err != nil {
 			return err
 		}
+		if err := reverseEntries(tx, "transaction_id = ?", transaction.ID); err != nil {
+			return err
+		}
 
 		return tx.Delete(&transaction).Error
 	})
 }
 
+// GetDeleted lists the user's transactions in the trash, most recently
+// deleted first
+func (r *transactionV2Repository) GetDeleted(userID uint, page, limit int) ([]models.TransactionV2, int64, error) {
+	var transactions []models.TransactionV2
+	var total int64
+
+	query := r.db.Unscoped().Model(&models.TransactionV2{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// The category or asset may be in the trash as well
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+	err := query.
+		Preload("Category", unscoped).
+		Preload("Bank").
+		Preload("Asset", unscoped).
+		Preload("Splits.Category", unscoped).
+		Preload("Tags").
+		Preload("Payee").
+		Order("deleted_at DESC, id DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&transactions).Error
+
+	return transactions, total, err
+}
+
+// RestoreWithBalanceUpdate takes a transaction out of the trash and applies
+// it to its asset's balance and the ledger again
+func (r *transactionV2Repository) RestoreWithBalanceUpdate(id, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var transaction models.TransactionV2
+		if err := tx.Unscoped().
+			Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+			First(&transaction).Error; err != nil {
+			return errors.New("transaction not found in trash")
+		}
+
+		var asset models.Asset
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&asset, transaction.AssetID).Error; err != nil {
+			return errors.New("asset not found")
+		}
+
+		if transaction.TransactionType == 2 && !asset.CanSpend(transaction.Amount) {
+			return errors.New("insufficient balance")
+		}
+
+		if transaction.TransactionType == 1 {
+			asset.Balance += transaction.Amount
+		} else {
+			asset.Balance -= transaction.Amount
+		}
+
+		if err := tx.Save(&asset).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&transaction).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return postTransaction(tx, &transaction, &asset)
+	})
+}
+
+// UpdateStatus moves a transaction between pending, cleared and reconciled.
+// The balance is not touched since it already includes pending rows. A
+// reconciled transaction only changes status when unlock is set.
+func (r *transactionV2Repository) UpdateStatus(id, userID uint, status string, unlock bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var transaction models.TransactionV2
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", id, userID).
+			First(&transaction).Error; err != nil {
+			return err
+		}
+		if transaction.Status == models.TransactionStatusReconciled && status != transaction.Status && !unlock {
+			return errors.New("transaction is reconciled")
+		}
+
+		return tx.Model(&transaction).Update("status", status).Error
+	})
+}
+
 func (r *transactionV2Repository) GetByAssetID(assetID uint64, userID uint, page, limit int) ([]models.TransactionV2, int64, error) {
 	var transactions []models.TransactionV2
 	var total int64
@@ -189,6 +567,9 @@ func (r *transactionV2Repository) GetByAssetID(assetID uint64, userID uint, page
 	err := query.
 		Preload("Category").
 		Preload("Bank").
+		Preload("Splits.Category").
+		Preload("Tags").
+		Preload("Payee").
 		Order("date DESC, id DESC").
 		Limit(limit).
 		Offset(offset).
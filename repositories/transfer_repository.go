@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"my-api/models"
+)
+
+type TransferRepository interface {
+	GetAll(userID uint, page, limit int, assetID *uint64) ([]models.Transfer, int64, error)
+	GetByID(id, userID uint) (*models.Transfer, error)
+	CreateWithBalanceUpdate(transfer *models.Transfer, categoryID uint) error
+	CreatePayment(transfer *models.Transfer, categoryID uint) error
+	DeleteWithBalanceRollback(id, userID uint) error
+	CategoryExists(categoryID uint, userID uint) (bool, error)
+}
+
+type transferRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferRepository(db *gorm.DB) TransferRepository {
+	return &transferRepository{db: db}
+}
+
+func (r *transferRepository) GetAll(userID uint, page, limit int, assetID *uint64) ([]models.Transfer, int64, error) {
+	var transfers []models.Transfer
+	var total int64
+
+	query := r.db.Model(&models.Transfer{}).Where("user_id = ?", userID)
+
+	if assetID != nil {
+		query = query.Where("source_asset_id = ? OR target_asset_id = ?", *assetID, *assetID)
+	}
+
+	query.Count(&total)
+
+	offset := (page - 1) * limit
+	err := query.
+		Preload("SourceAsset").
+		Preload("TargetAsset").
+		Order("date DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&transfers).Error
+
+	return transfers, total, err
+}
+
+func (r *transferRepository) GetByID(id, userID uint) (*models.Transfer, error) {
+	var transfer models.Transfer
+	err := r.db.
+		Preload("SourceAsset").
+		Preload("TargetAsset").
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&transfer).Error
+
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// lockAssetPair locks both assets FOR UPDATE in ascending ID order so two
+// opposite transfers between the same wallets cannot deadlock.
+func lockAssetPair(tx *gorm.DB, sourceID, targetID uint64) (*models.Asset, *models.Asset, error) {
+	firstID, secondID := sourceID, targetID
+	if firstID > secondID {
+		firstID, secondID = secondID, firstID
+	}
+
+	var first, second models.Asset
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&first, firstID).Error; err != nil {
+		return nil, nil, errors.New("asset not found")
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&second, secondID).Error; err != nil {
+		return nil, nil, errors.New("asset not found")
+	}
+
+	if first.ID == sourceID {
+		return &first, &second, nil
+	}
+	return &second, &first, nil
+}
+
+func (r *transferRepository) CreateWithBalanceUpdate(transfer *models.Transfer, categoryID uint) error {
+	return r.create(transfer, categoryID, false)
+}
+
+// CreatePayment records a payment towards a credit card or loan as a
+// transfer from an ordinary asset, which reduces the debt
+func (r *transferRepository) CreatePayment(transfer *models.Transfer, categoryID uint) error {
+	return r.create(transfer, categoryID, true)
+}
+
+func (r *transferRepository) create(transfer *models.Transfer, categoryID uint, payment bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		source, target, err := lockAssetPair(tx, transfer.SourceAssetID, transfer.TargetAssetID)
+		if err != nil {
+			return err
+		}
+
+		if source.UserID != uint64(transfer.UserID) || target.UserID != uint64(transfer.UserID) {
+			return errors.New("unauthorized: asset does not belong to user")
+		}
+
+		if payment && !target.IsLiability() {
+			return errors.New("payments can only be made to a credit card or loan")
+		}
+		if payment && source.IsLiability() {
+			return errors.New("payments must come from a non-credit asset")
+		}
+
+		if source.Currency != target.Currency {
+			return errors.New("currency mismatch")
+		}
+
+		if !source.CanSpend(transfer.Amount) {
+			return errors.New("insufficient balance")
+		}
+
+		source.Balance -= transfer.Amount
+		target.Balance += transfer.Amount
+
+		if err := tx.Save(source).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(target).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(transfer).Error; err != nil {
+			return err
+		}
+		if err := postTransfer(tx, transfer, source, target); err != nil {
+			return err
+		}
+
+		outgoing := &models.TransactionV2{
+			UserID:          transfer.UserID,
+			Description:     transfer.Description,
+			CategoryID:      categoryID,
+			AssetID:         transfer.SourceAssetID,
+			Amount:          transfer.Amount,
+			TransactionType: 2,
+			Status:          models.TransactionStatusCleared,
+			TransferID:      &transfer.ID,
+			Date:            transfer.Date,
+		}
+		if err := createTransferLeg(tx, outgoing); err != nil {
+			return err
+		}
+
+		incoming := &models.TransactionV2{
+			UserID:          transfer.UserID,
+			Description:     transfer.Description,
+			CategoryID:      categoryID,
+			AssetID:         transfer.TargetAssetID,
+			Amount:          transfer.Amount,
+			TransactionType: 1,
+			Status:          models.TransactionStatusCleared,
+			TransferID:      &transfer.ID,
+			Date:            transfer.Date,
+		}
+		if err := createTransferLeg(tx, incoming); err != nil {
+			return err
+		}
+
+		transfer.SourceTransactionID = outgoing.ID
+		transfer.TargetTransactionID = incoming.ID
+		return tx.Model(transfer).Updates(map[string]interface{}{
+			"source_transaction_id": outgoing.ID,
+			"target_transaction_id": incoming.ID,
+		}).Error
+	})
+}
+
+// createTransferLeg creates one side of a transfer. Legs without a category
+// store NULL rather than pointing at a category that does not exist.
+func createTransferLeg(tx *gorm.DB, leg *models.TransactionV2) error {
+	if leg.CategoryID == 0 {
+		tx = tx.Omit("category_id")
+	}
+	return tx.Create(leg).Error
+}
+
+func (r *transferRepository) CategoryExists(categoryID uint, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Category{}).
+		Where("id = ? AND user_id = ?", categoryID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *transferRepository) DeleteWithBalanceRollback(id, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var transfer models.Transfer
+		if err := tx.Where("id = ? AND user_id = ?", id, userID).
+			First(&transfer).Error; err != nil {
+			return err
+		}
+
+		var reconciledLegs int64
+		if err := tx.Model(&models.TransactionV2{}).
+			Where("transfer_id = ? AND status = ?", transfer.ID, models.TransactionStatusReconciled).
+			Count(&reconciledLegs).Error; err != nil {
+			return err
+		}
+		if reconciledLegs > 0 {
+			return errors.New("transaction is reconciled")
+		}
+
+		source, target, err := lockAssetPair(tx, transfer.SourceAssetID, transfer.TargetAssetID)
+		if err != nil {
+			return err
+		}
+
+		source.Balance += transfer.Amount
+		target.Balance -= transfer.Amount
+
+		if err := tx.Save(source).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(target).Error; err != nil {
+			return err
+		}
+		if err := reverseEntries(tx, "transfer_id = ?", transfer.ID); err != nil {
+			return err
+		}
+
+		// Transfers have no trash, so their legs are removed for good
+		if err := tx.Unscoped().Where("transfer_id = ?", transfer.ID).
+			Delete(&models.TransactionV2{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&transfer).Error
+	})
+}
@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"my-api/models"
+	"time"
+)
+
+// purgeBatchSize caps how many trashed transactions are removed per DB
+// transaction
+const purgeBatchSize = 500
+
+// TrashRepository permanently removes rows that were soft deleted before a
+// cutoff
+type TrashRepository interface {
+	PurgeTransactions(before time.Time) ([]models.Attachment, int64, error)
+	PurgeAssets(before time.Time) ([]models.Attachment, int64, error)
+	PurgeCategories(before time.Time) (int64, error)
+}
+
+type trashRepository struct {
+	db *gorm.DB
+}
+
+func NewTrashRepository(db *gorm.DB) TrashRepository {
+	return &trashRepository{db: db}
+}
+
+// PurgeTransactions removes transactions deleted before the cutoff. It returns
+// the attachments that went with them so the caller can remove the stored
+// files, and the number of transactions removed.
+func (r *trashRepository) PurgeTransactions(before time.Time) ([]models.Attachment, int64, error) {
+	var removed []models.Attachment
+	var purged int64
+	for {
+		var ids []uint
+		var attachments []models.Attachment
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Unscoped().Model(&models.TransactionV2{}).
+				Where("deleted_at < ?", before).
+				Order("id ASC").
+				Limit(purgeBatchSize).
+				Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+
+			var err error
+			attachments, err = purgeTransactions(tx, ids)
+			return err
+		})
+		if err != nil {
+			return removed, purged, err
+		}
+
+		removed = append(removed, attachments...)
+		purged += int64(len(ids))
+		if len(ids) < purgeBatchSize {
+			return removed, purged, nil
+		}
+	}
+}
+
+// PurgeAssets removes assets deleted before the cutoff together with their
+// transactions, holdings and balance snapshots, and deactivates recurring
+// transactions still posting to them. Transfer legs stay so the other side of
+// each transfer keeps its history. Assets a budget is still limited to stay in
+// the trash until the budget no longer lists them.
+func (r *trashRepository) PurgeAssets(before time.Time) ([]models.Attachment, int64, error) {
+	var removed []models.Attachment
+	var assetIDs []uint64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Asset{}).
+			Where("deleted_at < ?", before).
+			Where("NOT EXISTS (SELECT 1 FROM budget_assets WHERE budget_assets.asset_id = assets.id)").
+			Pluck("id", &assetIDs).Error; err != nil {
+			return err
+		}
+		if len(assetIDs) == 0 {
+			return nil
+		}
+
+		var transactionIDs []uint
+		if err := tx.Unscoped().Model(&models.TransactionV2{}).
+			Where("asset_id IN ? AND transfer_id IS NULL", assetIDs).
+			Pluck("id", &transactionIDs).Error; err != nil {
+			return err
+		}
+		attachments, err := purgeTransactions(tx, transactionIDs)
+		if err != nil {
+			return err
+		}
+
+		if err := deactivateRecurringTransactions(tx, assetIDs); err != nil {
+			return err
+		}
+		if err := tx.Where("asset_id IN ?", assetIDs).
+			Delete(&models.HoldingLot{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("asset_id IN ?", assetIDs).
+			Delete(&models.BalanceSnapshot{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&models.Asset{}, assetIDs).Error; err != nil {
+			return err
+		}
+
+		removed = attachments
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return removed, int64(len(assetIDs)), nil
+}
+
+// categoryReferences are the conditions a category must meet before it can be
+// purged, one per column that points at categories. Apart from
+// budget_categories none of them has a foreign key, so a purged category
+// would leave those rows pointing at nothing and drop their amounts out of
+// every query that joins categories.
+var categoryReferences = []string{
+	"NOT EXISTS (SELECT 1 FROM transactions WHERE transactions.category_id = categories.id)",
+	"NOT EXISTS (SELECT 1 FROM transaction_splits WHERE transaction_splits.category_id = categories.id)",
+	"NOT EXISTS (SELECT 1 FROM budgets WHERE budgets.category_id = categories.id)",
+	"NOT EXISTS (SELECT 1 FROM budget_categories WHERE budget_categories.category_id = categories.id)",
+	"NOT EXISTS (SELECT 1 FROM recurring_transactions WHERE recurring_transactions.category_id = categories.id)",
+	"NOT EXISTS (SELECT 1 FROM category_rules WHERE category_rules.category_id = categories.id)",
+	"NOT EXISTS (SELECT 1 FROM import_profiles WHERE import_profiles.income_category_id = categories.id OR import_profiles.expense_category_id = categories.id)",
+}
+
+// PurgeCategories removes categories deleted before the cutoff that nothing
+// refers to any more. Categories still used by a transaction, split, budget,
+// recurring transaction, rule or import profile, including ones in the trash,
+// stay in the trash until those rows are gone or moved.
+func (r *trashRepository) PurgeCategories(before time.Time) (int64, error) {
+	query := r.db.Unscoped().Where("deleted_at < ?", before)
+	for _, condition := range categoryReferences {
+		query = query.Where(condition)
+	}
+	result := query.Delete(&models.Category{})
+	return result.RowsAffected, result.Error
+}
+
+// purgeTransactions removes the transactions for good along with their
+// splits, tags and attachment rows, and returns the attachments
+func purgeTransactions(tx *gorm.DB, ids []uint) ([]models.Attachment, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var attachments []models.Attachment
+	if err := tx.Where("transaction_id IN ?", ids).
+		Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("transaction_id IN ?", ids).
+		Delete(&models.TransactionSplit{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM transaction_tags WHERE transaction_id IN ?", ids).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("transaction_id IN ?", ids).
+		Delete(&models.Attachment{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Delete(&models.TransactionV2{}, ids).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
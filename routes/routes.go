@@ -2,11 +2,14 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"log"
 	"my-api/config"
 	"my-api/controllers"
 	"my-api/middleware"
 	"my-api/repositories"
 	"my-api/services"
+	"my-api/storage"
+	"my-api/utils"
 )
 
 func SetupRouter(router *gin.Engine) {
@@ -19,16 +22,46 @@ func SetupRouter(router *gin.Engine) {
 	assetRepo := repositories.NewAssetRepository(config.DB)
 	transactionV2Repo := repositories.NewTransactionV2Repository(config.DB)
 	userSettingsRepo := repositories.NewUserSettingsRepository(config.DB)
+	transferRepo := repositories.NewTransferRepository(config.DB)
+	recurringTransactionRepo := repositories.NewRecurringTransactionRepository(config.DB)
+	importProfileRepo := repositories.NewImportProfileRepository(config.DB)
+	tagRepo := repositories.NewTagRepository(config.DB)
+	attachmentRepo := repositories.NewAttachmentRepository(config.DB)
+	payeeRepo := repositories.NewPayeeRepository(config.DB)
+	categoryRuleRepo := repositories.NewCategoryRuleRepository(config.DB)
+	exchangeRateRepo := repositories.NewExchangeRateRepository(config.DB)
+	ledgerRepo := repositories.NewLedgerRepository(config.DB)
+	reconciliationRepo := repositories.NewReconciliationRepository(config.DB)
+	investmentRepo := repositories.NewInvestmentRepository(config.DB)
+	snapshotRepo := repositories.NewSnapshotRepository(config.DB)
+
+	// Initialize file storage
+	fileStorage, err := storage.NewLocalStorage(storage.AttachmentDir())
+	if err != nil {
+		utils.LogErrorf("Failed to initialize attachment storage: %v", err)
+		log.Fatal("Failed to initialize attachment storage:", err)
+	}
 
 	// Initialize services
 	userService := services.NewUserService(userRepo)
 	bankService := services.NewBankService(bankRepo)
-	budgetService := services.NewBudgetService(budgetRepo)
-	analyticsService := services.NewAnalyticsService(analyticsRepo, budgetRepo)
+	budgetService := services.NewBudgetService(budgetRepo, userSettingsRepo)
+	exchangeRateService := services.NewExchangeRateService(exchangeRateRepo, userSettingsRepo)
+	analyticsService := services.NewAnalyticsService(analyticsRepo, budgetService, snapshotRepo, exchangeRateService)
 	transactionService := services.NewTransactionService(transactionRepo)
-	assetService := services.NewAssetService(assetRepo)
-	transactionV2Service := services.NewTransactionV2Service(transactionV2Repo, assetRepo)
-	userSettingsService := services.NewUserSettingsService(userSettingsRepo)
+	investmentService := services.NewInvestmentService(investmentRepo, assetRepo)
+	assetService := services.NewAssetService(assetRepo, exchangeRateService, investmentService)
+	payeeService := services.NewPayeeService(payeeRepo)
+	categoryRuleService := services.NewCategoryRuleService(categoryRuleRepo, assetRepo, payeeService, budgetService)
+	transactionV2Service := services.NewTransactionV2Service(transactionV2Repo, assetRepo, ledgerRepo, payeeService, categoryRuleService)
+	userSettingsService := services.NewUserSettingsService(userSettingsRepo, budgetService)
+	transferService := services.NewTransferService(transferRepo)
+	recurringTransactionService := services.NewRecurringTransactionService(recurringTransactionRepo, transactionV2Repo, assetRepo, userSettingsRepo, budgetService)
+	importService := services.NewImportService(importProfileRepo, transactionV2Repo, assetRepo, payeeService, categoryRuleService, budgetService)
+	tagService := services.NewTagService(tagRepo)
+	attachmentService := services.NewAttachmentService(attachmentRepo, transactionV2Repo, fileStorage)
+	ledgerService := services.NewLedgerService(ledgerRepo, assetRepo)
+	reconciliationService := services.NewReconciliationService(reconciliationRepo, ledgerRepo)
 
 	// Initialize controllers
 	authController := controllers.NewAuthController(userService)
@@ -38,8 +71,19 @@ func SetupRouter(router *gin.Engine) {
 	analyticsController := controllers.NewAnalyticsController(analyticsService, userSettingsService)
 	transactionController := controllers.NewTransactionController(transactionService, budgetService)
 	transactionV2Controller := controllers.NewTransactionV2Controller(transactionV2Service, budgetService)
-	assetController := controllers.NewAssetController(assetService)
+	assetController := controllers.NewAssetController(assetService, budgetService)
 	userSettingsController := controllers.NewUserSettingsController(userSettingsService)
+	transferController := controllers.NewTransferController(transferService)
+	recurringTransactionController := controllers.NewRecurringTransactionController(recurringTransactionService)
+	importController := controllers.NewImportController(importService)
+	tagController := controllers.NewTagController(tagService)
+	attachmentController := controllers.NewAttachmentController(attachmentService)
+	payeeController := controllers.NewPayeeController(payeeService)
+	categoryRuleController := controllers.NewCategoryRuleController(categoryRuleService)
+	exchangeRateController := controllers.NewExchangeRateController(exchangeRateService)
+	ledgerController := controllers.NewLedgerController(ledgerService)
+	reconciliationController := controllers.NewReconciliationController(reconciliationService)
+	investmentController := controllers.NewInvestmentController(investmentService)
 
 	api := router.Group("/api")
 	{
@@ -81,16 +125,88 @@ func SetupRouter(router *gin.Engine) {
 		v2 := authorized.Group("/v2")
 		{
 			v2.GET("/transactions", transactionV2Controller.GetTransactions)
+			v2.GET("/transactions/export", transactionV2Controller.ExportTransactions)
 			v2.GET("/transactions/:id", transactionV2Controller.GetTransactionByID)
 			v2.POST("/transactions", transactionV2Controller.CreateTransaction)
 			v2.PUT("/transactions/:id", transactionV2Controller.UpdateTransaction)
 			v2.DELETE("/transactions/:id", transactionV2Controller.DeleteTransaction)
+			v2.GET("/transactions/trash", transactionV2Controller.GetDeletedTransactions)
+			v2.POST("/transactions/:id/restore", transactionV2Controller.RestoreTransaction)
+			v2.PUT("/transactions/:id/status", transactionV2Controller.UpdateTransactionStatus)
 			v2.GET("/assets/:id/transactions", transactionV2Controller.GetAssetTransactions)
+
+			// Receipt attachments
+			v2.GET("/transactions/:id/attachments", attachmentController.GetAttachments)
+			v2.POST("/transactions/:id/attachments", attachmentController.UploadAttachment)
+			v2.GET("/attachments/:id", attachmentController.DownloadAttachment)
+			v2.DELETE("/attachments/:id", attachmentController.DeleteAttachment)
+
+			// Transfers between assets
+			v2.GET("/transfers", transferController.GetTransfers)
+			v2.GET("/transfers/:id", transferController.GetTransferByID)
+			v2.POST("/transfers", transferController.CreateTransfer)
+			v2.DELETE("/transfers/:id", transferController.DeleteTransfer)
+
+			// Recurring transactions
+			v2.GET("/recurring-transactions", recurringTransactionController.GetRecurringTransactions)
+			v2.GET("/recurring-transactions/:id", recurringTransactionController.GetRecurringTransaction)
+			v2.POST("/recurring-transactions", recurringTransactionController.CreateRecurringTransaction)
+			v2.PUT("/recurring-transactions/:id", recurringTransactionController.UpdateRecurringTransaction)
+			v2.DELETE("/recurring-transactions/:id", recurringTransactionController.DeleteRecurringTransaction)
+			v2.GET("/recurring-transactions/:id/upcoming", recurringTransactionController.GetUpcomingOccurrences)
+			v2.POST("/recurring-transactions/:id/skip", recurringTransactionController.SkipOccurrence)
+			v2.POST("/recurring-transactions/:id/retry", recurringTransactionController.RetryOccurrence)
+
+			// Bank statement imports
+			v2.POST("/imports/csv", importController.ImportCSV)
+			v2.POST("/imports/ofx", importController.ImportOFX)
+			v2.POST("/imports/qif", importController.ImportQIF)
+			v2.GET("/imports/profiles", importController.GetProfiles)
+			v2.GET("/imports/profiles/:id", importController.GetProfile)
+			v2.POST("/imports/profiles", importController.CreateProfile)
+			v2.PUT("/imports/profiles/:id", importController.UpdateProfile)
+			v2.DELETE("/imports/profiles/:id", importController.DeleteProfile)
 		}
 
 		// Category routes
 		authorized.GET("/my-categories", controllers.GetCategoriesByUser)
 		authorized.POST("/categories", controllers.CreateCategory)
+		authorized.GET("/categories/trash", controllers.GetDeletedCategories)
+		authorized.POST("/categories/:id/restore", controllers.RestoreCategory)
+
+		// Tag routes
+		authorized.GET("/tags", tagController.GetTags)
+		authorized.GET("/tags/:id", tagController.GetTag)
+		authorized.POST("/tags", tagController.CreateTag)
+		authorized.PUT("/tags/:id", tagController.UpdateTag)
+		authorized.DELETE("/tags/:id", tagController.DeleteTag)
+
+		// Payee routes
+		authorized.GET("/payees", payeeController.GetPayees)
+		authorized.GET("/payees/:id", payeeController.GetPayee)
+		authorized.POST("/payees", payeeController.CreatePayee)
+		authorized.PUT("/payees/:id", payeeController.UpdatePayee)
+		authorized.DELETE("/payees/:id", payeeController.DeletePayee)
+		authorized.GET("/payee-rules", payeeController.GetRules)
+		authorized.POST("/payee-rules", payeeController.CreateRule)
+		authorized.POST("/payee-rules/apply", payeeController.ApplyRules)
+		authorized.PUT("/payee-rules/:id", payeeController.UpdateRule)
+		authorized.DELETE("/payee-rules/:id", payeeController.DeleteRule)
+
+		// Category rule routes
+		authorized.GET("/category-rules", categoryRuleController.GetRules)
+		authorized.GET("/category-rules/:id", categoryRuleController.GetRule)
+		authorized.POST("/category-rules", categoryRuleController.CreateRule)
+		authorized.PUT("/category-rules/:id", categoryRuleController.UpdateRule)
+		authorized.DELETE("/category-rules/:id", categoryRuleController.DeleteRule)
+		authorized.POST("/category-rules/:id/dry-run", categoryRuleController.DryRun)
+		authorized.POST("/category-rules/:id/apply", categoryRuleController.Apply)
+
+		// Exchange rate routes
+		authorized.GET("/exchange-rates", exchangeRateController.GetRates)
+		authorized.POST("/exchange-rates", exchangeRateController.CreateRate)
+		authorized.POST("/exchange-rates/import", exchangeRateController.ImportRates)
+		authorized.DELETE("/exchange-rates/:id", exchangeRateController.DeleteRate)
 
 		// Wallet routes (protected)
 		authorized.GET("/wallets", assetController.ListAssets)
@@ -98,13 +214,39 @@ func SetupRouter(router *gin.Engine) {
 		authorized.POST("/wallets", assetController.CreateAsset)
 		authorized.PUT("/wallets/:id", assetController.UpdateAsset)
 		authorized.DELETE("/wallets/:id", assetController.DeleteAsset)
+		authorized.GET("/wallets/trash", assetController.ListDeletedAssets)
+		authorized.POST("/wallets/:id/restore", assetController.RestoreAsset)
 		authorized.GET("/wallets/summary", assetController.Summary)
+		authorized.GET("/wallets/:id/ledger", ledgerController.GetStatement)
+		authorized.GET("/wallets/:id/statement", ledgerController.GetCreditStatement)
+		authorized.POST("/wallets/:id/payments", transferController.CreatePayment)
+		authorized.GET("/wallets/:id/reconciliations", reconciliationController.GetReconciliations)
+		authorized.POST("/wallets/:id/reconciliations", reconciliationController.CreateReconciliation)
+		authorized.GET("/wallets/:id/holdings", investmentController.GetHoldings)
+		authorized.GET("/wallets/:id/lots", investmentController.GetLots)
+		authorized.POST("/wallets/:id/lots", investmentController.CreateLot)
+		authorized.DELETE("/holding-lots/:id", investmentController.DeleteLot)
+
+		// Investment instrument and price routes
+		authorized.GET("/instruments", investmentController.GetInstruments)
+		authorized.POST("/instruments", investmentController.CreateInstrument)
+		authorized.PUT("/instruments/:id", investmentController.UpdateInstrument)
+		authorized.DELETE("/instruments/:id", investmentController.DeleteInstrument)
+		authorized.GET("/instruments/:id/prices", investmentController.GetPrices)
+		authorized.POST("/instruments/:id/prices", investmentController.CreatePrice)
+		authorized.POST("/instrument-prices/import", investmentController.ImportPrices)
+		authorized.DELETE("/instrument-prices/:id", investmentController.DeletePrice)
+
+		// Balance integrity routes
+		authorized.GET("/reconciliation", reconciliationController.CheckBalances)
+		authorized.POST("/reconciliation/fix", reconciliationController.FixDiscrepancies)
 
 		// Budget routes
 		authorized.POST("/budgets", budgetController.CreateBudget)
 		authorized.GET("/budgets", budgetController.GetBudgets)
 		authorized.GET("/budgets/status", budgetController.GetBudgetStatus)
 		authorized.GET("/budgets/:id", budgetController.GetBudget)
+		authorized.GET("/budgets/:id/history", budgetController.GetBudgetHistory)
 		authorized.PUT("/budgets/:id", budgetController.UpdateBudget)
 		authorized.DELETE("/budgets/:id", budgetController.DeleteBudget)
 		authorized.GET("/budget-alerts", budgetController.GetAlerts)
@@ -114,6 +256,8 @@ func SetupRouter(router *gin.Engine) {
 		// Analytics routes
 		authorized.GET("/analytics/dashboard", analyticsController.GetDashboardSummary)
 		authorized.GET("/analytics/spending-by-category", analyticsController.GetSpendingByCategory)
+		authorized.GET("/analytics/spending-by-tag", analyticsController.GetSpendingByTag)
+		authorized.GET("/analytics/spending-by-payee", analyticsController.GetSpendingByPayee)
 		authorized.GET("/analytics/spending-by-bank", analyticsController.GetSpendingByBank)
 		authorized.GET("/analytics/spending-by-asset", analyticsController.GetSpendingByAsset)
 		authorized.GET("/analytics/income-vs-expense", analyticsController.GetIncomeVsExpense)
@@ -121,6 +265,7 @@ func SetupRouter(router *gin.Engine) {
 		authorized.GET("/analytics/monthly-comparison", analyticsController.GetMonthlyComparison)
 		authorized.GET("/analytics/yearly-report", analyticsController.GetYearlyReport)
 		authorized.GET("/analytics/category-trend/:category_id", analyticsController.GetCategoryTrend)
+		authorized.GET("/analytics/net-worth", analyticsController.GetNetWorth)
 
 		// User Settings routes (Pay Cycle Configuration)
 		authorized.GET("/user/settings", userSettingsController.GetUserSettings)
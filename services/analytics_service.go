@@ -1,15 +1,23 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"my-api/dto"
 	"my-api/models"
+	"my-api/money"
 	"my-api/repositories"
+	"my-api/utils"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type AnalyticsService interface {
 	GetSpendingByCategory(userID uint, req *dto.AnalyticsRequest) ([]dto.SpendingByCategoryResponse, error)
+	GetSpendingByTag(userID uint, req *dto.AnalyticsRequest) ([]dto.SpendingByTagResponse, error)
+	GetSpendingByPayee(userID uint, req *dto.AnalyticsRequest) ([]dto.SpendingByPayeeResponse, error)
 	GetIncomeVsExpense(userID uint, req *dto.AnalyticsRequest) (*dto.IncomeVsExpenseResponse, error)
 	GetTrendAnalysis(userID uint, req *dto.AnalyticsRequest) (*dto.TrendAnalysisResponse, error)
 	GetTrendAnalysisWithPayCycle(userID uint, req *dto.AnalyticsRequest, settings *models.UserSettings) (*dto.TrendAnalysisResponse, error)
@@ -19,17 +27,22 @@ type AnalyticsService interface {
 	GetDashboardSummary(userID uint, startDate, endDate *time.Time, assetID *uint64) (*dto.DashboardSummaryResponse, error)
 	GetYearlyReport(userID uint, year int, assetID *uint64) (*dto.YearlyReportResponse, error)
 	GetCategoryTrend(userID uint, categoryID uint, req *dto.AnalyticsRequest) (*dto.CategoryTrendResponse, error)
+	GetNetWorth(userID uint, req *dto.AnalyticsRequest) (*dto.NetWorthResponse, error)
 }
 
 type analyticsService struct {
 	analyticsRepo repositories.AnalyticsRepository
-	budgetRepo    repositories.BudgetRepository
+	budgets       BudgetService
+	snapshotRepo  repositories.SnapshotRepository
+	fx            ExchangeRateService
 }
 
-func NewAnalyticsService(analyticsRepo repositories.AnalyticsRepository, budgetRepo repositories.BudgetRepository) AnalyticsService {
+func NewAnalyticsService(analyticsRepo repositories.AnalyticsRepository, budgets BudgetService, snapshotRepo repositories.SnapshotRepository, fx ExchangeRateService) AnalyticsService {
 	return &analyticsService{
 		analyticsRepo: analyticsRepo,
-		budgetRepo:    budgetRepo,
+		budgets:       budgets,
+		snapshotRepo:  snapshotRepo,
+		fx:            fx,
 	}
 }
 
@@ -53,6 +66,33 @@ func toInt(val interface{}) int {
 	}
 }
 
+// Helper function to safely convert a scanned SQL value to money.Amount.
+// Aggregates over DECIMAL columns come back as strings.
+func toAmount(val interface{}) money.Amount {
+	switch v := val.(type) {
+	case money.Amount:
+		return v
+	case int:
+		return money.FromInt(int64(v))
+	case int64:
+		return money.FromInt(v)
+	case float64:
+		return money.FromFloat(v)
+	case string:
+		if amount, err := money.Parse(v); err == nil {
+			return amount
+		}
+		return 0
+	case []byte:
+		if amount, err := money.Parse(string(v)); err == nil {
+			return amount
+		}
+		return 0
+	default:
+		return 0
+	}
+}
+
 // Helper function to safely convert interface{} to uint
 func toUint(val interface{}) uint {
 	switch v := val.(type) {
@@ -89,6 +129,87 @@ func toUint64(val interface{}) uint64 {
 	}
 }
 
+// mergeCurrencyRows folds rows that differ only by currency into one row per
+// key. Converted totals and counts are summed, the unconverted amounts are
+// collected per currency under "original_amounts", currencies converted at 1
+// for lack of a rate under "rates_missing", and the rows are re-sorted by
+// converted total, largest first.
+func mergeCurrencyRows(results []map[string]interface{}, key string) []map[string]interface{} {
+	merged := make([]map[string]interface{}, 0, len(results))
+	index := make(map[string]int)
+
+	for _, result := range results {
+		k := fmt.Sprint(result[key])
+		i, ok := index[k]
+		if !ok {
+			row := make(map[string]interface{}, len(result)+1)
+			for field, value := range result {
+				row[field] = value
+			}
+			row["total_amount"] = money.Amount(0)
+			row["count"] = 0
+			row["original_amounts"] = make(map[string]money.Amount)
+			index[k] = len(merged)
+			merged = append(merged, row)
+			i = len(merged) - 1
+		}
+
+		row := merged[i]
+		row["total_amount"] = toAmount(row["total_amount"]) + toAmount(result["total_amount"])
+		row["count"] = toInt(row["count"]) + toInt(result["count"])
+		if currency, _ := result["currency"].(string); currency != "" {
+			row["original_amounts"].(map[string]money.Amount)[currency] += toAmount(result["original_amount"])
+			if toInt(result["rate_missing"]) > 0 {
+				missing, _ := row["rates_missing"].([]string)
+				row["rates_missing"] = append(missing, currency)
+			}
+		}
+	}
+
+	sort.SliceStable(merged, func(a, b int) bool {
+		return toAmount(merged[a]["total_amount"]) > toAmount(merged[b]["total_amount"])
+	})
+	return merged
+}
+
+func toCurrencyAmounts(val interface{}) map[string]money.Amount {
+	amounts, _ := val.(map[string]money.Amount)
+	if len(amounts) == 0 {
+		return nil
+	}
+	return amounts
+}
+
+func originalAmounts(result map[string]interface{}) map[string]money.Amount {
+	amounts, _ := result["original_amounts"].(map[string]money.Amount)
+	return amounts
+}
+
+// ratesMissing returns the sorted currencies of a merged row that were
+// converted at 1 because no exchange rate was found
+func ratesMissing(result map[string]interface{}) []string {
+	missing, _ := result["rates_missing"].([]string)
+	sort.Strings(missing)
+	return missing
+}
+
+// splitCurrencies parses a comma separated currency list, as returned by
+// GROUP_CONCAT, adding each currency to set
+func splitCurrencies(val interface{}, set map[string]bool) {
+	var list string
+	switch v := val.(type) {
+	case string:
+		list = v
+	case []byte:
+		list = string(v)
+	}
+	for _, currency := range strings.Split(list, ",") {
+		if currency != "" {
+			set[currency] = true
+		}
+	}
+}
+
 func (s *analyticsService) GetSpendingByCategory(userID uint, req *dto.AnalyticsRequest) ([]dto.SpendingByCategoryResponse, error) {
 	startDate, err := req.GetStartDate()
 	if err != nil {
@@ -99,30 +220,127 @@ func (s *analyticsService) GetSpendingByCategory(userID uint, req *dto.Analytics
 		return nil, err
 	}
 
-	results, err := s.analyticsRepo.GetSpendingByCategory(userID, startDate, endDate, 2, req.AssetID) // 2 = expense
+	results, err := s.analyticsRepo.GetSpendingByCategory(userID, startDate, endDate, 2, req.AssetID, s.fx.BaseCurrency(userID)) // 2 = expense
 	if err != nil {
 		return nil, err
 	}
+	results = mergeCurrencyRows(results, "category_id")
 
-	var totalAmount int64
+	var totalAmount money.Amount
 	for _, result := range results {
-		totalAmount += int64(toInt(result["total_amount"]))
+		totalAmount += toAmount(result["total_amount"])
 	}
 
 	responses := make([]dto.SpendingByCategoryResponse, len(results))
 	for i, result := range results {
-		amount := toInt(result["total_amount"])
+		amount := toAmount(result["total_amount"])
 		percentage := float64(0)
 		if totalAmount > 0 {
 			percentage = float64(amount) / float64(totalAmount) * 100
 		}
 
 		responses[i] = dto.SpendingByCategoryResponse{
-			CategoryID:   toUint(result["category_id"]),
-			CategoryName: result["category_name"].(string),
-			TotalAmount:  amount,
-			Percentage:   percentage,
-			Count:        toInt(result["count"]),
+			CategoryID:      toUint(result["category_id"]),
+			CategoryName:    result["category_name"].(string),
+			TotalAmount:     amount,
+			Percentage:      percentage,
+			Count:           toInt(result["count"]),
+			OriginalAmounts: originalAmounts(result),
+			RatesMissing:    ratesMissing(result),
+		}
+	}
+
+	return responses, nil
+}
+
+func (s *analyticsService) GetSpendingByTag(userID uint, req *dto.AnalyticsRequest) ([]dto.SpendingByTagResponse, error) {
+	startDate, err := req.GetStartDate()
+	if err != nil {
+		return nil, err
+	}
+	endDate, err := req.GetEndDate()
+	if err != nil {
+		return nil, err
+	}
+
+	baseCurrency := s.fx.BaseCurrency(userID)
+	results, err := s.analyticsRepo.GetSpendingByTag(userID, startDate, endDate, 2, req.AssetID, baseCurrency) // 2 = expense
+	if err != nil {
+		return nil, err
+	}
+	results = mergeCurrencyRows(results, "tag_id")
+
+	// Tags overlap, so percentages are relative to all expenses in the period
+	totals, err := s.analyticsRepo.GetIncomeVsExpense(userID, startDate, endDate, req.AssetID, baseCurrency)
+	if err != nil {
+		return nil, err
+	}
+	totalExpense := toAmount(totals["total_expense"])
+
+	responses := make([]dto.SpendingByTagResponse, len(results))
+	for i, result := range results {
+		amount := toAmount(result["total_amount"])
+		percentage := float64(0)
+		if totalExpense > 0 {
+			percentage = float64(amount) / float64(totalExpense) * 100
+		}
+
+		color, _ := result["color"].(string)
+		responses[i] = dto.SpendingByTagResponse{
+			TagID:           toUint(result["tag_id"]),
+			TagName:         result["tag_name"].(string),
+			Color:           color,
+			TotalAmount:     amount,
+			Percentage:      percentage,
+			Count:           toInt(result["count"]),
+			OriginalAmounts: originalAmounts(result),
+			RatesMissing:    ratesMissing(result),
+		}
+	}
+
+	return responses, nil
+}
+
+func (s *analyticsService) GetSpendingByPayee(userID uint, req *dto.AnalyticsRequest) ([]dto.SpendingByPayeeResponse, error) {
+	startDate, err := req.GetStartDate()
+	if err != nil {
+		return nil, err
+	}
+	endDate, err := req.GetEndDate()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.analyticsRepo.GetSpendingByPayee(userID, startDate, endDate, 2, req.AssetID, s.fx.BaseCurrency(userID)) // 2 = expense
+	if err != nil {
+		return nil, err
+	}
+	results = mergeCurrencyRows(results, "payee_id")
+
+	var totalAmount money.Amount
+	for _, result := range results {
+		totalAmount += toAmount(result["total_amount"])
+	}
+
+	responses := make([]dto.SpendingByPayeeResponse, len(results))
+	for i, result := range results {
+		amount := toAmount(result["total_amount"])
+		count := toInt(result["count"])
+		percentage := float64(0)
+		if totalAmount > 0 {
+			percentage = float64(amount) / float64(totalAmount) * 100
+		}
+		average := amount.Div(int64(count))
+
+		responses[i] = dto.SpendingByPayeeResponse{
+			PayeeID:         toUint(result["payee_id"]),
+			PayeeName:       result["payee_name"].(string),
+			TotalAmount:     amount,
+			Count:           count,
+			AverageAmount:   average,
+			Percentage:      percentage,
+			OriginalAmounts: originalAmounts(result),
+			RatesMissing:    ratesMissing(result),
 		}
 	}
 
@@ -139,27 +357,38 @@ func (s *analyticsService) GetIncomeVsExpense(userID uint, req *dto.AnalyticsReq
 		return nil, err
 	}
 
-	result, err := s.analyticsRepo.GetIncomeVsExpense(userID, startDate, endDate, req.AssetID)
+	baseCurrency := s.fx.BaseCurrency(userID)
+	result, err := s.analyticsRepo.GetIncomeVsExpense(userID, startDate, endDate, req.AssetID, baseCurrency)
 	if err != nil {
 		return nil, err
 	}
 
-	income := toInt(result["total_income"])
-	expense := toInt(result["total_expense"])
-	net := toInt(result["net_amount"])
+	income := toAmount(result["total_income"])
+	expense := toAmount(result["total_expense"])
+	net := toAmount(result["net_amount"])
 
 	savingsRate := float64(0)
 	if income > 0 {
 		savingsRate = float64(net) / float64(income) * 100
 	}
 
+	var missing []string
+	if currencies, _ := result["rates_missing"].([]string); len(currencies) > 0 {
+		missing = currencies
+		sort.Strings(missing)
+	}
+
 	return &dto.IncomeVsExpenseResponse{
-		TotalIncome:  income,
-		TotalExpense: expense,
-		NetAmount:    net,
-		IncomeCount:  toInt(result["income_count"]),
-		ExpenseCount: toInt(result["expense_count"]),
-		SavingsRate:  savingsRate,
+		Currency:        baseCurrency,
+		TotalIncome:     income,
+		TotalExpense:    expense,
+		NetAmount:       net,
+		IncomeCount:     toInt(result["income_count"]),
+		ExpenseCount:    toInt(result["expense_count"]),
+		SavingsRate:     savingsRate,
+		OriginalIncome:  toCurrencyAmounts(result["original_income"]),
+		OriginalExpense: toCurrencyAmounts(result["original_expense"]),
+		RatesMissing:    missing,
 	}, nil
 }
 
@@ -173,15 +402,15 @@ func (s *analyticsService) GetTrendAnalysis(userID uint, req *dto.AnalyticsReque
 		return nil, err
 	}
 
-	results, err := s.analyticsRepo.GetMonthlyTrend(userID, startDate, endDate, req.AssetID)
+	results, err := s.analyticsRepo.GetMonthlyTrend(userID, startDate, endDate, req.AssetID, s.fx.BaseCurrency(userID))
 	if err != nil {
 		return nil, err
 	}
 
 	dataPoints := make([]dto.TrendDataPoint, len(results))
 	for i, result := range results {
-		income := toInt(result["income"])
-		expense := toInt(result["expense"])
+		income := toAmount(result["income"])
+		expense := toAmount(result["expense"])
 
 		dataPoints[i] = dto.TrendDataPoint{
 			Date:    result["month"].(string),
@@ -195,6 +424,7 @@ func (s *analyticsService) GetTrendAnalysis(userID uint, req *dto.AnalyticsReque
 
 	return &dto.TrendAnalysisResponse{
 		Period:     req.GroupBy,
+		Currency:   summary.Currency,
 		DataPoints: dataPoints,
 		Summary:    *summary,
 	}, nil
@@ -210,15 +440,15 @@ func (s *analyticsService) GetTrendAnalysisWithPayCycle(userID uint, req *dto.An
 		return nil, err
 	}
 
-	results, err := s.analyticsRepo.GetMonthlyTrendByPayCycle(userID, startDate, endDate, req.AssetID, settings)
+	results, err := s.analyticsRepo.GetMonthlyTrendByPayCycle(userID, startDate, endDate, req.AssetID, settings, s.fx.BaseCurrency(userID))
 	if err != nil {
 		return nil, err
 	}
 
 	dataPoints := make([]dto.TrendDataPoint, len(results))
 	for i, result := range results {
-		income := toInt(result["income"])
-		expense := toInt(result["expense"])
+		income := toAmount(result["income"])
+		expense := toAmount(result["expense"])
 
 		// Use period label for date
 		dateStr := result["period"].(string)
@@ -238,6 +468,7 @@ func (s *analyticsService) GetTrendAnalysisWithPayCycle(userID uint, req *dto.An
 
 	return &dto.TrendAnalysisResponse{
 		Period:     "pay_cycle",
+		Currency:   summary.Currency,
 		DataPoints: dataPoints,
 		Summary:    *summary,
 	}, nil
@@ -254,30 +485,33 @@ func (s *analyticsService) GetSpendingByBank(userID uint, req *dto.AnalyticsRequ
 		return nil, err
 	}
 
-	results, err := s.analyticsRepo.GetSpendingByBank(userID, startDate, endDate, req.AssetID)
+	results, err := s.analyticsRepo.GetSpendingByBank(userID, startDate, endDate, req.AssetID, s.fx.BaseCurrency(userID))
 	if err != nil {
 		return nil, err
 	}
+	results = mergeCurrencyRows(results, "bank_id")
 
-	var totalAmount int64
+	var totalAmount money.Amount
 	for _, result := range results {
-		totalAmount += int64(toInt(result["total_amount"]))
+		totalAmount += toAmount(result["total_amount"])
 	}
 
 	responses := make([]dto.SpendingByBankResponse, len(results))
 	for i, result := range results {
-		amount := toInt(result["total_amount"])
+		amount := toAmount(result["total_amount"])
 		percentage := float64(0)
 		if totalAmount > 0 {
 			percentage = float64(amount) / float64(totalAmount) * 100
 		}
 
 		responses[i] = dto.SpendingByBankResponse{
-			BankID:      toUint(result["bank_id"]),
-			BankName:    result["bank_name"].(string),
-			TotalAmount: amount,
-			Percentage:  percentage,
-			Count:       toInt(result["count"]),
+			BankID:          toUint(result["bank_id"]),
+			BankName:        result["bank_name"].(string),
+			TotalAmount:     amount,
+			Percentage:      percentage,
+			Count:           toInt(result["count"]),
+			OriginalAmounts: originalAmounts(result),
+			RatesMissing:    ratesMissing(result),
 		}
 	}
 
@@ -295,23 +529,26 @@ func (s *analyticsService) GetSpendingByAsset(userID uint, req *dto.AnalyticsReq
 		return nil, err
 	}
 
-	results, err := s.analyticsRepo.GetSpendingByAsset(userID, startDate, endDate)
+	results, err := s.analyticsRepo.GetSpendingByAsset(userID, startDate, endDate, s.fx.BaseCurrency(userID))
 	if err != nil {
 		return nil, err
 	}
 
-	var totalExpense int64
+	// Assets hold different currencies, so shares are taken from converted expenses
+	var totalExpense money.Amount
 	for _, result := range results {
-		totalExpense += int64(toInt(result["total_expense"]))
+		totalExpense += toAmount(result["converted_expense"])
 	}
 
 	responses := make([]dto.SpendingByAssetResponse, len(results))
 	for i, result := range results {
-		income := toInt(result["total_income"])
-		expense := toInt(result["total_expense"])
+		income := toAmount(result["total_income"])
+		expense := toAmount(result["total_expense"])
+		convertedIncome := toAmount(result["converted_income"])
+		convertedExpense := toAmount(result["converted_expense"])
 		percentage := float64(0)
 		if totalExpense > 0 {
-			percentage = float64(expense) / float64(totalExpense) * 100
+			percentage = float64(convertedExpense) / float64(totalExpense) * 100
 		}
 
 		responses[i] = dto.SpendingByAssetResponse{
@@ -322,8 +559,12 @@ func (s *analyticsService) GetSpendingByAsset(userID uint, req *dto.AnalyticsReq
 			TotalIncome:       income,
 			TotalExpense:      expense,
 			NetAmount:         income - expense,
+			ConvertedIncome:   convertedIncome,
+			ConvertedExpense:  convertedExpense,
+			ConvertedNet:      convertedIncome - convertedExpense,
 			Percentage:        percentage,
 			TransactionCount:  toInt(result["transaction_count"]),
+			RateMissing:       toInt(result["rate_missing"]) > 0,
 		}
 	}
 
@@ -334,17 +575,17 @@ func (s *analyticsService) GetMonthlyComparison(userID uint, months int, assetID
 	endDate := time.Now()
 	startDate := endDate.AddDate(0, -months, 0)
 
-	results, err := s.analyticsRepo.GetMonthlyTrend(userID, startDate, endDate, assetID)
+	results, err := s.analyticsRepo.GetMonthlyTrend(userID, startDate, endDate, assetID, s.fx.BaseCurrency(userID))
 	if err != nil {
 		return nil, err
 	}
 
 	responses := make([]dto.MonthlyComparisonResponse, len(results))
-	var prevIncome, prevExpense int
+	var prevIncome, prevExpense money.Amount
 
 	for i, result := range results {
-		income := toInt(result["income"])
-		expense := toInt(result["expense"])
+		income := toAmount(result["income"])
+		expense := toAmount(result["expense"])
 
 		incomeChange := float64(0)
 		expenseChange := float64(0)
@@ -417,10 +658,11 @@ func (s *analyticsService) GetDashboardSummary(userID uint, startDate, endDate *
 	budgetSummary := s.getBudgetSummary(userID)
 
 	return &dto.DashboardSummaryResponse{
+		BaseCurrency:       currentMonth.Currency,
 		CurrentMonth:       *currentMonth,
 		LastMonth:          *lastMonth,
 		TopCategories:      topCategories,
-		RecentTransactions: s.toTransactionResponses(transactions),
+		RecentTransactions: s.toTransactionResponses(userID, transactions),
 		BudgetSummary:      budgetSummary,
 	}, nil
 }
@@ -438,20 +680,23 @@ func (s *analyticsService) GetYearlyReport(userID uint, year int, assetID *uint6
 	summary, _ := s.GetIncomeVsExpense(userID, req)
 	monthlyBreakdown, _ := s.GetMonthlyComparison(userID, 12, assetID)
 
-	expenseCategories, _ := s.analyticsRepo.GetSpendingByCategory(userID, startDate, endDate, 2, assetID)
-	incomeCategories, _ := s.analyticsRepo.GetSpendingByCategory(userID, startDate, endDate, 1, assetID)
+	baseCurrency := s.fx.BaseCurrency(userID)
+	expenseCategories, _ := s.analyticsRepo.GetSpendingByCategory(userID, startDate, endDate, 2, assetID, baseCurrency)
+	incomeCategories, _ := s.analyticsRepo.GetSpendingByCategory(userID, startDate, endDate, 1, assetID, baseCurrency)
 
 	topExpense := s.toSpendingByCategoryResponses(expenseCategories, 10)
 	topIncome := s.toSpendingByCategoryResponses(incomeCategories, 10)
 
 	return &dto.YearlyReportResponse{
 		Year:                 year,
+		Currency:             baseCurrency,
 		TotalIncome:          summary.TotalIncome,
 		TotalExpense:         summary.TotalExpense,
 		NetSavings:           summary.NetAmount,
 		MonthlyBreakdown:     monthlyBreakdown,
 		TopExpenseCategories: topExpense,
 		TopIncomeCategories:  topIncome,
+		RatesMissing:         summary.RatesMissing,
 	}, nil
 }
 
@@ -465,17 +710,20 @@ func (s *analyticsService) GetCategoryTrend(userID uint, categoryID uint, req *d
 		return nil, err
 	}
 
-	results, err := s.analyticsRepo.GetCategoryTrend(userID, categoryID, startDate, endDate, req.AssetID)
+	baseCurrency := s.fx.BaseCurrency(userID)
+	results, err := s.analyticsRepo.GetCategoryTrend(userID, categoryID, startDate, endDate, req.AssetID, baseCurrency)
 	if err != nil {
 		return nil, err
 	}
 
 	dataPoints := make([]dto.TrendDataPoint, len(results))
-	var totalAmount int
+	var totalAmount money.Amount
+	missingSet := make(map[string]bool)
 
 	for i, result := range results {
-		amount := toInt(result["amount"])
+		amount := toAmount(result["amount"])
 		totalAmount += amount
+		splitCurrencies(result["rates_missing"], missingSet)
 
 		dataPoints[i] = dto.TrendDataPoint{
 			Date:    result["date"].(string),
@@ -483,22 +731,28 @@ func (s *analyticsService) GetCategoryTrend(userID uint, categoryID uint, req *d
 		}
 	}
 
-	avgAmount := float64(0)
-	if len(dataPoints) > 0 {
-		avgAmount = float64(totalAmount) / float64(len(dataPoints))
+	avgAmount := totalAmount.Div(int64(len(dataPoints)))
+
+	var missing []string
+	for currency := range missingSet {
+		missing = append(missing, currency)
 	}
+	sort.Strings(missing)
 
 	return &dto.CategoryTrendResponse{
 		CategoryID:    categoryID,
 		CategoryName:  "", // Would need to fetch from category repo
+		Currency:      baseCurrency,
 		DataPoints:    dataPoints,
 		TotalAmount:   totalAmount,
 		AverageAmount: avgAmount,
+		RatesMissing:  missing,
 	}, nil
 }
 
 // Helper functions
-func (s *analyticsService) toTransactionResponses(transactions []models.TransactionV2) []dto.TransactionResponse {
+func (s *analyticsService) toTransactionResponses(userID uint, transactions []models.TransactionV2) []dto.TransactionResponse {
+	baseCurrency := s.fx.BaseCurrency(userID)
 	responses := make([]dto.TransactionResponse, len(transactions))
 	for i, t := range transactions {
 		categoryName := ""
@@ -514,6 +768,15 @@ func (s *analyticsService) toTransactionResponses(transactions []models.Transact
 			assetName = t.Asset.Name
 		}
 
+		currency := baseCurrency
+		if t.Asset.Currency != "" {
+			currency = t.Asset.Currency
+		}
+		convertedAmount := t.Amount
+		if rate, ok, err := s.fx.GetRate(userID, currency, baseCurrency, t.Date.Time); err == nil && ok {
+			convertedAmount = t.Amount.MulRate(rate)
+		}
+
 		responses[i] = dto.TransactionResponse{
 			ID:              t.ID,
 			Description:     t.Description,
@@ -523,46 +786,52 @@ func (s *analyticsService) toTransactionResponses(transactions []models.Transact
 			CategoryName:    categoryName,
 			BankName:        bankName,
 			AssetName:       assetName,
+			Currency:        currency,
+			ConvertedAmount: convertedAmount,
 		}
 	}
 	return responses
 }
 
 func (s *analyticsService) toSpendingByCategoryResponses(results []map[string]interface{}, limit int) []dto.SpendingByCategoryResponse {
+	results = mergeCurrencyRows(results, "category_id")
 	if len(results) > limit {
 		results = results[:limit]
 	}
 
-	var totalAmount int64
+	var totalAmount money.Amount
 	for _, result := range results {
-		totalAmount += int64(toInt(result["total_amount"]))
+		totalAmount += toAmount(result["total_amount"])
 	}
 
 	responses := make([]dto.SpendingByCategoryResponse, len(results))
 	for i, result := range results {
-		amount := toInt(result["total_amount"])
+		amount := toAmount(result["total_amount"])
 		percentage := float64(0)
 		if totalAmount > 0 {
 			percentage = float64(amount) / float64(totalAmount) * 100
 		}
 
 		responses[i] = dto.SpendingByCategoryResponse{
-			CategoryID:   toUint(result["category_id"]),
-			CategoryName: result["category_name"].(string),
-			TotalAmount:  amount,
-			Percentage:   percentage,
-			Count:        toInt(result["count"]),
+			CategoryID:      toUint(result["category_id"]),
+			CategoryName:    result["category_name"].(string),
+			TotalAmount:     amount,
+			Percentage:      percentage,
+			Count:           toInt(result["count"]),
+			OriginalAmounts: originalAmounts(result),
+			RatesMissing:    ratesMissing(result),
 		}
 	}
 
 	return responses
 }
 
+// getBudgetSummary totals the active budgets, measuring each against its
+// available amount so rollover carry-over counts
 func (s *analyticsService) getBudgetSummary(userID uint) dto.BudgetSummaryResponse {
-	budgets, _ := s.budgetRepo.FindActiveBudgets(userID)
+	budgets, _ := s.budgets.GetBudgetStatus(userID)
 
-	totalBudgeted := 0
-	totalSpent := 0
+	var totalBudgeted, totalSpent money.Amount
 	exceededCount := 0
 	warningCount := 0
 	activeCount := 0
@@ -571,15 +840,13 @@ func (s *analyticsService) getBudgetSummary(userID uint) dto.BudgetSummaryRespon
 		if budget.IsActive {
 			activeCount++
 		}
-		totalBudgeted += budget.Amount
+		totalBudgeted += budget.AvailableAmount
+		totalSpent += budget.SpentAmount
 
-		spent, _ := s.budgetRepo.GetSpentAmount(budget.ID, budget.StartDate.Time, budget.EndDate.Time)
-		totalSpent += spent
-
-		percentage := float64(spent) / float64(budget.Amount) * 100
-		if percentage >= 100 {
+		switch budget.Status {
+		case "exceeded":
 			exceededCount++
-		} else if percentage >= float64(budget.AlertAt) {
+		case "warning":
 			warningCount++
 		}
 	}
@@ -599,3 +866,95 @@ func (s *analyticsService) getBudgetSummary(userID uint) dto.BudgetSummaryRespon
 		AverageUtilization: avgUtilization,
 	}
 }
+
+// GetNetWorth values every asset at the end of each period from its latest
+// daily snapshot, converts the values into the base currency at the rates of
+// that day and splits them into assets and liabilities. Without start_date
+// the last twelve months are shown, by month unless group_by is given.
+func (s *analyticsService) GetNetWorth(userID uint, req *dto.AnalyticsRequest) (*dto.NetWorthResponse, error) {
+	endDate, err := req.GetEndDate()
+	if err != nil {
+		return nil, err
+	}
+	endDate = utils.TruncateToDay(endDate)
+	startDate := time.Date(endDate.Year(), endDate.Month()-11, 1, 0, 0, 0, 0, time.UTC)
+	if req.StartDate != "" {
+		if startDate, err = req.GetStartDate(); err != nil {
+			return nil, err
+		}
+	}
+	if startDate.After(endDate) {
+		return nil, errors.New("start_date must not be after end_date")
+	}
+	groupBy := req.GroupBy
+	if groupBy == "" {
+		groupBy = "month"
+	}
+
+	latest, err := s.snapshotRepo.FindLatestBefore(userID, startDate)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := s.snapshotRepo.FindBetween(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[uint64]models.BalanceSnapshot, len(latest))
+	for _, snapshot := range latest {
+		current[snapshot.AssetID] = snapshot
+	}
+
+	response := &dto.NetWorthResponse{
+		Currency: s.fx.BaseCurrency(userID),
+		GroupBy:  groupBy,
+	}
+	missing := make(map[string]bool)
+	next := 0
+	for _, period := range utils.PeriodsBetween(groupBy, startDate, endDate) {
+		for next < len(snapshots) && !snapshots[next].SnapshotDate.After(period.EndDate) {
+			current[snapshots[next].AssetID] = snapshots[next]
+			next++
+		}
+
+		point := dto.NetWorthDataPoint{
+			Period:    period.PeriodLabel,
+			StartDate: period.StartDate.Format("2006-01-02"),
+			EndDate:   period.EndDate.Format("2006-01-02"),
+		}
+		rates := make(map[string]float64)
+		for _, snapshot := range current {
+			rate, ok := rates[snapshot.Currency]
+			if !ok {
+				var found bool
+				rate, found, err = s.fx.GetRate(userID, snapshot.Currency, response.Currency, period.EndDate)
+				if err != nil {
+					return nil, err
+				}
+				if !found {
+					rate = 1
+					missing[snapshot.Currency] = true
+				}
+				rates[snapshot.Currency] = rate
+			}
+
+			value := money.New(snapshot.Worth(), snapshot.Currency).Convert(rate, response.Currency).Amount
+			if value < 0 {
+				point.Liabilities -= value
+			} else {
+				point.Assets += value
+			}
+		}
+		point.NetWorth = point.Assets - point.Liabilities
+		response.DataPoints = append(response.DataPoints, point)
+	}
+
+	if n := len(response.DataPoints); n > 0 {
+		response.Change = response.DataPoints[n-1].NetWorth - response.DataPoints[0].NetWorth
+	}
+	for currency := range missing {
+		response.RatesMissing = append(response.RatesMissing, currency)
+	}
+	sort.Strings(response.RatesMissing)
+	return response, nil
+}
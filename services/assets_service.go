@@ -2,30 +2,49 @@ package services
 
 import (
     "errors"
+    "my-api/dto"
     "my-api/models"
+    "my-api/money"
     "my-api/repositories"
+    "slices"
+    "sort"
+    "time"
 )
 
 type AssetService struct {
-    repo *repositories.AssetRepository
+    repo        *repositories.AssetRepository
+    fx          ExchangeRateService
+    investments InvestmentService
 }
 
 type CreateAssetDTO struct {
     Name     string  `json:"name"`
     Type     string  `json:"type"`
-    Balance  float64 `json:"balance"`
+    Balance  money.Amount `json:"balance"`
     Currency string  `json:"currency"`
     BankName string  `json:"bank_name"`
     AccountNo string `json:"account_no"`
+
+    CreditLimit         money.Amount `json:"credit_limit"`
+    StatementClosingDay int          `json:"statement_closing_day"`
+    PaymentDueDay       int          `json:"payment_due_day"`
+    MinPaymentPercent   float64      `json:"min_payment_percent"`
+    MinPaymentAmount    money.Amount `json:"min_payment_amount"`
 }
 
 type UpdateAssetDTO struct {
     Name      *string  `json:"name"`
     Type      *string  `json:"type"`
-    Balance   *float64 `json:"balance"`
+    Balance   *money.Amount `json:"balance"`
     Currency  *string  `json:"currency"`
     BankName  *string  `json:"bank_name"`
     AccountNo *string  `json:"account_no"`
+
+    CreditLimit         *money.Amount `json:"credit_limit"`
+    StatementClosingDay *int          `json:"statement_closing_day"`
+    PaymentDueDay       *int          `json:"payment_due_day"`
+    MinPaymentPercent   *float64      `json:"min_payment_percent"`
+    MinPaymentAmount    *money.Amount `json:"min_payment_amount"`
 }
 
 func (dto *CreateAssetDTO) validate() error {
@@ -35,14 +54,38 @@ func (dto *CreateAssetDTO) validate() error {
     if dto.Currency == "" {
         return errors.New("currency is required")
     }
-    if dto.Balance < 0 {
-        return errors.New("balance cannot be negative")
+    return nil
+}
+
+// validateAsset checks the type-specific rules. Types outside AssetTypes are
+// only rejected when they are being set, so wallets created before typed
+// assets keep working as plain cash-like wallets.
+func validateAsset(asset *models.Asset, typeChanged bool, balance money.Amount) error {
+    if typeChanged && asset.Type != "" && !slices.Contains(models.AssetTypes, asset.Type) {
+        return errors.New("invalid asset type")
+    }
+    if asset.CreditLimit < 0 {
+        return errors.New("credit_limit cannot be negative")
+    }
+    if asset.StatementClosingDay < 0 || asset.StatementClosingDay > 31 ||
+        asset.PaymentDueDay < 0 || asset.PaymentDueDay > 31 {
+        return errors.New("statement_closing_day and payment_due_day must be between 1 and 31")
+    }
+    if asset.MinPaymentPercent < 0 || asset.MinPaymentPercent > 100 {
+        return errors.New("min_payment_percent must be between 0 and 100")
+    }
+    if asset.MinPaymentAmount < 0 {
+        return errors.New("min_payment_amount cannot be negative")
+    }
+    // Liabilities open with what is already owed as a negative balance
+    if balance < 0 && !asset.IsLiability() && balance < -asset.CreditLimit {
+        return errors.New("balance cannot be below the credit limit")
     }
     return nil
 }
 
-func NewAssetService(repo *repositories.AssetRepository) *AssetService {
-    return &AssetService{repo: repo}
+func NewAssetService(repo *repositories.AssetRepository, fx ExchangeRateService, investments InvestmentService) *AssetService {
+    return &AssetService{repo: repo, fx: fx, investments: investments}
 }
 
 func (s *AssetService) CreateAsset(userID uint, dto CreateAssetDTO) (*models.Asset, error) {
@@ -57,15 +100,48 @@ func (s *AssetService) CreateAsset(userID uint, dto CreateAssetDTO) (*models.Ass
         Currency:  dto.Currency,
         BankName:  dto.BankName,
         AccountNo: dto.AccountNo,
+
+        CreditLimit:         dto.CreditLimit,
+        StatementClosingDay: dto.StatementClosingDay,
+        PaymentDueDay:       dto.PaymentDueDay,
+        MinPaymentPercent:   dto.MinPaymentPercent,
+        MinPaymentAmount:    dto.MinPaymentAmount,
     }
-    if err := s.repo.CreateAsset(asset); err != nil {
+    if err := validateAsset(asset, true, asset.Balance); err != nil {
+        return nil, err
+    }
+    if err := s.repo.CreateWithOpeningBalance(asset); err != nil {
         return nil, err
     }
     return asset, nil
 }
 
 func (s *AssetService) ListAssets(userID uint) ([]models.Asset, error) {
-    return s.repo.GetAssetsByUser(uint64(userID))
+    assets, err := s.repo.GetAssetsByUser(uint64(userID))
+    if err != nil {
+        return nil, err
+    }
+    if err := s.fillClearedBalances(assets); err != nil {
+        return nil, err
+    }
+    return assets, nil
+}
+
+// fillClearedBalances sets ClearedBalance, the balance without pending
+// transactions, on each asset
+func (s *AssetService) fillClearedBalances(assets []models.Asset) error {
+    ids := make([]uint64, len(assets))
+    for i := range assets {
+        ids[i] = assets[i].ID
+    }
+    pending, err := s.repo.GetPendingNet(ids)
+    if err != nil {
+        return err
+    }
+    for i := range assets {
+        assets[i].ClearedBalance = assets[i].Balance - pending[assets[i].ID]
+    }
+    return nil
 }
 
 func (s *AssetService) GetAsset(userID uint, id uint) (*models.Asset, error) {
@@ -76,7 +152,11 @@ func (s *AssetService) GetAsset(userID uint, id uint) (*models.Asset, error) {
     if asset.UserID != uint64(userID) {
         return nil, errors.New("unauthorized")
     }
-    return asset, nil
+    assets := []models.Asset{*asset}
+    if err := s.fillClearedBalances(assets); err != nil {
+        return nil, err
+    }
+    return &assets[0], nil
 }
 
 func (s *AssetService) UpdateAsset(userID uint, id uint, dto UpdateAssetDTO) (*models.Asset, error) {
@@ -89,14 +169,21 @@ func (s *AssetService) UpdateAsset(userID uint, id uint, dto UpdateAssetDTO) (*m
     }
     if dto.Name != nil { asset.Name = *dto.Name }
     if dto.Type != nil { asset.Type = *dto.Type }
-    if dto.Balance != nil {
-        if *dto.Balance < 0 { return nil, errors.New("balance cannot be negative") }
-        asset.Balance = *dto.Balance
-    }
     if dto.Currency != nil { asset.Currency = *dto.Currency }
     if dto.BankName != nil { asset.BankName = *dto.BankName }
     if dto.AccountNo != nil { asset.AccountNo = *dto.AccountNo }
-    if err := s.repo.UpdateAsset(asset); err != nil {
+    if dto.CreditLimit != nil { asset.CreditLimit = *dto.CreditLimit }
+    if dto.StatementClosingDay != nil { asset.StatementClosingDay = *dto.StatementClosingDay }
+    if dto.PaymentDueDay != nil { asset.PaymentDueDay = *dto.PaymentDueDay }
+    if dto.MinPaymentPercent != nil { asset.MinPaymentPercent = *dto.MinPaymentPercent }
+    if dto.MinPaymentAmount != nil { asset.MinPaymentAmount = *dto.MinPaymentAmount }
+    balance := asset.Balance
+    if dto.Balance != nil { balance = *dto.Balance }
+    if err := validateAsset(asset, dto.Type != nil, balance); err != nil {
+        return nil, err
+    }
+    // A balance change is posted to the ledger as an adjustment
+    if err := s.repo.UpdateWithAdjustment(asset, dto.Balance); err != nil {
         return nil, err
     }
     return asset, nil
@@ -113,14 +200,72 @@ func (s *AssetService) DeleteAsset(userID uint, id uint) error {
     return s.repo.DeleteAsset(uint64(id))
 }
 
-func (s *AssetService) Summary(userID uint) (map[string]float64, error) {
+// ListDeletedAssets returns the user's wallets in the trash
+func (s *AssetService) ListDeletedAssets(userID uint) ([]models.Asset, error) {
+    return s.repo.GetDeletedAssetsByUser(uint64(userID))
+}
+
+// RestoreAsset takes a wallet out of the trash. Its balance was kept while it
+// was there, so it counts towards totals again right away.
+func (s *AssetService) RestoreAsset(userID uint, id uint) error {
+    return s.repo.RestoreAsset(uint64(id), uint64(userID))
+}
+
+// Summary totals wallet balances and the market value of investment holdings
+// per currency, and converts each total into the user's base currency at the
+// latest known rate
+func (s *AssetService) Summary(userID uint) (*dto.WalletSummaryResponse, error) {
     assets, err := s.ListAssets(userID)
     if err != nil {
         return nil, err
     }
-    summary := make(map[string]float64)
+    now := time.Now()
+    holdings, err := s.investments.MarketValues(userID, now)
+    if err != nil {
+        return nil, err
+    }
+
+    balances := make(map[string]money.Money)
+    values := make(map[string]money.Amount)
+    var currencies []string
     for _, a := range assets {
-        summary[a.Currency] += a.Balance
+        balance := a.BalanceMoney()
+        total, ok := balances[balance.Currency]
+        if !ok {
+            currencies = append(currencies, balance.Currency)
+            total = money.New(0, balance.Currency)
+        }
+        if balances[balance.Currency], err = total.Add(balance); err != nil {
+            return nil, err
+        }
+        values[balance.Currency] += holdings[a.ID]
+    }
+    sort.Strings(currencies)
+
+    summary := &dto.WalletSummaryResponse{
+        BaseCurrency: s.fx.BaseCurrency(userID),
+        Currencies:   make([]dto.CurrencyBalance, 0, len(currencies)),
+    }
+    for _, currency := range currencies {
+        rate, found, err := s.fx.GetRate(userID, currency, summary.BaseCurrency, now)
+        if err != nil {
+            return nil, err
+        }
+        if !found {
+            rate = 1
+        }
+
+        worth := money.New(balances[currency].Amount+values[currency], currency)
+        converted := worth.Convert(rate, summary.BaseCurrency)
+        summary.Currencies = append(summary.Currencies, dto.CurrencyBalance{
+            Currency:         currency,
+            Balance:          balances[currency].Amount,
+            HoldingsValue:    values[currency],
+            Rate:             rate,
+            ConvertedBalance: converted.Amount,
+            RateMissing:      !found,
+        })
+        summary.TotalBalance += converted.Amount
     }
     return summary, nil
 }
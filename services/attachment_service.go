@@ -0,0 +1,211 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"my-api/dto"
+	"my-api/models"
+	"my-api/repositories"
+	"my-api/storage"
+	"my-api/utils"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxAttachmentSize is the largest receipt file accepted (10 MB)
+const MaxAttachmentSize = 10 << 20
+
+// allowedAttachmentTypes maps the sniffed content type of an upload to the
+// extension used in storage
+var allowedAttachmentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"image/gif":       ".gif",
+	"application/pdf": ".pdf",
+}
+
+type AttachmentService interface {
+	UploadAttachment(userID uint, transactionID uint, fileName string, reader io.Reader) (*dto.AttachmentResponse, error)
+	GetAttachments(userID uint, transactionID uint) ([]dto.AttachmentResponse, error)
+	OpenAttachment(id uint, userID uint) (*models.Attachment, io.ReadCloser, error)
+	DeleteAttachment(id uint, userID uint) error
+}
+
+type attachmentService struct {
+	repo            repositories.AttachmentRepository
+	transactionRepo repositories.TransactionV2Repository
+	fileStorage     storage.FileStorage
+}
+
+func NewAttachmentService(repo repositories.AttachmentRepository, transactionRepo repositories.TransactionV2Repository, fileStorage storage.FileStorage) AttachmentService {
+	return &attachmentService{
+		repo:            repo,
+		transactionRepo: transactionRepo,
+		fileStorage:     fileStorage,
+	}
+}
+
+// UploadAttachment stores a receipt for the transaction. The content type is
+// detected from the file itself rather than trusted from the client.
+func (s *attachmentService) UploadAttachment(userID uint, transactionID uint, fileName string, reader io.Reader) (*dto.AttachmentResponse, error) {
+	if _, err := s.transactionRepo.GetByID(transactionID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("transaction not found")
+		}
+		return nil, err
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(reader, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	head = head[:n]
+	if n == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	contentType := strings.Split(http.DetectContentType(head), ";")[0]
+	extension, ok := allowedAttachmentTypes[contentType]
+	if !ok {
+		return nil, errors.New("unsupported file type: only JPEG, PNG, WebP, GIF and PDF are allowed")
+	}
+
+	key := fmt.Sprintf("%d/%d/%s%s", userID, transactionID, randomKey(), extension)
+	// Read one byte past the limit so oversized uploads can be detected
+	content := io.LimitReader(io.MultiReader(bytes.NewReader(head), reader), MaxAttachmentSize+1)
+	size, err := s.fileStorage.Save(key, content)
+	if err != nil {
+		return nil, err
+	}
+	if size > MaxAttachmentSize {
+		s.fileStorage.Delete(key)
+		return nil, errors.New("file is too large (max 10 MB)")
+	}
+
+	attachment := &models.Attachment{
+		TransactionID: transactionID,
+		UserID:        userID,
+		FileName:      cleanFileName(fileName, extension),
+		ContentType:   contentType,
+		Size:          size,
+		StorageKey:    key,
+	}
+	if err := s.repo.Create(attachment); err != nil {
+		s.fileStorage.Delete(key)
+		return nil, err
+	}
+
+	return toAttachmentResponse(attachment), nil
+}
+
+func (s *attachmentService) GetAttachments(userID uint, transactionID uint) ([]dto.AttachmentResponse, error) {
+	if _, err := s.transactionRepo.GetByID(transactionID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("transaction not found")
+		}
+		return nil, err
+	}
+
+	attachments, err := s.repo.FindByTransaction(transactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.AttachmentResponse, len(attachments))
+	for i := range attachments {
+		responses[i] = *toAttachmentResponse(&attachments[i])
+	}
+	return responses, nil
+}
+
+// OpenAttachment returns the attachment and a reader for its content. The
+// caller must close the reader.
+func (s *attachmentService) OpenAttachment(id uint, userID uint) (*models.Attachment, io.ReadCloser, error) {
+	attachment, err := s.findAttachment(id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, err := s.fileStorage.Open(attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, errors.New("attachment not found")
+		}
+		return nil, nil, err
+	}
+	return attachment, reader, nil
+}
+
+func (s *attachmentService) DeleteAttachment(id uint, userID uint) error {
+	attachment, err := s.findAttachment(id, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(attachment.ID, userID); err != nil {
+		return err
+	}
+
+	removeAttachmentFiles(s.fileStorage, []models.Attachment{*attachment})
+	return nil
+}
+
+func (s *attachmentService) findAttachment(id uint, userID uint) (*models.Attachment, error) {
+	attachment, err := s.repo.FindByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("attachment not found")
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// removeAttachmentFiles deletes stored files once their rows are gone. A
+// failure only leaves an orphaned file behind, so it is logged, not returned.
+func removeAttachmentFiles(fileStorage storage.FileStorage, attachments []models.Attachment) {
+	for _, attachment := range attachments {
+		if err := fileStorage.Delete(attachment.StorageKey); err != nil {
+			utils.LogWarningf("Failed to delete attachment file %s: %v", attachment.StorageKey, err)
+		}
+	}
+}
+
+func toAttachmentResponse(attachment *models.Attachment) *dto.AttachmentResponse {
+	return &dto.AttachmentResponse{
+		ID:            attachment.ID,
+		TransactionID: attachment.TransactionID,
+		FileName:      attachment.FileName,
+		ContentType:   attachment.ContentType,
+		Size:          attachment.Size,
+		DownloadURL:   fmt.Sprintf("/api/v2/attachments/%d", attachment.ID),
+		CreatedAt:     attachment.CreatedAt,
+	}
+}
+
+// cleanFileName keeps only the base name of the uploaded file and makes sure
+// it carries an extension matching its content
+func cleanFileName(name, extension string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == "" {
+		name = "receipt"
+	}
+	if !strings.EqualFold(filepath.Ext(name), extension) && !(extension == ".jpg" && strings.EqualFold(filepath.Ext(name), ".jpeg")) {
+		name += extension
+	}
+	return truncate(name, 255)
+}
+
+func randomKey() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
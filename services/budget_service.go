@@ -6,8 +6,11 @@ import (
 	"gorm.io/gorm"
 	"my-api/dto"
 	"my-api/models"
+	"my-api/money"
 	"my-api/repositories"
 	"my-api/utils"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -18,26 +21,47 @@ type BudgetService interface {
 	UpdateBudget(id uint, userID uint, req *dto.UpdateBudgetRequest) (*dto.BudgetResponse, error)
 	DeleteBudget(id uint, userID uint) error
 	GetBudgetStatus(userID uint) ([]dto.BudgetWithSpendingResponse, error)
+	GetBudgetHistory(id uint, userID uint) ([]dto.BudgetPeriodResponse, error)
 	CheckBudgetAlerts(userID uint) error
+	RealignPayCycleBudgets(userID uint) error
 	GetUserAlerts(userID uint, unreadOnly bool) ([]dto.BudgetAlertResponse, error)
 	GetUserAlertsPaginated(userID uint, filter *dto.AlertFilterRequest) (*dto.PaginationResponse, error)
 	MarkAlertAsRead(alertID uint, userID uint) error
 	MarkAllAlertsAsRead(userID uint) error
 }
 
+// maxAlertThresholds caps how many alert thresholds a budget can have
+const maxAlertThresholds = 10
+
 type budgetService struct {
-	repo repositories.BudgetRepository
+	repo         repositories.BudgetRepository
+	settingsRepo repositories.UserSettingsRepository
 }
 
-func NewBudgetService(repo repositories.BudgetRepository) BudgetService {
-	return &budgetService{repo: repo}
+func NewBudgetService(repo repositories.BudgetRepository, settingsRepo repositories.UserSettingsRepository) BudgetService {
+	return &budgetService{repo: repo, settingsRepo: settingsRepo}
 }
 
 func (s *budgetService) CreateBudget(userID uint, req *dto.CreateBudgetRequest) (*dto.BudgetResponse, error) {
-	endDate := s.calculateEndDate(req.StartDate.Time, req.Period)
+	categoryIDs := uniqueIDs(append([]uint{req.CategoryID}, req.CategoryIDs...))
+	if len(categoryIDs) == 0 {
+		return nil, errors.New("at least one category is required")
+	}
+	if err := validateThresholds(req.AlertThresholds); err != nil {
+		return nil, err
+	}
+	assetIDs := uniqueIDs(req.AssetIDs)
+
+	settings := s.payCycleSettings(userID, req.Period)
+	endDate := s.calculateEndDate(req.StartDate.Time, req.Period, settings)
+	recurring := req.Recurring == nil || *req.Recurring
 
-	// Check for overlapping budgets
-	existing, _ := s.repo.FindBudgetByCategory(userID, req.CategoryID, req.StartDate.Time, endDate.Time, nil)
+	// Check for overlapping budgets. A recurring budget never ends.
+	var overlapEnd *time.Time
+	if !recurring {
+		overlapEnd = &endDate.Time
+	}
+	existing, _ := s.repo.FindOverlappingBudget(userID, categoryIDs, assetIDs, req.StartDate.Time, overlapEnd, 0)
 	if existing != nil && existing.ID > 0 {
 		return nil, errors.New("budget already exists for this category in the specified period")
 	}
@@ -48,22 +72,30 @@ func (s *budgetService) CreateBudget(userID uint, req *dto.CreateBudgetRequest)
 	}
 
 	budget := &models.Budget{
-		UserID:      userID,
-		CategoryID:  req.CategoryID,
-		Amount:      req.Amount,
-		Period:      req.Period,
-		StartDate:   req.StartDate,
-		EndDate:     endDate,
-		IsActive:    true,
-		AlertAt:     alertAt,
-		Description: req.Description,
+		UserID:         userID,
+		CategoryID:     categoryIDs[0],
+		Name:           req.Name,
+		Amount:         req.Amount,
+		Period:         req.Period,
+		Recurring:      recurring,
+		StartDate:      req.StartDate,
+		EndDate:        endDate,
+		IsActive:       true,
+		AlertAt:        alertAt,
+		ProjectedAlert: req.ProjectedAlert,
+		Rollover:       req.Rollover,
+		Description:    req.Description,
+		Categories:     categoriesFromIDs(categoryIDs),
+		Assets:         assetsFromIDs(assetIDs),
 	}
+	budget.SetThresholds(req.AlertThresholds)
 
 	if err := s.repo.Create(budget); err != nil {
 		return nil, err
 	}
 
 	budget, _ = s.repo.FindByID(budget.ID, userID)
+	s.syncPeriods(budget)
 	return s.toBudgetResponse(budget), nil
 }
 
@@ -76,7 +108,7 @@ func (s *budgetService) GetBudgetByID(id uint, userID uint) (*dto.BudgetWithSpen
 		return nil, err
 	}
 
-	return s.toBudgetWithSpendingResponse(budget), nil
+	return s.toBudgetWithSpendingResponse(budget)
 }
 
 func (s *budgetService) GetAllBudgets(userID uint, filter *dto.BudgetFilterRequest) (*dto.PaginationResponse, error) {
@@ -88,8 +120,12 @@ func (s *budgetService) GetAllBudgets(userID uint, filter *dto.BudgetFilterReque
 	}
 
 	responses := make([]dto.BudgetWithSpendingResponse, len(budgets))
-	for i, budget := range budgets {
-		responses[i] = *s.toBudgetWithSpendingResponse(&budget)
+	for i := range budgets {
+		response, err := s.toBudgetWithSpendingResponse(&budgets[i])
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = *response
 	}
 
 	return dto.NewPaginationResponse(responses, filter.Page, filter.PageSize, total), nil
@@ -104,22 +140,62 @@ func (s *budgetService) UpdateBudget(id uint, userID uint, req *dto.UpdateBudget
 		return nil, err
 	}
 
+	if req.Name != nil {
+		budget.Name = *req.Name
+	}
+	scopeChanged := false
+	if categoryIDs := uniqueIDs(req.CategoryIDs); len(categoryIDs) > 0 {
+		budget.CategoryID = categoryIDs[0]
+		budget.Categories = categoriesFromIDs(categoryIDs)
+		scopeChanged = true
+	}
+	if req.AssetIDs != nil {
+		budget.Assets = assetsFromIDs(uniqueIDs(*req.AssetIDs))
+		scopeChanged = true
+	}
 	if req.Amount > 0 {
 		budget.Amount = req.Amount
 	}
 	if req.AlertAt > 0 {
 		budget.AlertAt = req.AlertAt
 	}
+	if req.AlertThresholds != nil {
+		if err := validateThresholds(*req.AlertThresholds); err != nil {
+			return nil, err
+		}
+		budget.SetThresholds(*req.AlertThresholds)
+	}
+	if req.ProjectedAlert != nil {
+		budget.ProjectedAlert = *req.ProjectedAlert
+	}
 	if req.Description != "" {
 		budget.Description = req.Description
 	}
 	if req.IsActive != nil {
 		budget.IsActive = *req.IsActive
 	}
+	if req.Rollover != nil {
+		budget.Rollover = *req.Rollover
+	}
+
+	if scopeChanged && budget.IsActive {
+		var overlapEnd *time.Time
+		if !budget.Recurring {
+			overlapEnd = &budget.EndDate.Time
+		}
+		existing, _ := s.repo.FindOverlappingBudget(userID, budget.CategoryIDs(), budget.AssetIDs(), budget.StartDate.Time, overlapEnd, budget.ID)
+		if existing != nil && existing.ID > 0 {
+			return nil, errors.New("budget already exists for this category in the specified period")
+		}
+	}
 
 	if err := s.repo.Update(budget); err != nil {
 		return nil, err
 	}
+	if scopeChanged {
+		budget, _ = s.repo.FindByID(budget.ID, userID)
+	}
+	s.syncPeriods(budget)
 
 	return s.toBudgetResponse(budget), nil
 }
@@ -143,52 +219,157 @@ func (s *budgetService) GetBudgetStatus(userID uint) ([]dto.BudgetWithSpendingRe
 	}
 
 	responses := make([]dto.BudgetWithSpendingResponse, len(budgets))
-	for i, budget := range budgets {
-		responses[i] = *s.toBudgetWithSpendingResponse(&budget)
+	for i := range budgets {
+		response, err := s.toBudgetWithSpendingResponse(&budgets[i])
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = *response
 	}
 
 	return responses, nil
 }
 
+// GetBudgetHistory lists every period of the budget up to the current one,
+// oldest first, with what was planned and what was actually spent
+func (s *budgetService) GetBudgetHistory(id uint, userID uint) ([]dto.BudgetPeriodResponse, error) {
+	budget, err := s.repo.FindByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("budget not found")
+		}
+		return nil, err
+	}
+
+	periods, err := s.resolvePeriods(budget, time.Now(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.BudgetPeriodResponse, len(periods))
+	for i, period := range periods {
+		available := period.Available()
+		percentage := percentageUsed(period.Spent, available)
+		responses[i] = dto.BudgetPeriodResponse{
+			StartDate:       period.StartDate,
+			EndDate:         period.EndDate,
+			PlannedAmount:   period.Amount,
+			CarryOver:       period.CarryIn,
+			AvailableAmount: available,
+			ActualAmount:    period.Spent,
+			RemainingAmount: period.Remaining,
+			PercentageUsed:  percentage,
+			Status:          budgetStatus(percentage, budget.AlertAt),
+			IsCurrent:       i == len(periods)-1,
+		}
+	}
+
+	return responses, nil
+}
+
+// CheckBudgetAlerts stores the periods of all of the user's budgets and fires
+// the alerts of the active ones for their current period. Every transaction
+// write path calls it, so stored periods keep up with spending whichever
+// endpoint is used. Each threshold fires once per period: when spending jumps
+// past several at once only the highest is reported. Budgets with
+// ProjectedAlert also warn once per period when the spending pace so far
+// would exceed the available amount by the period's end.
 func (s *budgetService) CheckBudgetAlerts(userID uint) error {
 	budgets, err := s.repo.FindActiveBudgets(userID)
 	if err != nil {
 		return err
 	}
 
-	for _, budget := range budgets {
-		spent, _ := s.repo.GetSpentAmount(budget.ID, budget.StartDate.Time, budget.EndDate.Time)
-		percentage := float64(spent) / float64(budget.Amount) * 100
+	now := time.Now()
+	resolved := make(map[uint]bool, len(budgets))
+	for i := range budgets {
+		budget := &budgets[i]
+		resolved[budget.ID] = true
+		period, err := s.resolvePeriod(budget, true)
+		if err != nil {
+			return err
+		}
+		available := period.Available()
+		percentage := percentageUsed(period.Spent, available)
 
-		if percentage >= float64(budget.AlertAt) {
-			// Check if alert already exists for this budget at this percentage level
-			existingAlerts, _ := s.repo.GetUserAlerts(userID, true)
-			alertExists := false
-			for _, existing := range existingAlerts {
-				if existing.BudgetID == budget.ID && existing.Percentage >= int(percentage)-5 {
-					alertExists = true
-					break
-				}
+		if threshold := crossedThreshold(budget.Thresholds(), percentage); threshold > period.AlertedThreshold {
+			statusMsg := "reached"
+			if percentage >= 100 {
+				statusMsg = "exceeded"
 			}
-
-			// Only create alert if it doesn't exist
-			if !alertExists {
-				statusMsg := "reached"
-				if percentage >= 100 {
-					statusMsg = "exceeded"
-				}
-				alert := &models.BudgetAlert{
-					BudgetID:    budget.ID,
-					UserID:      userID,
-					Percentage:  int(percentage),
-					SpentAmount: spent,
-					Message:     fmt.Sprintf("You have %s %.0f%% of your %s budget", statusMsg, percentage, budget.Category.CategoryName),
-				}
-				s.repo.CreateAlert(alert)
+			if _, err := s.repo.CreateThresholdAlert(period.StartDate.Time, &models.BudgetAlert{
+				BudgetID:    budget.ID,
+				UserID:      userID,
+				Kind:        models.BudgetAlertThreshold,
+				Threshold:   threshold,
+				Percentage:  int(percentage),
+				SpentAmount: period.Spent,
+				Message:     fmt.Sprintf("You have %s %.0f%% of your %s budget", statusMsg, percentage, budgetLabel(budget)),
+			}); err != nil {
+				return err
 			}
 		}
+
+		if !budget.ProjectedAlert || period.ProjectedAlerted || percentage >= 100 {
+			continue
+		}
+		projected, ok := projectedSpending(period, now)
+		if !ok || projected <= available {
+			continue
+		}
+		projectedPercentage := percentageUsed(projected, available)
+		if _, err := s.repo.CreateProjectedAlert(period.StartDate.Time, &models.BudgetAlert{
+			BudgetID:    budget.ID,
+			UserID:      userID,
+			Kind:        models.BudgetAlertProjected,
+			Threshold:   100,
+			Percentage:  int(projectedPercentage),
+			SpentAmount: period.Spent,
+			Message: fmt.Sprintf("At your current pace you will spend %.0f%% of your %s budget by %s",
+				projectedPercentage, budgetLabel(budget), period.EndDate.Format("2006-01-02")),
+		}); err != nil {
+			return err
+		}
 	}
 
+	// Budgets that no longer alert are kept up to date as well, so backdated
+	// changes reach their stored periods
+	all, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return err
+	}
+	for i := range all {
+		if resolved[all[i].ID] {
+			continue
+		}
+		if _, err := s.resolvePeriods(&all[i], now, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RealignPayCycleBudgets recomputes the windows of the user's pay cycle
+// budgets after their pay cycle settings changed, dropping stored periods
+// that no longer line up
+func (s *budgetService) RealignPayCycleBudgets(userID uint) error {
+	budgets, err := s.repo.FindByPeriod(userID, string(utils.RecurrencePayCycle))
+	if err != nil {
+		return err
+	}
+
+	settings := s.payCycleSettings(userID, string(utils.RecurrencePayCycle))
+	for i := range budgets {
+		budget := &budgets[i]
+		budget.EndDate = s.calculateEndDate(budget.StartDate.Time, budget.Period, settings)
+		if err := s.repo.Update(budget); err != nil {
+			return err
+		}
+		if _, err := s.resolvePeriods(budget, time.Now(), true); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -219,6 +400,8 @@ func (s *budgetService) toAlertResponses(alerts []models.BudgetAlert) []dto.Budg
 		response := dto.BudgetAlertResponse{
 			ID:          alert.ID,
 			BudgetID:    alert.BudgetID,
+			Kind:        alert.Kind,
+			Threshold:   alert.Threshold,
 			Percentage:  alert.Percentage,
 			SpentAmount: alert.SpentAmount,
 			Message:     alert.Message,
@@ -228,6 +411,7 @@ func (s *budgetService) toAlertResponses(alerts []models.BudgetAlert) []dto.Budg
 
 		// Include budget and category information if available
 		if alert.Budget.ID > 0 {
+			response.BudgetName = budgetLabel(&alert.Budget)
 			response.CategoryID = alert.Budget.CategoryID
 			response.BudgetAmount = alert.Budget.Amount
 			if alert.Budget.Category.ID > 0 {
@@ -249,17 +433,72 @@ func (s *budgetService) MarkAllAlertsAsRead(userID uint) error {
 }
 
 // Helper functions
-func (s *budgetService) calculateEndDate(startDate time.Time, period string) utils.CustomTime {
-	var endDate time.Time
-	switch period {
-	case "monthly":
-		endDate = startDate.AddDate(0, 1, -1)
-	case "yearly":
-		endDate = startDate.AddDate(1, 0, -1)
-	default:
-		endDate = startDate.AddDate(0, 1, -1)
+
+// calculateEndDate returns the last day of the budget's first window
+func (s *budgetService) calculateEndDate(startDate time.Time, period string, settings utils.UserSettingsInterface) utils.CustomTime {
+	if period == string(utils.RecurrencePayCycle) {
+		return utils.CustomTime{Time: payCycleWindow(settings, startDate).EndDate}
+	}
+	window := utils.BudgetPeriodFor(utils.RecurrenceFrequency(period), startDate, startDate)
+	return utils.CustomTime{Time: window.EndDate}
+}
+
+// payCycleSettings returns the user's pay cycle settings for a pay cycle
+// budget, or nil to fall back to calendar months
+func (s *budgetService) payCycleSettings(userID uint, period string) utils.UserSettingsInterface {
+	if period != string(utils.RecurrencePayCycle) {
+		return nil
+	}
+	settings, err := s.settingsRepo.FindByUserID(userID)
+	if err != nil || settings == nil {
+		return nil
+	}
+	return settings
+}
+
+// payCycleWindow returns the pay cycle holding date, from its first to its
+// last day
+func payCycleWindow(settings utils.UserSettingsInterface, date time.Time) utils.FinancialPeriod {
+	period := utils.GetFinancialPeriodForDate(settings, utils.TruncateToDay(date))
+	return utils.FinancialPeriod{
+		PeriodLabel: period.PeriodLabel,
+		StartDate:   utils.TruncateToDay(period.StartDate),
+		EndDate:     utils.TruncateToDay(period.EndDate),
+	}
+}
+
+// periodAt returns the budget's window holding date. A one-off budget only
+// has the window it was created with, and pay cycle windows follow the
+// user's current settings.
+func (s *budgetService) periodAt(budget *models.Budget, settings utils.UserSettingsInterface, date time.Time) utils.FinancialPeriod {
+	if budget.Period == string(utils.RecurrencePayCycle) {
+		if !budget.Recurring {
+			date = budget.StartDate.Time
+		}
+		return payCycleWindow(settings, date)
+	}
+	if !budget.Recurring {
+		start := utils.TruncateToDay(budget.StartDate.Time)
+		return utils.FinancialPeriod{
+			PeriodLabel: start.Format("2006-01-02"),
+			StartDate:   start,
+			EndDate:     utils.TruncateToDay(budget.EndDate.Time),
+		}
+	}
+	return utils.BudgetPeriodFor(utils.RecurrenceFrequency(budget.Period), budget.StartDate.Time, date)
+}
+
+// periodWindows lists the budget's windows from the first up to the one
+// holding asOf, oldest first
+func (s *budgetService) periodWindows(budget *models.Budget, settings utils.UserSettingsInterface, asOf time.Time) []utils.FinancialPeriod {
+	current := s.periodAt(budget, settings, asOf)
+	var windows []utils.FinancialPeriod
+	for window := s.periodAt(budget, settings, budget.StartDate.Time); ; window = s.periodAt(budget, settings, window.EndDate.AddDate(0, 0, 1)) {
+		windows = append(windows, window)
+		if !window.StartDate.Before(current.StartDate) {
+			return windows
+		}
 	}
-	return utils.CustomTime{Time: endDate}
 }
 
 func (s *budgetService) toBudgetResponse(budget *models.Budget) *dto.BudgetResponse {
@@ -269,45 +508,340 @@ func (s *budgetService) toBudgetResponse(budget *models.Budget) *dto.BudgetRespo
 	}
 
 	return &dto.BudgetResponse{
-		ID:           budget.ID,
-		CategoryID:   budget.CategoryID,
-		CategoryName: categoryName,
-		Amount:       budget.Amount,
-		Period:       budget.Period,
-		StartDate:    budget.StartDate,
-		EndDate:      budget.EndDate,
-		IsActive:     budget.IsActive,
-		AlertAt:      budget.AlertAt,
-		Description:  budget.Description,
-		CreatedAt:    budget.CreatedAt,
+		ID:              budget.ID,
+		Name:            budget.Name,
+		CategoryID:      budget.CategoryID,
+		CategoryName:    categoryName,
+		CategoryIDs:     budget.CategoryIDs(),
+		AssetIDs:        budget.AssetIDs(),
+		Amount:          budget.Amount,
+		Period:          budget.Period,
+		Recurring:       budget.Recurring,
+		StartDate:       budget.StartDate,
+		EndDate:         budget.EndDate,
+		IsActive:        budget.IsActive,
+		AlertAt:         budget.AlertAt,
+		AlertThresholds: budget.Thresholds(),
+		ProjectedAlert:  budget.ProjectedAlert,
+		Rollover:        budget.Rollover,
+		Description:     budget.Description,
+		CreatedAt:       budget.CreatedAt,
 	}
 }
 
-func (s *budgetService) toBudgetWithSpendingResponse(budget *models.Budget) *dto.BudgetWithSpendingResponse {
+func (s *budgetService) toBudgetWithSpendingResponse(budget *models.Budget) (*dto.BudgetWithSpendingResponse, error) {
 	baseResponse := s.toBudgetResponse(budget)
-	spent, _ := s.repo.GetSpentAmount(budget.ID, budget.StartDate.Time, budget.EndDate.Time)
-
-	remaining := budget.Amount - spent
-	percentageUsed := float64(spent) / float64(budget.Amount) * 100
-
-	status := "safe"
-	if percentageUsed >= 100 {
-		status = "exceeded"
-	} else if percentageUsed >= float64(budget.AlertAt) {
-		status = "warning"
+	period, err := s.resolvePeriod(budget, false)
+	if err != nil {
+		return nil, err
 	}
 
-	daysRemaining := int(budget.EndDate.Sub(time.Now()).Hours() / 24)
+	available := period.Available()
+	percentage := percentageUsed(period.Spent, available)
+
+	daysRemaining := int(period.EndDate.Sub(time.Now()).Hours() / 24)
 	if daysRemaining < 0 {
 		daysRemaining = 0
 	}
 
 	return &dto.BudgetWithSpendingResponse{
 		BudgetResponse:  *baseResponse,
-		SpentAmount:     spent,
-		RemainingAmount: remaining,
-		PercentageUsed:  percentageUsed,
-		Status:          status,
+		PeriodStart:     period.StartDate,
+		PeriodEnd:       period.EndDate,
+		CarryOver:       period.CarryIn,
+		AvailableAmount: available,
+		SpentAmount:     period.Spent,
+		RemainingAmount: period.Remaining,
+		PercentageUsed:  percentage,
+		Status:          budgetStatus(percentage, budget.AlertAt),
 		DaysRemaining:   daysRemaining,
+	}, nil
+}
+
+// resolvePeriod returns the budget's current period. With persist set the
+// periods are stored, see resolvePeriods.
+func (s *budgetService) resolvePeriod(budget *models.Budget, persist bool) (*models.BudgetPeriod, error) {
+	periods, err := s.resolvePeriods(budget, time.Now(), persist)
+	if err != nil {
+		return nil, err
+	}
+	return &periods[len(periods)-1], nil
+}
+
+// resolvePeriods works out the spending of each of the budget's windows up
+// to the one holding asOf, oldest first. A rollover budget carries each
+// remainder into the next window; a one-off rollover budget starts from the
+// remainder of the previous budget of the same scope, which is resolved the
+// same way. Closed windows of a recurring budget keep the amount planned at
+// the time.
+//
+// Closed windows are taken from the stored periods unless a transaction
+// change since the last sync reaches them, so only the open window and the
+// windows after the oldest backdated change are recomputed. Reads leave the
+// database alone; with persist set, recomputed figures that changed are
+// stored, stored periods that no longer match a window, after the pay cycle
+// changed, are dropped, and the budget is marked as synced.
+func (s *budgetService) resolvePeriods(budget *models.Budget, asOf time.Time, persist bool) ([]models.BudgetPeriod, error) {
+	syncedAt := time.Now()
+	windows := s.periodWindows(budget, s.payCycleSettings(budget.UserID, budget.Period), asOf)
+
+	stored, err := s.repo.FindPeriods(budget.ID)
+	if err != nil {
+		return nil, err
+	}
+	storedByStart := make(map[string]models.BudgetPeriod, len(stored))
+	for _, period := range stored {
+		storedByStart[period.StartDate.Format("2006-01-02")] = period
+	}
+
+	changedFrom, err := s.changedSince(budget)
+	if err != nil {
+		return nil, err
+	}
+
+	today := utils.TruncateToDay(time.Now())
+	periods := make([]models.BudgetPeriod, len(windows))
+	first := 0
+	for ; first < len(windows); first++ {
+		window := windows[first]
+		existing, found := storedByStart[window.StartDate.Format("2006-01-02")]
+		if !found || !existing.EndDate.Equal(window.EndDate) || !window.EndDate.Before(today) ||
+			(changedFrom != nil && !window.EndDate.Before(utils.TruncateToDay(*changedFrom))) {
+			break
+		}
+		periods[first] = existing
+		delete(storedByStart, window.StartDate.Format("2006-01-02"))
+	}
+	if first == len(windows) {
+		if persist {
+			if err := s.repo.MarkPeriodsSynced(budget.ID, syncedAt); err != nil {
+				return nil, err
+			}
+			budget.PeriodsSyncedAt = &utils.CustomTime{Time: syncedAt}
+		}
+		return periods, nil
+	}
+
+	daily, err := s.repo.GetDailySpending(budget, windows[first].StartDate, windows[len(windows)-1].EndDate)
+	if err != nil {
+		return nil, err
+	}
+	spent := make([]money.Amount, len(windows))
+	for day, amount := range daily {
+		i := first + sort.Search(len(windows)-first, func(i int) bool {
+			return windows[first+i].EndDate.Format("2006-01-02") >= day
+		})
+		if i < len(windows) {
+			spent[i] += amount
+		}
+	}
+
+	var carryIn money.Amount
+	switch {
+	case first > 0 && budget.Rollover:
+		carryIn = periods[first-1].Remaining
+	case first == 0 && budget.Rollover && !budget.Recurring:
+		previous, err := s.repo.FindPreviousBudget(budget)
+		if err != nil {
+			return nil, err
+		}
+		if previous != nil {
+			previousPeriods, err := s.resolvePeriods(previous, previous.EndDate.Time, persist)
+			if err != nil {
+				return nil, err
+			}
+			carryIn = previousPeriods[len(previousPeriods)-1].Remaining
+		}
+	}
+
+	var changed []models.BudgetPeriod
+	for i := first; i < len(windows); i++ {
+		window := windows[i]
+		key := window.StartDate.Format("2006-01-02")
+		existing, found := storedByStart[key]
+		delete(storedByStart, key)
+
+		// Keep the stored start so the period is updated in place, along
+		// with the alerts it already fired
+		start := utils.CustomTime{Time: window.StartDate}
+		if found {
+			start = existing.StartDate
+		}
+
+		amount := budget.Amount
+		if found && budget.Recurring && window.EndDate.Before(today) {
+			amount = existing.Amount
+		}
+
+		periods[i] = models.BudgetPeriod{
+			BudgetID:         budget.ID,
+			UserID:           budget.UserID,
+			StartDate:        start,
+			EndDate:          utils.CustomTime{Time: window.EndDate},
+			Amount:           amount,
+			CarryIn:          carryIn,
+			Spent:            spent[i],
+			Remaining:        amount + carryIn - spent[i],
+			AlertedThreshold: existing.AlertedThreshold,
+			ProjectedAlerted: existing.ProjectedAlerted,
+		}
+		if !found || !existing.EndDate.Equal(window.EndDate) || existing.Amount != amount ||
+			existing.CarryIn != carryIn || existing.Spent != spent[i] {
+			changed = append(changed, periods[i])
+		}
+
+		if budget.Rollover {
+			carryIn = periods[i].Remaining
+		}
+	}
+
+	if !persist {
+		return periods, nil
+	}
+
+	stale := make([]uint, 0, len(storedByStart))
+	for _, period := range storedByStart {
+		stale = append(stale, period.ID)
+	}
+	if err := s.repo.DeletePeriods(stale); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpsertPeriods(changed); err != nil {
+		return nil, err
+	}
+	if err := s.repo.MarkPeriodsSynced(budget.ID, syncedAt); err != nil {
+		return nil, err
+	}
+	budget.PeriodsSyncedAt = &utils.CustomTime{Time: syncedAt}
+	return periods, nil
+}
+
+// syncPeriods stores the budget's periods after the budget changed so reads
+// can reuse them. Failing only costs later reads a full recompute, so the
+// error is logged rather than returned.
+func (s *budgetService) syncPeriods(budget *models.Budget) {
+	if budget == nil {
+		return
+	}
+	if _, err := s.resolvePeriods(budget, time.Now(), true); err != nil {
+		utils.LogErrorf("Failed to store the periods of budget %d: %v", budget.ID, err)
+	}
+}
+
+// changedSince returns the oldest day whose spending may have changed since
+// the budget's periods were last synced, or nil when nothing changed. Every
+// window counts as changed when the periods were never synced or the budget
+// itself was edited since.
+func (s *budgetService) changedSince(budget *models.Budget) (*time.Time, error) {
+	if budget.PeriodsSyncedAt == nil || budget.UpdatedAt.After(budget.PeriodsSyncedAt.Time) {
+		return &time.Time{}, nil
+	}
+	return s.repo.EarliestChangeSince(budget.UserID, budget.PeriodsSyncedAt.Time)
+}
+
+// budgetLabel names the budget in messages: its own name, or else the names
+// of the categories it covers
+func budgetLabel(budget *models.Budget) string {
+	if budget.Name != "" {
+		return budget.Name
+	}
+	if len(budget.Categories) == 0 {
+		return budget.Category.CategoryName
+	}
+	names := make([]string, len(budget.Categories))
+	for i, category := range budget.Categories {
+		names[i] = category.CategoryName
+	}
+	return strings.Join(names, ", ")
+}
+
+// uniqueIDs drops zero and repeated IDs, keeping the first occurrence of each
+func uniqueIDs[T comparable](ids []T) []T {
+	var zero T
+	seen := make(map[T]bool, len(ids))
+	unique := make([]T, 0, len(ids))
+	for _, id := range ids {
+		if id != zero && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+func categoriesFromIDs(ids []uint) []models.Category {
+	categories := make([]models.Category, len(ids))
+	for i, id := range ids {
+		categories[i] = models.Category{ID: id}
+	}
+	return categories
+}
+
+func assetsFromIDs(ids []uint64) []models.Asset {
+	assets := make([]models.Asset, len(ids))
+	for i, id := range ids {
+		assets[i] = models.Asset{ID: id}
+	}
+	return assets
+}
+
+// crossedThreshold returns the highest of the ascending thresholds that the
+// percentage reached, or 0 when it reached none
+func crossedThreshold(thresholds []int, percentage float64) int {
+	crossed := 0
+	for _, threshold := range thresholds {
+		if percentage < float64(threshold) {
+			break
+		}
+		crossed = threshold
+	}
+	return crossed
+}
+
+// projectedSpending extrapolates the period's spending to its last day at the
+// pace so far. It reports false until a quarter of the period has passed, and
+// once the period is over.
+func projectedSpending(period *models.BudgetPeriod, now time.Time) (money.Amount, bool) {
+	start := utils.TruncateToDay(period.StartDate.Time)
+	totalDays := int64(utils.TruncateToDay(period.EndDate.Time).Sub(start).Hours()/24) + 1
+	elapsedDays := int64(utils.TruncateToDay(now).Sub(start).Hours()/24) + 1
+	if elapsedDays >= totalDays || elapsedDays*4 < totalDays {
+		return 0, false
+	}
+	return period.Spent.Mul(totalDays).Div(elapsedDays), true
+}
+
+// validateThresholds checks a requested list of alert thresholds
+func validateThresholds(thresholds []int) error {
+	if len(thresholds) > maxAlertThresholds {
+		return fmt.Errorf("at most %d alert thresholds are allowed", maxAlertThresholds)
+	}
+	for _, threshold := range thresholds {
+		if threshold < 1 || threshold > 1000 {
+			return errors.New("alert thresholds must be between 1 and 1000")
+		}
+	}
+	return nil
+}
+
+// budgetStatus rates the percentage used against the budget's alert level
+func budgetStatus(percentage float64, alertAt int) string {
+	if percentage >= 100 {
+		return "exceeded"
+	} else if percentage >= float64(alertAt) {
+		return "warning"
+	}
+	return "safe"
+}
+
+// percentageUsed returns spent as a percentage of available. Once a carried
+// over overspend leaves nothing available, the budget counts as fully used.
+func percentageUsed(spent, available money.Amount) float64 {
+	if available <= 0 {
+		if spent > 0 || available < 0 {
+			return 100
+		}
+		return 0
 	}
+	return float64(spent) / float64(available) * 100
 }
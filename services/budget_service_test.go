@@ -0,0 +1,75 @@
+package services
+
+import (
+	"my-api/models"
+	"my-api/money"
+	"my-api/utils"
+	"testing"
+	"time"
+)
+
+func TestCrossedThreshold(t *testing.T) {
+	tests := []struct {
+		name       string
+		thresholds []int
+		percentage float64
+		want       int
+	}{
+		{name: "below every threshold", thresholds: []int{50, 80, 100}, percentage: 49.9, want: 0},
+		{name: "exactly at a threshold", thresholds: []int{50, 80, 100}, percentage: 80, want: 80},
+		{name: "jump past several reports the highest", thresholds: []int{50, 80, 100}, percentage: 99, want: 80},
+		{name: "jump past all of them", thresholds: []int{50, 80, 100}, percentage: 240, want: 100},
+		{name: "thresholds above 100", thresholds: []int{100, 150, 200}, percentage: 180, want: 150},
+		{name: "overspend past the top threshold", thresholds: []int{80, 100, 120}, percentage: 121, want: 120},
+		{name: "no thresholds", thresholds: nil, percentage: 150, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := crossedThreshold(tt.thresholds, tt.percentage); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCrossedThresholdWithUnsortedBudgetThresholds(t *testing.T) {
+	budget := models.Budget{AlertThresholds: "120,50,100,80,50"}
+	if got := crossedThreshold(budget.Thresholds(), 110); got != 100 {
+		t.Errorf("expected 100, got %d", got)
+	}
+}
+
+func TestProjectedSpending(t *testing.T) {
+	// A 30 day period: Oct 1 up to and including Oct 30
+	period := models.BudgetPeriod{
+		StartDate: utils.CustomTime{Time: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		EndDate:   utils.CustomTime{Time: time.Date(2026, 10, 30, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name   string
+		spent  money.Amount
+		now    time.Time
+		want   money.Amount
+		wantOK bool
+	}{
+		{name: "before a quarter of the period", spent: money.FromInt(70), now: time.Date(2026, 10, 7, 18, 0, 0, 0, time.UTC)},
+		{name: "first day past the quarter", spent: money.FromInt(80), now: time.Date(2026, 10, 8, 9, 0, 0, 0, time.UTC), want: money.FromInt(300), wantOK: true},
+		{name: "halfway", spent: money.FromInt(150), now: time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC), want: money.FromInt(300), wantOK: true},
+		{name: "day before the last", spent: money.FromInt(290), now: time.Date(2026, 10, 29, 23, 0, 0, 0, time.UTC), want: money.FromInt(300), wantOK: true},
+		{name: "last day", spent: money.FromInt(290), now: time.Date(2026, 10, 30, 8, 0, 0, 0, time.UTC)},
+		{name: "after the period", spent: money.FromInt(290), now: time.Date(2026, 11, 2, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			period := period
+			period.Spent = tt.spent
+			got, ok := projectedSpending(&period, tt.now)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("expected %s (%v), got %s (%v)", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
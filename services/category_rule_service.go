@@ -0,0 +1,346 @@
+package services
+
+import (
+	"errors"
+	"my-api/categorizer"
+	"my-api/dto"
+	"my-api/models"
+	"my-api/repositories"
+	"my-api/utils"
+
+	"gorm.io/gorm"
+)
+
+type CategoryRuleService interface {
+	CreateRule(userID uint, req *dto.CategoryRuleRequest) (*dto.CategoryRuleResponse, error)
+	GetRules(userID uint) ([]dto.CategoryRuleResponse, error)
+	GetRuleByID(id uint, userID uint) (*dto.CategoryRuleResponse, error)
+	UpdateRule(id uint, userID uint, req *dto.CategoryRuleRequest) (*dto.CategoryRuleResponse, error)
+	DeleteRule(id uint, userID uint) error
+
+	Engine(userID uint) (*categorizer.Engine, error)
+	Categorize(transaction *models.TransactionV2) error
+	DryRun(id uint, userID uint, limit int) (*dto.CategoryRuleRunResponse, error)
+	Apply(id uint, userID uint) (*dto.CategoryRuleRunResponse, error)
+}
+
+type categoryRuleService struct {
+	repo          repositories.CategoryRuleRepository
+	assetRepo     *repositories.AssetRepository
+	payeeService  PayeeService
+	budgetService BudgetService
+}
+
+func NewCategoryRuleService(
+	repo repositories.CategoryRuleRepository,
+	assetRepo *repositories.AssetRepository,
+	payeeService PayeeService,
+	budgetService BudgetService,
+) CategoryRuleService {
+	return &categoryRuleService{
+		repo:          repo,
+		assetRepo:     assetRepo,
+		payeeService:  payeeService,
+		budgetService: budgetService,
+	}
+}
+
+func (s *categoryRuleService) CreateRule(userID uint, req *dto.CategoryRuleRequest) (*dto.CategoryRuleResponse, error) {
+	rule := &models.CategoryRule{UserID: userID, Priority: 100, IsActive: true}
+	if err := s.applyRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(rule); err != nil {
+		return nil, err
+	}
+	return s.GetRuleByID(rule.ID, userID)
+}
+
+func (s *categoryRuleService) GetRules(userID uint) ([]dto.CategoryRuleResponse, error) {
+	rules, err := s.repo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.CategoryRuleResponse, len(rules))
+	for i := range rules {
+		responses[i] = *toCategoryRuleResponse(&rules[i])
+	}
+	return responses, nil
+}
+
+func (s *categoryRuleService) GetRuleByID(id uint, userID uint) (*dto.CategoryRuleResponse, error) {
+	rule, err := s.findRule(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toCategoryRuleResponse(rule), nil
+}
+
+func (s *categoryRuleService) UpdateRule(id uint, userID uint, req *dto.CategoryRuleRequest) (*dto.CategoryRuleResponse, error) {
+	rule, err := s.findRule(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(rule); err != nil {
+		return nil, err
+	}
+	return s.GetRuleByID(rule.ID, userID)
+}
+
+func (s *categoryRuleService) DeleteRule(id uint, userID uint) error {
+	if _, err := s.findRule(id, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id, userID)
+}
+
+// Engine returns the user's active rules compiled for evaluation
+func (s *categoryRuleService) Engine(userID uint) (*categorizer.Engine, error) {
+	rules, err := s.repo.FindActive(userID)
+	if err != nil {
+		return nil, err
+	}
+	return categorizer.New(rules), nil
+}
+
+// Categorize fills in the category, payee and tags of a new transaction from
+// the user's rules. Values the caller already set are kept.
+func (s *categoryRuleService) Categorize(transaction *models.TransactionV2) error {
+	if transaction.TransferID != nil {
+		return nil
+	}
+
+	engine, err := s.Engine(transaction.UserID)
+	if err != nil {
+		return err
+	}
+
+	result := engine.Evaluate(categorizer.Transaction{
+		Description:     transaction.Description,
+		Amount:          transaction.Amount,
+		TransactionType: transaction.TransactionType,
+		AssetID:         transaction.AssetID,
+	})
+	if transaction.CategoryID == 0 && result.CategoryID != nil {
+		transaction.CategoryID = *result.CategoryID
+	}
+	if transaction.PayeeID == nil {
+		transaction.PayeeID = result.PayeeID
+	}
+	if len(transaction.Tags) == 0 {
+		for _, tagID := range result.TagIDs {
+			transaction.Tags = append(transaction.Tags, models.Tag{ID: tagID})
+		}
+	}
+	return nil
+}
+
+// DryRun lists the existing transactions the rule would change without
+// writing anything
+func (s *categoryRuleService) DryRun(id uint, userID uint, limit int) (*dto.CategoryRuleRunResponse, error) {
+	return s.run(id, userID, false, limit)
+}
+
+// Apply re-categorizes every existing transaction that meets the rule's
+// conditions, whether or not the rule is active
+func (s *categoryRuleService) Apply(id uint, userID uint) (*dto.CategoryRuleRunResponse, error) {
+	result, err := s.run(id, userID, true, 0)
+	if err != nil {
+		return nil, err
+	}
+	if result.Changed > 0 {
+		utils.LogInfof("Category rule %d updated %d transactions for user %d", id, result.Changed, userID)
+		s.budgetService.CheckBudgetAlerts(userID)
+	}
+	return result, nil
+}
+
+func (s *categoryRuleService) run(id uint, userID uint, commit bool, limit int) (*dto.CategoryRuleRunResponse, error) {
+	model, err := s.findRule(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := categorizer.Compile(*model)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.CategoryRuleRunResponse{RuleID: id, DryRun: !commit}
+	var lastID uint
+	for {
+		transactions, err := s.repo.FindTransactions(userID, lastID, 500)
+		if err != nil {
+			return nil, err
+		}
+		if len(transactions) == 0 {
+			break
+		}
+
+		var updates []repositories.TransactionRuleUpdate
+		for _, t := range transactions {
+			if !rule.Matches(categorizer.Transaction{
+				Description:     t.Description,
+				Amount:          t.Amount,
+				TransactionType: t.TransactionType,
+				AssetID:         t.AssetID,
+			}) {
+				continue
+			}
+			result.Matched++
+
+			update, changed := ruleUpdate(rule, &t)
+			if !changed {
+				continue
+			}
+			result.Changed++
+			updates = append(updates, update)
+
+			if !commit && len(result.Changes) < limit {
+				result.Changes = append(result.Changes, dto.CategoryRuleChangeResponse{
+					TransactionID:       t.ID,
+					Date:                t.Date,
+					Description:         t.Description,
+					Amount:              t.Amount,
+					TransactionType:     t.TransactionType,
+					CurrentCategoryID:   t.CategoryID,
+					CurrentCategoryName: t.Category.CategoryName,
+					NewCategoryID:       update.CategoryID,
+					CurrentPayeeID:      t.PayeeID,
+					NewPayeeID:          update.PayeeID,
+					AddTagIDs:           update.AddTagIDs,
+				})
+			}
+		}
+
+		if commit && len(updates) > 0 {
+			if err := s.repo.ApplyUpdates(userID, updates); err != nil {
+				return nil, err
+			}
+		}
+
+		result.Scanned += len(transactions)
+		lastID = transactions[len(transactions)-1].ID
+	}
+
+	return result, nil
+}
+
+// ruleUpdate works out what the rule would change on a matching transaction.
+// Split transactions keep their categories since those live on the splits.
+func ruleUpdate(rule *categorizer.Rule, t *models.TransactionV2) (repositories.TransactionRuleUpdate, bool) {
+	update := repositories.TransactionRuleUpdate{TransactionID: t.ID}
+	changed := false
+
+	if rule.CategoryID != nil && len(t.Splits) == 0 && t.CategoryID != *rule.CategoryID {
+		update.CategoryID = rule.CategoryID
+		changed = true
+	}
+	if rule.PayeeID != nil && (t.PayeeID == nil || *t.PayeeID != *rule.PayeeID) {
+		update.PayeeID = rule.PayeeID
+		changed = true
+	}
+
+	existing := make(map[uint]bool, len(t.Tags))
+	for _, tag := range t.Tags {
+		existing[tag.ID] = true
+	}
+	for _, tagID := range rule.TagIDs {
+		if !existing[tagID] {
+			update.AddTagIDs = append(update.AddTagIDs, tagID)
+			changed = true
+		}
+	}
+
+	return update, changed
+}
+
+func (s *categoryRuleService) applyRuleRequest(rule *models.CategoryRule, req *dto.CategoryRuleRequest) error {
+	if req.CategoryID != nil {
+		exists, err := s.repo.CategoryExists(*req.CategoryID, rule.UserID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.New("category not found")
+		}
+	}
+	if req.PayeeID != nil {
+		if err := s.payeeService.CheckPayee(*req.PayeeID, rule.UserID); err != nil {
+			return err
+		}
+	}
+	if req.AssetID != nil {
+		asset, err := s.assetRepo.GetAssetByID(*req.AssetID)
+		if err != nil {
+			return errors.New("asset not found")
+		}
+		if asset.UserID != uint64(rule.UserID) {
+			return errors.New("unauthorized: asset does not belong to user")
+		}
+	}
+
+	rule.Name = req.Name
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	rule.MatchType = utils.MatchType(req.MatchType)
+	rule.Pattern = req.Pattern
+	rule.MinAmount = req.MinAmount
+	rule.MaxAmount = req.MaxAmount
+	rule.TransactionType = nil
+	if req.TransactionType != nil {
+		transactionType := 1
+		if *req.TransactionType == "Expense" || *req.TransactionType == "expense" {
+			transactionType = 2
+		}
+		rule.TransactionType = &transactionType
+	}
+	rule.AssetID = req.AssetID
+	rule.CategoryID = req.CategoryID
+	rule.PayeeID = req.PayeeID
+	rule.Tags = make([]models.Tag, len(req.TagIDs))
+	for i, tagID := range req.TagIDs {
+		rule.Tags[i] = models.Tag{ID: tagID}
+	}
+
+	// Compile runs the same checks the engine does, so invalid rules are
+	// rejected here rather than silently skipped later
+	_, err := categorizer.Compile(*rule)
+	return err
+}
+
+func (s *categoryRuleService) findRule(id uint, userID uint) (*models.CategoryRule, error) {
+	rule, err := s.repo.FindByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("category rule not found")
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+func toCategoryRuleResponse(rule *models.CategoryRule) *dto.CategoryRuleResponse {
+	return &dto.CategoryRuleResponse{
+		ID:              rule.ID,
+		Name:            rule.Name,
+		Priority:        rule.Priority,
+		IsActive:        rule.IsActive,
+		MatchType:       string(rule.MatchType),
+		Pattern:         rule.Pattern,
+		MinAmount:       rule.MinAmount,
+		MaxAmount:       rule.MaxAmount,
+		TransactionType: rule.TransactionType,
+		AssetID:         rule.AssetID,
+		CategoryID:      rule.CategoryID,
+		PayeeID:         rule.PayeeID,
+		Tags:            toTagResponses(rule.Tags),
+	}
+}
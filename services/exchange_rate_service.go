@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"my-api/dto"
+	"my-api/importers"
+	"my-api/models"
+	"my-api/repositories"
+	"my-api/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ExchangeRateService interface {
+	CreateRate(userID uint, req *dto.CreateExchangeRateRequest) (*dto.ExchangeRateResponse, error)
+	GetRates(userID uint, currency string, startDate, endDate *time.Time) ([]dto.ExchangeRateResponse, error)
+	DeleteRate(id uint, userID uint) error
+	ImportCSV(userID uint, file io.Reader, dateFormat string) (*dto.ExchangeRateImportResponse, error)
+
+	BaseCurrency(userID uint) string
+	GetRate(userID uint, from, to string, date time.Time) (float64, bool, error)
+}
+
+type exchangeRateService struct {
+	repo             repositories.ExchangeRateRepository
+	userSettingsRepo repositories.UserSettingsRepository
+}
+
+func NewExchangeRateService(
+	repo repositories.ExchangeRateRepository,
+	userSettingsRepo repositories.UserSettingsRepository,
+) ExchangeRateService {
+	return &exchangeRateService{
+		repo:             repo,
+		userSettingsRepo: userSettingsRepo,
+	}
+}
+
+func (s *exchangeRateService) CreateRate(userID uint, req *dto.CreateExchangeRateRequest) (*dto.ExchangeRateResponse, error) {
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, errors.New("invalid date format, use YYYY-MM-DD")
+	}
+
+	rate := models.ExchangeRate{
+		UserID:        userID,
+		BaseCurrency:  strings.ToUpper(req.BaseCurrency),
+		QuoteCurrency: strings.ToUpper(req.QuoteCurrency),
+		RateDate:      utils.CustomTime{Time: date},
+		Rate:          req.Rate,
+		Source:        "manual",
+	}
+	if rate.BaseCurrency == rate.QuoteCurrency {
+		return nil, errors.New("base and quote currency must differ")
+	}
+
+	if err := s.repo.Upsert([]models.ExchangeRate{rate}); err != nil {
+		return nil, err
+	}
+
+	// The upsert does not report the id of a replaced row, so read it back
+	saved, err := s.repo.FindLatest(userID, rate.BaseCurrency, rate.QuoteCurrency, date)
+	if err != nil {
+		return nil, err
+	}
+	return toExchangeRateResponse(saved), nil
+}
+
+func (s *exchangeRateService) GetRates(userID uint, currency string, startDate, endDate *time.Time) ([]dto.ExchangeRateResponse, error) {
+	rates, err := s.repo.FindAll(userID, strings.ToUpper(currency), startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ExchangeRateResponse, len(rates))
+	for i := range rates {
+		responses[i] = *toExchangeRateResponse(&rates[i])
+	}
+	return responses, nil
+}
+
+func (s *exchangeRateService) DeleteRate(id uint, userID uint) error {
+	if _, err := s.repo.FindByID(id, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("exchange rate not found")
+		}
+		return err
+	}
+	return s.repo.Delete(id, userID)
+}
+
+// ImportCSV loads rates from a CSV file. Valid rows are saved even when
+// others are rejected; a rate for an existing pair and date replaces it.
+func (s *exchangeRateService) ImportCSV(userID uint, file io.Reader, dateFormat string) (*dto.ExchangeRateImportResponse, error) {
+	rows, err := importers.ParseExchangeRatesCSV(file, dateFormat)
+	if err != nil {
+		return nil, errors.New("invalid CSV file: " + err.Error())
+	}
+
+	result := &dto.ExchangeRateImportResponse{TotalRows: len(rows)}
+	rates := make([]models.ExchangeRate, 0, len(rows))
+	for _, row := range rows {
+		if row.Error != "" {
+			result.Rejected++
+			result.Errors = append(result.Errors, dto.ExchangeRateImportRowError{Line: row.Line, Error: row.Error})
+			continue
+		}
+		rates = append(rates, models.ExchangeRate{
+			UserID:        userID,
+			BaseCurrency:  row.BaseCurrency,
+			QuoteCurrency: row.QuoteCurrency,
+			RateDate:      utils.CustomTime{Time: row.Date},
+			Rate:          row.Rate,
+			Source:        "csv",
+		})
+	}
+
+	if err := s.repo.Upsert(rates); err != nil {
+		return nil, err
+	}
+	result.Imported = len(rates)
+	utils.LogInfof("Imported %d exchange rates for user %d (%d rejected)", result.Imported, userID, result.Rejected)
+
+	return result, nil
+}
+
+// BaseCurrency returns the currency the user's totals are reported in
+func (s *exchangeRateService) BaseCurrency(userID uint) string {
+	settings, err := s.userSettingsRepo.FindByUserID(userID)
+	if err != nil || settings.BaseCurrency == "" {
+		return models.DefaultBaseCurrency
+	}
+	return settings.BaseCurrency
+}
+
+// GetRate returns the rate converting from one currency into another on the
+// given date. The direct pair is preferred; otherwise the inverse of the
+// reverse pair is used. The boolean is false when neither is known.
+func (s *exchangeRateService) GetRate(userID uint, from, to string, date time.Time) (float64, bool, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return 1, true, nil
+	}
+
+	rate, err := s.repo.FindLatest(userID, from, to, date)
+	if err == nil {
+		return rate.Rate, true, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, false, err
+	}
+
+	rate, err = s.repo.FindLatest(userID, to, from, date)
+	if err == nil && rate.Rate > 0 {
+		return 1 / rate.Rate, true, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, false, err
+	}
+	return 0, false, nil
+}
+
+func toExchangeRateResponse(rate *models.ExchangeRate) *dto.ExchangeRateResponse {
+	return &dto.ExchangeRateResponse{
+		ID:            rate.ID,
+		BaseCurrency:  rate.BaseCurrency,
+		QuoteCurrency: rate.QuoteCurrency,
+		Rate:          rate.Rate,
+		Date:          rate.RateDate.Format("2006-01-02"),
+		Source:        rate.Source,
+	}
+}
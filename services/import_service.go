@@ -0,0 +1,383 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"my-api/categorizer"
+	"my-api/dto"
+	"my-api/importers"
+	"my-api/models"
+	"my-api/repositories"
+	"my-api/utils"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+type ImportService interface {
+	CreateProfile(userID uint, req *dto.ImportProfileRequest) (*models.ImportProfile, error)
+	GetProfiles(userID uint) ([]models.ImportProfile, error)
+	GetProfileByID(id uint, userID uint) (*models.ImportProfile, error)
+	UpdateProfile(id uint, userID uint, req *dto.ImportProfileRequest) (*models.ImportProfile, error)
+	DeleteProfile(id uint, userID uint) error
+	ImportCSV(userID uint, req *dto.ImportCSVRequest, file io.Reader) (*dto.ImportResultResponse, error)
+	ImportOFX(userID uint, req *dto.ImportFileRequest, file io.Reader) (*dto.ImportResultResponse, error)
+	ImportQIF(userID uint, req *dto.ImportFileRequest, file io.Reader) (*dto.ImportResultResponse, error)
+}
+
+type importService struct {
+	profileRepo     repositories.ImportProfileRepository
+	transactionRepo repositories.TransactionV2Repository
+	assetRepo       *repositories.AssetRepository
+	payeeService    PayeeService
+	ruleService     CategoryRuleService
+	budgetService   BudgetService
+}
+
+func NewImportService(
+	profileRepo repositories.ImportProfileRepository,
+	transactionRepo repositories.TransactionV2Repository,
+	assetRepo *repositories.AssetRepository,
+	payeeService PayeeService,
+	ruleService CategoryRuleService,
+	budgetService BudgetService,
+) ImportService {
+	return &importService{
+		profileRepo:     profileRepo,
+		transactionRepo: transactionRepo,
+		assetRepo:       assetRepo,
+		payeeService:    payeeService,
+		ruleService:     ruleService,
+		budgetService:   budgetService,
+	}
+}
+
+func (s *importService) CreateProfile(userID uint, req *dto.ImportProfileRequest) (*models.ImportProfile, error) {
+	profile := &models.ImportProfile{UserID: userID}
+	if err := s.applyProfileRequest(profile, req); err != nil {
+		return nil, err
+	}
+	if err := s.profileRepo.Create(profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (s *importService) GetProfiles(userID uint) ([]models.ImportProfile, error) {
+	return s.profileRepo.FindAll(userID)
+}
+
+func (s *importService) GetProfileByID(id uint, userID uint) (*models.ImportProfile, error) {
+	profile, err := s.profileRepo.FindByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("import profile not found")
+		}
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (s *importService) UpdateProfile(id uint, userID uint, req *dto.ImportProfileRequest) (*models.ImportProfile, error) {
+	profile, err := s.GetProfileByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyProfileRequest(profile, req); err != nil {
+		return nil, err
+	}
+	if err := s.profileRepo.Update(profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (s *importService) DeleteProfile(id uint, userID uint) error {
+	if _, err := s.GetProfileByID(id, userID); err != nil {
+		return err
+	}
+	return s.profileRepo.Delete(id, userID)
+}
+
+// ImportCSV parses a statement with the given profile. Without req.Commit it
+// only returns the preview; with it all new rows are posted to the asset in
+// one DB transaction.
+func (s *importService) ImportCSV(userID uint, req *dto.ImportCSVRequest, file io.Reader) (*dto.ImportResultResponse, error) {
+	profile, err := s.GetProfileByID(req.ProfileID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	assetID := req.AssetID
+	if assetID == nil {
+		assetID = profile.AssetID
+	}
+	if assetID == nil {
+		return nil, errors.New("asset_id is required")
+	}
+
+	rows, err := importers.ParseCSV(file, profileMapping(profile))
+	if err != nil {
+		return nil, errors.New("invalid CSV file: " + err.Error())
+	}
+
+	return s.importRows(userID, *assetID, rows, importCategories{
+		override: req.CategoryID,
+		income:   profile.IncomeCategoryID,
+		expense:  profile.ExpenseCategoryID,
+	}, req.Commit)
+}
+
+func (s *importService) ImportOFX(userID uint, req *dto.ImportFileRequest, file io.Reader) (*dto.ImportResultResponse, error) {
+	rows, err := importers.ParseOFX(file)
+	if err != nil {
+		return nil, errors.New("invalid OFX file: " + err.Error())
+	}
+	return s.importRows(userID, req.AssetID, rows, importCategories{
+		override: req.CategoryID,
+		income:   req.IncomeCategoryID,
+		expense:  req.ExpenseCategoryID,
+	}, req.Commit)
+}
+
+func (s *importService) ImportQIF(userID uint, req *dto.ImportFileRequest, file io.Reader) (*dto.ImportResultResponse, error) {
+	rows, err := importers.ParseQIF(file, req.DateFormat)
+	if err != nil {
+		return nil, errors.New("invalid QIF file: " + err.Error())
+	}
+	return s.importRows(userID, req.AssetID, rows, importCategories{
+		override: req.CategoryID,
+		income:   req.IncomeCategoryID,
+		expense:  req.ExpenseCategoryID,
+	}, req.Commit)
+}
+
+// importRows classifies parsed rows as new, duplicate or rejected and, when
+// commit is set, posts the new ones. Duplicates are detected through the
+// import key stored on previously imported transactions, and on rows accepted
+// earlier in the same file, since statements can repeat a bank transaction ID.
+func (s *importService) importRows(userID uint, assetID uint64, rows []importers.Row, categories importCategories, commit bool) (*dto.ImportResultResponse, error) {
+	if err := s.checkAssetOwnership(assetID, userID); err != nil {
+		return nil, err
+	}
+
+	importers.AssignImportKeys(rows)
+	keys := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.ImportKey != "" {
+			keys = append(keys, row.ImportKey)
+		}
+	}
+	existing, err := s.transactionRepo.FindExistingImportKeys(assetID, keys)
+	if err != nil {
+		return nil, err
+	}
+	matchPayee, err := s.payeeService.Matcher(userID)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := s.ruleService.Engine(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.ImportResultResponse{
+		Committed: commit,
+		AssetID:   assetID,
+		TotalRows: len(rows),
+		Rows:      make([]dto.ImportRowResponse, len(rows)),
+	}
+
+	var transactions []models.TransactionV2
+	for i, row := range rows {
+		ruled := engine.Evaluate(categorizer.Transaction{
+			Description:     row.Description,
+			Amount:          row.Amount,
+			TransactionType: row.TransactionType,
+			AssetID:         assetID,
+		})
+		categoryID := categories.forRow(row, ruled.CategoryID)
+		if row.Valid() && categoryID == 0 {
+			row.Error = "no category configured for this row"
+		}
+		payeeID := ruled.PayeeID
+		if payeeID == nil {
+			payeeID = matchPayee(row.Payee)
+		}
+		if payeeID == nil {
+			payeeID = matchPayee(row.Description)
+		}
+
+		response := dto.ImportRowResponse{
+			Line:            row.Line,
+			Date:            utils.CustomTime{Time: row.Date},
+			Description:     row.Description,
+			Payee:           row.Payee,
+			Amount:          row.Amount,
+			TransactionType: row.TransactionType,
+			CategoryID:      categoryID,
+			PayeeID:         payeeID,
+			TagIDs:          ruled.TagIDs,
+			Error:           row.Error,
+		}
+
+		switch {
+		case !row.Valid():
+			response.Status = "rejected"
+			result.Rejected++
+		case existing[row.ImportKey]:
+			response.Status = "duplicate"
+			result.SkippedDuplicates++
+		default:
+			response.Status = "new"
+			result.Created++
+			existing[row.ImportKey] = true
+			if row.TransactionType == 1 {
+				result.TotalIncome += row.Amount
+			} else {
+				result.TotalExpense += row.Amount
+			}
+
+			importKey := row.ImportKey
+			tags := make([]models.Tag, len(ruled.TagIDs))
+			for i, tagID := range ruled.TagIDs {
+				tags[i] = models.Tag{ID: tagID}
+			}
+			transactions = append(transactions, models.TransactionV2{
+				UserID:          userID,
+				Description:     truncate(row.Description, 200),
+				CategoryID:      categoryID,
+				AssetID:         assetID,
+				Amount:          row.Amount,
+				TransactionType: row.TransactionType,
+				Date:            utils.CustomTime{Time: row.Date},
+				PayeeID:         payeeID,
+				ImportKey:       &importKey,
+				Tags:            tags,
+			})
+		}
+		result.Rows[i] = response
+	}
+
+	if !commit || len(transactions) == 0 {
+		return result, nil
+	}
+
+	if err := s.transactionRepo.CreateBatchWithBalanceUpdate(assetID, userID, transactions); err != nil {
+		return nil, err
+	}
+	for i := range result.Rows {
+		if result.Rows[i].Status == "new" {
+			result.Rows[i].Status = "created"
+		}
+	}
+	utils.LogInfof("Imported %d transactions into asset %d for user %d (%d duplicates, %d rejected)", result.Created, assetID, userID, result.SkippedDuplicates, result.Rejected)
+
+	if result.TotalExpense > 0 {
+		s.budgetService.CheckBudgetAlerts(userID)
+	}
+
+	return result, nil
+}
+
+func (s *importService) applyProfileRequest(profile *models.ImportProfile, req *dto.ImportProfileRequest) error {
+	if req.AmountColumn == "" && req.DebitColumn == "" && req.CreditColumn == "" {
+		return errors.New("amount_column or debit_column/credit_column is required")
+	}
+	if req.AssetID != nil {
+		if err := s.checkAssetOwnership(*req.AssetID, profile.UserID); err != nil {
+			return err
+		}
+	}
+
+	profile.Name = req.Name
+	profile.Delimiter = req.Delimiter
+	if profile.Delimiter == "" {
+		profile.Delimiter = ","
+	}
+	profile.HasHeader = true
+	if req.HasHeader != nil {
+		profile.HasHeader = *req.HasHeader
+	}
+	profile.SkipRows = req.SkipRows
+	profile.DateColumn = req.DateColumn
+	profile.DateFormat = req.DateFormat
+	profile.DescriptionColumn = req.DescriptionColumn
+	profile.AmountColumn = req.AmountColumn
+	profile.DebitColumn = req.DebitColumn
+	profile.CreditColumn = req.CreditColumn
+	profile.TypeColumn = req.TypeColumn
+	profile.DebitValue = req.DebitValue
+	profile.CreditValue = req.CreditValue
+	profile.DecimalSeparator = req.DecimalSeparator
+	if profile.DecimalSeparator == "" {
+		profile.DecimalSeparator = "."
+	}
+	profile.AssetID = req.AssetID
+	profile.IncomeCategoryID = req.IncomeCategoryID
+	profile.ExpenseCategoryID = req.ExpenseCategoryID
+	return nil
+}
+
+func (s *importService) checkAssetOwnership(assetID uint64, userID uint) error {
+	asset, err := s.assetRepo.GetAssetByID(assetID)
+	if err != nil {
+		return errors.New("asset not found")
+	}
+	if asset.UserID != uint64(userID) {
+		return errors.New("unauthorized: asset does not belong to user")
+	}
+	return nil
+}
+
+func profileMapping(profile *models.ImportProfile) importers.CSVMapping {
+	delimiter, _ := utf8.DecodeRuneInString(profile.Delimiter)
+	return importers.CSVMapping{
+		Delimiter:         delimiter,
+		HasHeader:         profile.HasHeader,
+		SkipRows:          profile.SkipRows,
+		DateColumn:        profile.DateColumn,
+		DateFormat:        profile.DateFormat,
+		DescriptionColumn: profile.DescriptionColumn,
+		AmountColumn:      profile.AmountColumn,
+		DebitColumn:       profile.DebitColumn,
+		CreditColumn:      profile.CreditColumn,
+		TypeColumn:        profile.TypeColumn,
+		DebitValue:        profile.DebitValue,
+		CreditValue:       profile.CreditValue,
+		DecimalSeparator:  profile.DecimalSeparator,
+	}
+}
+
+// importCategories holds the categories chosen for an import. An explicit
+// override wins over category rules, which win over the per-direction
+// defaults.
+type importCategories struct {
+	override *uint
+	income   *uint
+	expense  *uint
+}
+
+func (c importCategories) forRow(row importers.Row, ruleCategoryID *uint) uint {
+	if c.override != nil {
+		return *c.override
+	}
+	if ruleCategoryID != nil {
+		return *ruleCategoryID
+	}
+	if row.TransactionType == 1 && c.income != nil {
+		return *c.income
+	}
+	if row.TransactionType == 2 && c.expense != nil {
+		return *c.expense
+	}
+	return 0
+}
+
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,483 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"math"
+	"my-api/dto"
+	"my-api/importers"
+	"my-api/models"
+	"my-api/money"
+	"my-api/repositories"
+	"my-api/utils"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InvestmentService interface {
+	CreateInstrument(userID uint, req *dto.CreateInstrumentRequest) (*dto.InstrumentResponse, error)
+	UpdateInstrument(id, userID uint, req *dto.UpdateInstrumentRequest) (*dto.InstrumentResponse, error)
+	DeleteInstrument(id, userID uint) error
+	GetInstruments(userID uint) ([]dto.InstrumentResponse, error)
+
+	CreateLot(userID uint, assetID uint64, req *dto.CreateHoldingLotRequest) (*dto.HoldingLotResponse, error)
+	DeleteLot(id, userID uint) error
+	GetLots(userID uint, assetID uint64) ([]dto.HoldingLotResponse, error)
+
+	CreatePrice(userID, instrumentID uint, req *dto.CreateInstrumentPriceRequest) (*dto.InstrumentPriceResponse, error)
+	GetPrices(userID, instrumentID uint, startDate, endDate *time.Time) ([]dto.InstrumentPriceResponse, error)
+	DeletePrice(id, userID uint) error
+	ImportPricesCSV(userID uint, file io.Reader, dateFormat string) (*dto.InstrumentPriceImportResponse, error)
+
+	GetHoldings(userID uint, assetID uint64) (*dto.HoldingsResponse, error)
+	MarketValues(userID uint, asOf time.Time) (map[uint64]money.Amount, error)
+}
+
+type investmentService struct {
+	repo      repositories.InvestmentRepository
+	assetRepo *repositories.AssetRepository
+}
+
+func NewInvestmentService(
+	repo repositories.InvestmentRepository,
+	assetRepo *repositories.AssetRepository,
+) InvestmentService {
+	return &investmentService{
+		repo:      repo,
+		assetRepo: assetRepo,
+	}
+}
+
+func (s *investmentService) CreateInstrument(userID uint, req *dto.CreateInstrumentRequest) (*dto.InstrumentResponse, error) {
+	instrument := &models.Instrument{
+		UserID:   userID,
+		Symbol:   strings.ToUpper(strings.TrimSpace(req.Symbol)),
+		Name:     req.Name,
+		Kind:     req.Kind,
+		Currency: strings.ToUpper(req.Currency),
+		Unit:     req.Unit,
+	}
+	if instrument.Kind == "" {
+		instrument.Kind = models.InstrumentKindOther
+	}
+
+	existing, err := s.repo.FindInstrumentsBySymbol(userID, []string{instrument.Symbol})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, errors.New("instrument with this symbol already exists")
+	}
+
+	if err := s.repo.CreateInstrument(instrument); err != nil {
+		return nil, err
+	}
+	return toInstrumentResponse(instrument), nil
+}
+
+// UpdateInstrument changes the descriptive fields. Symbol and currency stay
+// fixed because lots and prices are recorded against them.
+func (s *investmentService) UpdateInstrument(id, userID uint, req *dto.UpdateInstrumentRequest) (*dto.InstrumentResponse, error) {
+	instrument, err := s.findInstrument(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		instrument.Name = *req.Name
+	}
+	if req.Kind != nil {
+		instrument.Kind = *req.Kind
+	}
+	if req.Unit != nil {
+		instrument.Unit = *req.Unit
+	}
+
+	if err := s.repo.UpdateInstrument(instrument); err != nil {
+		return nil, err
+	}
+	return toInstrumentResponse(instrument), nil
+}
+
+func (s *investmentService) DeleteInstrument(id, userID uint) error {
+	if _, err := s.findInstrument(id, userID); err != nil {
+		return err
+	}
+	return s.repo.DeleteInstrument(id, userID)
+}
+
+func (s *investmentService) GetInstruments(userID uint) ([]dto.InstrumentResponse, error) {
+	instruments, err := s.repo.FindInstruments(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.InstrumentResponse, len(instruments))
+	for i := range instruments {
+		responses[i] = *toInstrumentResponse(&instruments[i])
+	}
+	return responses, nil
+}
+
+func (s *investmentService) findInstrument(id, userID uint) (*models.Instrument, error) {
+	instrument, err := s.repo.FindInstrumentByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("instrument not found")
+		}
+		return nil, err
+	}
+	return instrument, nil
+}
+
+func (s *investmentService) CreateLot(userID uint, assetID uint64, req *dto.CreateHoldingLotRequest) (*dto.HoldingLotResponse, error) {
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, errors.New("invalid date format, use YYYY-MM-DD")
+	}
+
+	lot := &models.HoldingLot{
+		UserID:       userID,
+		AssetID:      assetID,
+		InstrumentID: req.InstrumentID,
+		Side:         req.Side,
+		Quantity:     req.Quantity,
+		Price:        req.Price,
+		Fees:         req.Fees,
+		Date:         utils.CustomTime{Time: date},
+		Note:         req.Note,
+	}
+	if err := s.repo.CreateLot(lot); err != nil {
+		return nil, err
+	}
+
+	instrument, err := s.findInstrument(lot.InstrumentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	lot.Instrument = *instrument
+	return toHoldingLotResponse(lot), nil
+}
+
+func (s *investmentService) DeleteLot(id, userID uint) error {
+	return s.repo.DeleteLot(id, userID)
+}
+
+func (s *investmentService) GetLots(userID uint, assetID uint64) ([]dto.HoldingLotResponse, error) {
+	if _, err := s.findAsset(userID, assetID); err != nil {
+		return nil, err
+	}
+
+	lots, err := s.repo.FindLots(userID, &assetID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.HoldingLotResponse, len(lots))
+	for i := range lots {
+		responses[i] = *toHoldingLotResponse(&lots[i])
+	}
+	return responses, nil
+}
+
+func (s *investmentService) findAsset(userID uint, assetID uint64) (*models.Asset, error) {
+	asset, err := s.assetRepo.GetAssetByID(assetID)
+	if err != nil || asset.UserID != uint64(userID) {
+		return nil, errors.New("asset not found")
+	}
+	return asset, nil
+}
+
+// CreatePrice stores a manual price, replacing any price for the same
+// instrument and date
+func (s *investmentService) CreatePrice(userID, instrumentID uint, req *dto.CreateInstrumentPriceRequest) (*dto.InstrumentPriceResponse, error) {
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, errors.New("invalid date format, use YYYY-MM-DD")
+	}
+	if _, err := s.findInstrument(instrumentID, userID); err != nil {
+		return nil, err
+	}
+
+	price := models.InstrumentPrice{
+		UserID:       userID,
+		InstrumentID: instrumentID,
+		PriceDate:    utils.CustomTime{Time: date},
+		Price:        req.Price,
+		Source:       "manual",
+	}
+	if err := s.repo.UpsertPrices([]models.InstrumentPrice{price}); err != nil {
+		return nil, err
+	}
+
+	// The upsert does not report the id of a replaced row, so read it back
+	saved, err := s.repo.FindPrices(userID, instrumentID, &date, &date)
+	if err != nil {
+		return nil, err
+	}
+	if len(saved) == 0 {
+		return nil, errors.New("price not found")
+	}
+	return toInstrumentPriceResponse(&saved[0]), nil
+}
+
+func (s *investmentService) GetPrices(userID, instrumentID uint, startDate, endDate *time.Time) ([]dto.InstrumentPriceResponse, error) {
+	if _, err := s.findInstrument(instrumentID, userID); err != nil {
+		return nil, err
+	}
+
+	prices, err := s.repo.FindPrices(userID, instrumentID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.InstrumentPriceResponse, len(prices))
+	for i := range prices {
+		responses[i] = *toInstrumentPriceResponse(&prices[i])
+	}
+	return responses, nil
+}
+
+func (s *investmentService) DeletePrice(id, userID uint) error {
+	return s.repo.DeletePrice(id, userID)
+}
+
+// ImportPricesCSV loads prices from a CSV file, matching rows to the user's
+// instruments by symbol. Valid rows are saved even when others are rejected.
+func (s *investmentService) ImportPricesCSV(userID uint, file io.Reader, dateFormat string) (*dto.InstrumentPriceImportResponse, error) {
+	rows, err := importers.ParsePricesCSV(file, dateFormat)
+	if err != nil {
+		return nil, errors.New("invalid CSV file: " + err.Error())
+	}
+
+	var symbols []string
+	for _, row := range rows {
+		if row.Error == "" {
+			symbols = append(symbols, row.Symbol)
+		}
+	}
+	instruments, err := s.repo.FindInstrumentsBySymbol(userID, symbols)
+	if err != nil {
+		return nil, err
+	}
+	bySymbol := make(map[string]uint, len(instruments))
+	for _, instrument := range instruments {
+		bySymbol[instrument.Symbol] = instrument.ID
+	}
+
+	result := &dto.InstrumentPriceImportResponse{TotalRows: len(rows)}
+	prices := make([]models.InstrumentPrice, 0, len(rows))
+	for _, row := range rows {
+		instrumentID, ok := bySymbol[row.Symbol]
+		if row.Error == "" && !ok {
+			row.Error = "unknown symbol: " + row.Symbol
+		}
+		if row.Error != "" {
+			result.Rejected++
+			result.Errors = append(result.Errors, dto.InstrumentPriceImportRowError{Line: row.Line, Error: row.Error})
+			continue
+		}
+		prices = append(prices, models.InstrumentPrice{
+			UserID:       userID,
+			InstrumentID: instrumentID,
+			PriceDate:    utils.CustomTime{Time: row.Date},
+			Price:        row.Price,
+			Source:       "csv",
+		})
+	}
+
+	if err := s.repo.UpsertPrices(prices); err != nil {
+		return nil, err
+	}
+	result.Imported = len(prices)
+	utils.LogInfof("Imported %d instrument prices for user %d (%d rejected)", result.Imported, userID, result.Rejected)
+
+	return result, nil
+}
+
+// GetHoldings values every instrument held in an investment asset at its
+// latest price
+func (s *investmentService) GetHoldings(userID uint, assetID uint64) (*dto.HoldingsResponse, error) {
+	asset, err := s.findAsset(userID, assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	lots, err := s.repo.FindLots(userID, &assetID, nil)
+	if err != nil {
+		return nil, err
+	}
+	holdings, err := s.valueLots(userID, lots, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.HoldingsResponse{
+		AssetID:     asset.ID,
+		AssetName:   asset.Name,
+		Currency:    asset.Currency,
+		CashBalance: asset.Balance,
+		Holdings:    make([]dto.HoldingResponse, 0, len(holdings[assetID])),
+	}
+	for _, holding := range holdings[assetID] {
+		response.CostBasis += holding.CostBasis
+		response.MarketValue += holding.MarketValue
+		response.UnrealizedGain += holding.UnrealizedGain
+		response.RealizedGain += holding.RealizedGain
+		response.Holdings = append(response.Holdings, holding)
+	}
+	response.TotalValue = response.CashBalance + response.MarketValue
+	return response, nil
+}
+
+// MarketValues returns the market value of the holdings in each of the
+// user's assets as of the given day, in the asset's currency
+func (s *investmentService) MarketValues(userID uint, asOf time.Time) (map[uint64]money.Amount, error) {
+	lots, err := s.repo.FindLots(userID, nil, &asOf)
+	if err != nil {
+		return nil, err
+	}
+	holdings, err := s.valueLots(userID, lots, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[uint64]money.Amount, len(holdings))
+	for assetID, positions := range holdings {
+		for _, holding := range positions {
+			values[assetID] += holding.MarketValue
+		}
+	}
+	return values, nil
+}
+
+// valueLots builds each asset's positions from its lots, which must be in
+// date order, and prices them as of the given day. Positions that were sold
+// out are kept for their realized gain.
+func (s *investmentService) valueLots(userID uint, lots []models.HoldingLot, asOf time.Time) (map[uint64][]dto.HoldingResponse, error) {
+	type key struct {
+		assetID      uint64
+		instrumentID uint
+	}
+	positions := make(map[key]*dto.HoldingResponse)
+	var keys []key
+	var instrumentIDs []uint
+
+	for i := range lots {
+		lot := &lots[i]
+		k := key{lot.AssetID, lot.InstrumentID}
+		position, ok := positions[k]
+		if !ok {
+			position = &dto.HoldingResponse{
+				InstrumentID: lot.InstrumentID,
+				Symbol:       lot.Instrument.Symbol,
+				Name:         lot.Instrument.Name,
+				Kind:         lot.Instrument.Kind,
+				Unit:         lot.Instrument.Unit,
+			}
+			positions[k] = position
+			keys = append(keys, k)
+			instrumentIDs = append(instrumentIDs, lot.InstrumentID)
+		}
+		applyLot(position, lot)
+	}
+
+	prices, err := s.repo.FindLatestPrices(userID, instrumentIDs, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].assetID != keys[j].assetID {
+			return keys[i].assetID < keys[j].assetID
+		}
+		return positions[keys[i]].Symbol < positions[keys[j]].Symbol
+	})
+
+	holdings := make(map[uint64][]dto.HoldingResponse)
+	for _, k := range keys {
+		position := positions[k]
+		if price, ok := prices[k.instrumentID]; ok {
+			position.Price = price.Price
+			position.PriceDate = price.PriceDate.Format("2006-01-02")
+			position.MarketValue = price.Price.MulRate(position.Quantity)
+		} else if position.Quantity > 0 {
+			position.PriceMissing = true
+			position.MarketValue = position.CostBasis
+		}
+		position.UnrealizedGain = position.MarketValue - position.CostBasis
+		holdings[k.assetID] = append(holdings[k.assetID], *position)
+	}
+	return holdings, nil
+}
+
+// quantityEpsilon absorbs float rounding when a position is sold out
+const quantityEpsilon = 1e-9
+
+// applyLot adds a lot to a position at average cost: a buy adds its price and
+// fees to the cost basis, a sell takes out its share of the cost basis and
+// realizes the proceeds net of fees against it
+func applyLot(position *dto.HoldingResponse, lot *models.HoldingLot) {
+	switch lot.Side {
+	case models.LotSideBuy:
+		position.CostBasis += lot.Price.MulRate(lot.Quantity) + lot.Fees
+		position.Quantity += lot.Quantity
+	case models.LotSideSell:
+		if position.Quantity <= 0 {
+			return
+		}
+		soldCost := position.CostBasis.MulRate(math.Min(lot.Quantity/position.Quantity, 1))
+		proceeds := lot.Price.MulRate(lot.Quantity) - lot.Fees
+		position.RealizedGain += proceeds - soldCost
+		position.CostBasis -= soldCost
+		position.Quantity -= lot.Quantity
+	}
+
+	if position.Quantity < quantityEpsilon {
+		position.Quantity = 0
+		position.CostBasis = 0
+	}
+	position.AverageCost = 0
+	if position.Quantity > 0 {
+		position.AverageCost = position.CostBasis.MulRate(1 / position.Quantity)
+	}
+}
+
+func toInstrumentResponse(instrument *models.Instrument) *dto.InstrumentResponse {
+	return &dto.InstrumentResponse{
+		ID:       instrument.ID,
+		Symbol:   instrument.Symbol,
+		Name:     instrument.Name,
+		Kind:     instrument.Kind,
+		Currency: instrument.Currency,
+		Unit:     instrument.Unit,
+	}
+}
+
+func toHoldingLotResponse(lot *models.HoldingLot) *dto.HoldingLotResponse {
+	return &dto.HoldingLotResponse{
+		ID:           lot.ID,
+		AssetID:      lot.AssetID,
+		InstrumentID: lot.InstrumentID,
+		Symbol:       lot.Instrument.Symbol,
+		Side:         lot.Side,
+		Quantity:     lot.Quantity,
+		Price:        lot.Price,
+		Fees:         lot.Fees,
+		Date:         lot.Date.Format("2006-01-02"),
+		Note:         lot.Note,
+	}
+}
+
+func toInstrumentPriceResponse(price *models.InstrumentPrice) *dto.InstrumentPriceResponse {
+	return &dto.InstrumentPriceResponse{
+		ID:           price.ID,
+		InstrumentID: price.InstrumentID,
+		Price:        price.Price,
+		Date:         price.PriceDate.Format("2006-01-02"),
+		Source:       price.Source,
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"errors"
+	"my-api/dto"
+	"my-api/money"
+	"my-api/repositories"
+	"my-api/utils"
+	"time"
+)
+
+type LedgerService interface {
+	GetStatement(assetID uint64, userID uint, filter *dto.LedgerFilter, page, limit int) (*dto.LedgerStatementResponse, *dto.PaginationResponse, error)
+	GetCreditStatement(assetID uint64, userID uint, asOf time.Time) (*dto.CreditStatementResponse, error)
+}
+
+type ledgerService struct {
+	ledgerRepo repositories.LedgerRepository
+	assetRepo  *repositories.AssetRepository
+}
+
+func NewLedgerService(
+	ledgerRepo repositories.LedgerRepository,
+	assetRepo *repositories.AssetRepository,
+) LedgerService {
+	return &ledgerService{
+		ledgerRepo: ledgerRepo,
+		assetRepo:  assetRepo,
+	}
+}
+
+// GetStatement returns a page of the wallet's postings with a running
+// balance, and checks the stored balance against the ledger
+func (s *ledgerService) GetStatement(assetID uint64, userID uint, filter *dto.LedgerFilter, page, limit int) (*dto.LedgerStatementResponse, *dto.PaginationResponse, error) {
+	asset, err := s.assetRepo.GetAssetByID(assetID)
+	if err != nil {
+		return nil, nil, errors.New("asset not found")
+	}
+	if asset.UserID != uint64(userID) {
+		return nil, nil, errors.New("asset not found")
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	postings, total, opening, err := s.ledgerRepo.GetAssetPostings(assetID, filter, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	ledgerBalance, err := s.ledgerRepo.GetAssetBalance(assetID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	statement := &dto.LedgerStatementResponse{
+		AssetID:        asset.ID,
+		AssetName:      asset.Name,
+		Currency:       asset.Currency,
+		OpeningBalance: opening,
+		Lines:          make([]dto.LedgerLineResponse, len(postings)),
+		StoredBalance:  asset.Balance,
+		LedgerBalance:  ledgerBalance,
+		Difference:     asset.Balance - ledgerBalance,
+		InBalance:      asset.Balance == ledgerBalance,
+	}
+
+	balance := opening
+	for i, p := range postings {
+		balance += p.Amount
+		statement.Lines[i] = dto.LedgerLineResponse{
+			PostingID:     p.ID,
+			EntryID:       p.EntryID,
+			EntryType:     p.Entry.EntryType,
+			TransactionID: p.Entry.TransactionID,
+			TransferID:    p.Entry.TransferID,
+			ReversesID:    p.Entry.ReversesID,
+			Description:   p.Entry.Description,
+			Date:          p.Date,
+			Amount:        p.Amount,
+			Balance:       balance,
+		}
+	}
+	statement.ClosingBalance = balance
+
+	return statement, dto.NewPaginationResponse(nil, page, limit, total), nil
+}
+
+// GetCreditStatement computes the last statement of a credit card or loan
+// closed on or before asOf from the ledger. Money paid in after the closing
+// date counts towards the amount due.
+func (s *ledgerService) GetCreditStatement(assetID uint64, userID uint, asOf time.Time) (*dto.CreditStatementResponse, error) {
+	asset, err := s.assetRepo.GetAssetByID(assetID)
+	if err != nil {
+		return nil, errors.New("asset not found")
+	}
+	if asset.UserID != uint64(userID) {
+		return nil, errors.New("asset not found")
+	}
+	if !asset.IsLiability() {
+		return nil, errors.New("statements are only available for credit cards and loans")
+	}
+	if asset.StatementClosingDay == 0 {
+		return nil, errors.New("statement_closing_day is not set")
+	}
+
+	dueDay := asset.PaymentDueDay
+	if dueDay == 0 {
+		dueDay = asset.StatementClosingDay
+	}
+	cycle := utils.LastStatementCycle(asset.StatementClosingDay, dueDay, asOf)
+	afterClosing := cycle.ClosingDate.AddDate(0, 0, 1)
+
+	previous, err := s.ledgerRepo.GetAssetBalanceBefore(assetID, cycle.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	credits, charges, err := s.ledgerRepo.GetAssetFlows(assetID, cycle.StartDate, afterClosing)
+	if err != nil {
+		return nil, err
+	}
+	paid, _, err := s.ledgerRepo.GetAssetFlows(assetID, afterClosing, asOf.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	// Balances are negative while money is owed
+	statementBalance := -(previous + credits - charges)
+	if statementBalance < 0 {
+		statementBalance = 0
+	}
+	minimum := asset.MinimumPayment(statementBalance)
+
+	available := asset.CreditLimit + asset.Balance
+	if available < 0 {
+		available = 0
+	}
+
+	return &dto.CreditStatementResponse{
+		AssetID:          asset.ID,
+		AssetName:        asset.Name,
+		Currency:         asset.Currency,
+		StartDate:        utils.CustomTime{Time: cycle.StartDate},
+		ClosingDate:      utils.CustomTime{Time: cycle.ClosingDate},
+		DueDate:          utils.CustomTime{Time: cycle.DueDate},
+		PreviousBalance:  -previous,
+		Charges:          charges,
+		Credits:          credits,
+		StatementBalance: statementBalance,
+		MinimumPayment:   minimum,
+		PaidSinceClosing: paid,
+		RemainingDue:     max(statementBalance-paid, money.Amount(0)),
+		RemainingMinimum: max(minimum-paid, money.Amount(0)),
+		CurrentBalance:   -asset.Balance,
+		CreditLimit:      asset.CreditLimit,
+		AvailableCredit:  available,
+	}, nil
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"errors"
+	"my-api/dto"
+	"my-api/models"
+	"my-api/repositories"
+	"my-api/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// PayeeMatcher returns the payee for a raw transaction description, or nil
+// when no rule matches
+type PayeeMatcher func(description string) *uint
+
+type PayeeService interface {
+	CreatePayee(userID uint, req *dto.CreatePayeeRequest) (*dto.PayeeResponse, error)
+	GetPayees(userID uint, search string) ([]dto.PayeeResponse, error)
+	GetPayeeByID(id uint, userID uint) (*dto.PayeeResponse, error)
+	UpdatePayee(id uint, userID uint, req *dto.UpdatePayeeRequest) (*dto.PayeeResponse, error)
+	DeletePayee(id uint, userID uint) error
+
+	CreateRule(userID uint, req *dto.PayeeRuleRequest) (*dto.PayeeRuleResponse, error)
+	GetRules(userID uint) ([]dto.PayeeRuleResponse, error)
+	UpdateRule(id uint, userID uint, req *dto.PayeeRuleRequest) (*dto.PayeeRuleResponse, error)
+	DeleteRule(id uint, userID uint) error
+
+	CheckPayee(id uint, userID uint) error
+	Matcher(userID uint) (PayeeMatcher, error)
+	ApplyRules(userID uint) (*dto.ApplyPayeeRulesResponse, error)
+}
+
+type payeeService struct {
+	repo repositories.PayeeRepository
+}
+
+func NewPayeeService(repo repositories.PayeeRepository) PayeeService {
+	return &payeeService{repo: repo}
+}
+
+func (s *payeeService) CreatePayee(userID uint, req *dto.CreatePayeeRequest) (*dto.PayeeResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("payee name is required")
+	}
+	if _, err := s.repo.FindByName(name, userID); err == nil {
+		return nil, errors.New("payee already exists")
+	}
+
+	payee := &models.Payee{UserID: userID, Name: name}
+	if err := s.repo.Create(payee); err != nil {
+		return nil, err
+	}
+	return toPayeeResponse(payee), nil
+}
+
+func (s *payeeService) GetPayees(userID uint, search string) ([]dto.PayeeResponse, error) {
+	payees, err := s.repo.FindAll(userID, search)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.PayeeResponse, len(payees))
+	for i := range payees {
+		responses[i] = *toPayeeResponse(&payees[i])
+	}
+	return responses, nil
+}
+
+func (s *payeeService) GetPayeeByID(id uint, userID uint) (*dto.PayeeResponse, error) {
+	payee, err := s.findPayee(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toPayeeResponse(payee), nil
+}
+
+func (s *payeeService) UpdatePayee(id uint, userID uint, req *dto.UpdatePayeeRequest) (*dto.PayeeResponse, error) {
+	payee, err := s.findPayee(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("payee name is required")
+	}
+	if existing, err := s.repo.FindByName(name, userID); err == nil && existing.ID != payee.ID {
+		return nil, errors.New("payee already exists")
+	}
+
+	payee.Name = name
+	if err := s.repo.Update(payee); err != nil {
+		return nil, err
+	}
+	return toPayeeResponse(payee), nil
+}
+
+func (s *payeeService) DeletePayee(id uint, userID uint) error {
+	if _, err := s.findPayee(id, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id, userID)
+}
+
+func (s *payeeService) CreateRule(userID uint, req *dto.PayeeRuleRequest) (*dto.PayeeRuleResponse, error) {
+	rule := &models.PayeeRule{UserID: userID, Priority: 100}
+	if err := s.applyRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRule(rule); err != nil {
+		return nil, err
+	}
+	return toPayeeRuleResponse(rule), nil
+}
+
+func (s *payeeService) GetRules(userID uint) ([]dto.PayeeRuleResponse, error) {
+	rules, err := s.repo.FindRules(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.PayeeRuleResponse, len(rules))
+	for i := range rules {
+		responses[i] = *toPayeeRuleResponse(&rules[i])
+	}
+	return responses, nil
+}
+
+func (s *payeeService) UpdateRule(id uint, userID uint, req *dto.PayeeRuleRequest) (*dto.PayeeRuleResponse, error) {
+	rule, err := s.repo.FindRuleByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("payee rule not found")
+		}
+		return nil, err
+	}
+
+	if err := s.applyRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateRule(rule); err != nil {
+		return nil, err
+	}
+	return toPayeeRuleResponse(rule), nil
+}
+
+func (s *payeeService) DeleteRule(id uint, userID uint) error {
+	if _, err := s.repo.FindRuleByID(id, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("payee rule not found")
+		}
+		return err
+	}
+	return s.repo.DeleteRule(id, userID)
+}
+
+// CheckPayee verifies that the payee exists and belongs to the user
+func (s *payeeService) CheckPayee(id uint, userID uint) error {
+	_, err := s.findPayee(id, userID)
+	return err
+}
+
+// Matcher compiles the user's rules once so many descriptions can be resolved
+// without reloading them. Rules whose pattern no longer compiles are skipped.
+func (s *payeeService) Matcher(userID uint) (PayeeMatcher, error) {
+	rules, err := s.repo.FindRules(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	type compiledRule struct {
+		payeeID uint
+		matcher *utils.TextMatcher
+	}
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		matcher, err := utils.NewTextMatcher(rule.MatchType, rule.Pattern)
+		if err != nil {
+			utils.LogWarningf("Skipping payee rule %d: %v", rule.ID, err)
+			continue
+		}
+		compiled = append(compiled, compiledRule{payeeID: rule.PayeeID, matcher: matcher})
+	}
+
+	return func(description string) *uint {
+		for _, rule := range compiled {
+			if rule.matcher.Matches(description) {
+				payeeID := rule.payeeID
+				return &payeeID
+			}
+		}
+		return nil
+	}, nil
+}
+
+// ApplyRules assigns payees to existing transactions that do not have one yet
+func (s *payeeService) ApplyRules(userID uint) (*dto.ApplyPayeeRulesResponse, error) {
+	match, err := s.Matcher(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.ApplyPayeeRulesResponse{}
+	var lastID uint
+	for {
+		transactions, err := s.repo.FindUnassignedTransactions(userID, lastID, 500)
+		if err != nil {
+			return nil, err
+		}
+		if len(transactions) == 0 {
+			break
+		}
+
+		byPayee := map[uint][]uint{}
+		for _, transaction := range transactions {
+			if payeeID := match(transaction.Description); payeeID != nil {
+				byPayee[*payeeID] = append(byPayee[*payeeID], transaction.ID)
+			}
+		}
+		for payeeID, transactionIDs := range byPayee {
+			if err := s.repo.AssignPayee(transactionIDs, payeeID); err != nil {
+				return nil, err
+			}
+			result.Assigned += len(transactionIDs)
+		}
+
+		result.Scanned += len(transactions)
+		lastID = transactions[len(transactions)-1].ID
+	}
+
+	return result, nil
+}
+
+func (s *payeeService) applyRuleRequest(rule *models.PayeeRule, req *dto.PayeeRuleRequest) error {
+	if _, err := s.findPayee(req.PayeeID, rule.UserID); err != nil {
+		return err
+	}
+	if _, err := utils.NewTextMatcher(utils.MatchType(req.MatchType), req.Pattern); err != nil {
+		return err
+	}
+
+	rule.PayeeID = req.PayeeID
+	rule.MatchType = utils.MatchType(req.MatchType)
+	rule.Pattern = req.Pattern
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	return nil
+}
+
+func (s *payeeService) findPayee(id uint, userID uint) (*models.Payee, error) {
+	payee, err := s.repo.FindByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("payee not found")
+		}
+		return nil, err
+	}
+	return payee, nil
+}
+
+func toPayeeResponse(payee *models.Payee) *dto.PayeeResponse {
+	rules := make([]dto.PayeeRuleResponse, len(payee.Rules))
+	for i := range payee.Rules {
+		rules[i] = *toPayeeRuleResponse(&payee.Rules[i])
+	}
+	return &dto.PayeeResponse{
+		ID:    payee.ID,
+		Name:  payee.Name,
+		Rules: rules,
+	}
+}
+
+func toPayeeRuleResponse(rule *models.PayeeRule) *dto.PayeeRuleResponse {
+	return &dto.PayeeRuleResponse{
+		ID:        rule.ID,
+		PayeeID:   rule.PayeeID,
+		MatchType: string(rule.MatchType),
+		Pattern:   rule.Pattern,
+		Priority:  rule.Priority,
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"errors"
+	"my-api/dto"
+	"my-api/models"
+	"my-api/repositories"
+	"my-api/utils"
+	"time"
+)
+
+type ReconciliationService interface {
+	Check(userID *uint, assetIDs []uint64) (*dto.ReconciliationReportResponse, error)
+	Fix(userID *uint, assetIDs []uint64) (*dto.ReconciliationReportResponse, error)
+	CreateReconciliation(userID uint, assetID uint64, req *dto.CreateReconciliationRequest) (*dto.ReconciliationResponse, error)
+	GetReconciliations(userID uint, assetID uint64) ([]dto.ReconciliationResponse, error)
+}
+
+type reconciliationService struct {
+	repo       repositories.ReconciliationRepository
+	ledgerRepo repositories.LedgerRepository
+}
+
+func NewReconciliationService(
+	repo repositories.ReconciliationRepository,
+	ledgerRepo repositories.LedgerRepository,
+) ReconciliationService {
+	return &reconciliationService{
+		repo:       repo,
+		ledgerRepo: ledgerRepo,
+	}
+}
+
+// Check recomputes the balance of each asset and reports where the stored
+// balance disagrees. A nil userID checks every user's assets.
+func (s *reconciliationService) Check(userID *uint, assetIDs []uint64) (*dto.ReconciliationReportResponse, error) {
+	assets, err := s.repo.FindAssets(userID, assetIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.ReconciliationReportResponse{Assets: make([]dto.AssetBalanceCheck, 0, len(assets))}
+	for i := range assets {
+		check, err := s.checkAsset(&assets[i])
+		if err != nil {
+			return nil, err
+		}
+		report.Checked++
+		if check.Discrepancy != 0 || check.LedgerDiscrepancy != 0 {
+			report.Discrepancies++
+		}
+		report.Assets = append(report.Assets, *check)
+	}
+	return report, nil
+}
+
+// Fix posts a correcting adjustment for every asset whose stored balance the
+// history does not explain, then reports the assets again
+func (s *reconciliationService) Fix(userID *uint, assetIDs []uint64) (*dto.ReconciliationReportResponse, error) {
+	report, err := s.Check(userID, assetIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Discrepancies = 0
+	for i, check := range report.Assets {
+		if check.Discrepancy == 0 {
+			if check.LedgerDiscrepancy != 0 {
+				report.Discrepancies++
+			}
+			continue
+		}
+
+		adjusted, err := s.repo.AdjustToStoredBalance(check.AssetID)
+		if err != nil {
+			return nil, err
+		}
+
+		assets, err := s.repo.FindAssets(userID, []uint64{check.AssetID})
+		if err != nil {
+			return nil, err
+		}
+		if len(assets) == 0 {
+			return nil, errors.New("asset not found")
+		}
+		rechecked, err := s.checkAsset(&assets[0])
+		if err != nil {
+			return nil, err
+		}
+		rechecked.Adjusted = adjusted
+		if rechecked.Discrepancy != 0 || rechecked.LedgerDiscrepancy != 0 {
+			report.Discrepancies++
+		}
+		report.Assets[i] = *rechecked
+	}
+	return report, nil
+}
+
+func (s *reconciliationService) checkAsset(asset *models.Asset) (*dto.AssetBalanceCheck, error) {
+	expected, err := s.repo.GetExpectedBalance(asset.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := s.ledgerRepo.GetAssetBalance(asset.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AssetBalanceCheck{
+		AssetID:           asset.ID,
+		UserID:            asset.UserID,
+		AssetName:         asset.Name,
+		Currency:          asset.Currency,
+		StoredBalance:     asset.Balance,
+		ExpectedBalance:   expected,
+		LedgerBalance:     ledger,
+		Discrepancy:       asset.Balance - expected,
+		LedgerDiscrepancy: asset.Balance - ledger,
+	}, nil
+}
+
+// CreateReconciliation marks the wallet as agreeing with a bank statement
+// balance as of the statement date and locks the transactions it covers
+func (s *reconciliationService) CreateReconciliation(userID uint, assetID uint64, req *dto.CreateReconciliationRequest) (*dto.ReconciliationResponse, error) {
+	statementDate, err := time.Parse("2006-01-02", req.StatementDate)
+	if err != nil {
+		return nil, errors.New("invalid statement_date format, use YYYY-MM-DD")
+	}
+	if statementDate.After(time.Now()) {
+		return nil, errors.New("statement_date cannot be in the future")
+	}
+
+	reconciliation := &models.Reconciliation{
+		UserID:           userID,
+		AssetID:          assetID,
+		StatementDate:    utils.CustomTime{Time: statementDate},
+		StatementBalance: *req.StatementBalance,
+		Note:             req.Note,
+	}
+	reconciled, err := s.repo.CreateWithAdjustment(reconciliation, req.Adjust)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toReconciliationResponse(reconciliation)
+	response.TransactionsReconciled = reconciled
+	return response, nil
+}
+
+func (s *reconciliationService) GetReconciliations(userID uint, assetID uint64) ([]dto.ReconciliationResponse, error) {
+	reconciliations, err := s.repo.FindByAsset(assetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ReconciliationResponse, len(reconciliations))
+	for i := range reconciliations {
+		responses[i] = *toReconciliationResponse(&reconciliations[i])
+	}
+	return responses, nil
+}
+
+func toReconciliationResponse(reconciliation *models.Reconciliation) *dto.ReconciliationResponse {
+	return &dto.ReconciliationResponse{
+		ID:               reconciliation.ID,
+		AssetID:          reconciliation.AssetID,
+		StatementDate:    reconciliation.StatementDate,
+		StatementBalance: reconciliation.StatementBalance,
+		ComputedBalance:  reconciliation.ComputedBalance,
+		Adjustment:       reconciliation.Adjustment,
+		Note:             reconciliation.Note,
+		CreatedAt:        reconciliation.CreatedAt,
+	}
+}
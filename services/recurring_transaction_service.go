@@ -0,0 +1,449 @@
+package services
+
+import (
+	"errors"
+	"my-api/dto"
+	"my-api/models"
+	"my-api/repositories"
+	"my-api/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecurringTransactionService interface {
+	CreateRecurringTransaction(userID uint, req *dto.CreateRecurringTransactionRequest) (*dto.RecurringTransactionResponse, error)
+	GetRecurringTransactionByID(id uint, userID uint) (*dto.RecurringTransactionResponse, error)
+	GetAllRecurringTransactions(userID uint, filter *dto.RecurringTransactionFilterRequest) (*dto.PaginationResponse, error)
+	UpdateRecurringTransaction(id uint, userID uint, req *dto.UpdateRecurringTransactionRequest) (*dto.RecurringTransactionResponse, error)
+	DeleteRecurringTransaction(id uint, userID uint) error
+	GetUpcomingOccurrences(id uint, userID uint, count int) ([]dto.RecurringOccurrenceResponse, error)
+	SkipOccurrence(id uint, userID uint, date time.Time) error
+	RetryOccurrence(id uint, userID uint, date time.Time) error
+	PostDueOccurrences(now time.Time) (int, error)
+}
+
+type recurringTransactionService struct {
+	repo            repositories.RecurringTransactionRepository
+	transactionRepo repositories.TransactionV2Repository
+	assetRepo       *repositories.AssetRepository
+	settingsRepo    repositories.UserSettingsRepository
+	budgetService   BudgetService
+}
+
+func NewRecurringTransactionService(
+	repo repositories.RecurringTransactionRepository,
+	transactionRepo repositories.TransactionV2Repository,
+	assetRepo *repositories.AssetRepository,
+	settingsRepo repositories.UserSettingsRepository,
+	budgetService BudgetService,
+) RecurringTransactionService {
+	return &recurringTransactionService{
+		repo:            repo,
+		transactionRepo: transactionRepo,
+		assetRepo:       assetRepo,
+		settingsRepo:    settingsRepo,
+		budgetService:   budgetService,
+	}
+}
+
+func (s *recurringTransactionService) CreateRecurringTransaction(userID uint, req *dto.CreateRecurringTransactionRequest) (*dto.RecurringTransactionResponse, error) {
+	if err := s.checkAssetOwnership(req.AssetID, userID); err != nil {
+		return nil, err
+	}
+	if req.EndDate != nil && req.EndDate.Before(req.StartDate.Time) {
+		return nil, errors.New("end_date must be after start_date")
+	}
+
+	interval := 1
+	if req.Interval > 0 {
+		interval = req.Interval
+	}
+
+	recurring := &models.RecurringTransaction{
+		UserID:          userID,
+		Description:     req.Description,
+		CategoryID:      req.CategoryID,
+		AssetID:         req.AssetID,
+		Amount:          req.Amount,
+		TransactionType: parseTransactionType(req.TransactionType),
+		Frequency:       utils.RecurrenceFrequency(req.Frequency),
+		Interval:        interval,
+		StartDate:       utils.CustomTime{Time: utils.TruncateToDay(req.StartDate.Time)},
+		EndDate:         req.EndDate,
+		IsActive:        true,
+	}
+	s.scheduleNextOccurrence(recurring)
+
+	if err := s.repo.Create(recurring); err != nil {
+		return nil, err
+	}
+
+	return s.GetRecurringTransactionByID(recurring.ID, userID)
+}
+
+func (s *recurringTransactionService) GetRecurringTransactionByID(id uint, userID uint) (*dto.RecurringTransactionResponse, error) {
+	recurring, err := s.repo.FindByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("recurring transaction not found")
+		}
+		return nil, err
+	}
+	return s.toRecurringTransactionResponse(recurring), nil
+}
+
+func (s *recurringTransactionService) GetAllRecurringTransactions(userID uint, filter *dto.RecurringTransactionFilterRequest) (*dto.PaginationResponse, error) {
+	filter.SetDefaults()
+
+	recurrings, total, err := s.repo.FindAll(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.RecurringTransactionResponse, len(recurrings))
+	for i := range recurrings {
+		responses[i] = *s.toRecurringTransactionResponse(&recurrings[i])
+	}
+
+	return dto.NewPaginationResponse(responses, filter.Page, filter.PageSize, total), nil
+}
+
+func (s *recurringTransactionService) UpdateRecurringTransaction(id uint, userID uint, req *dto.UpdateRecurringTransactionRequest) (*dto.RecurringTransactionResponse, error) {
+	recurring, err := s.repo.FindByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("recurring transaction not found")
+		}
+		return nil, err
+	}
+
+	if req.Description != nil {
+		recurring.Description = *req.Description
+	}
+	if req.CategoryID != nil {
+		recurring.CategoryID = *req.CategoryID
+	}
+	if req.AssetID != nil {
+		if err := s.checkAssetOwnership(*req.AssetID, userID); err != nil {
+			return nil, err
+		}
+		recurring.AssetID = *req.AssetID
+	}
+	if req.Amount != nil {
+		recurring.Amount = *req.Amount
+	}
+	if req.TransactionType != nil {
+		recurring.TransactionType = parseTransactionType(*req.TransactionType)
+	}
+	if req.Frequency != nil {
+		recurring.Frequency = utils.RecurrenceFrequency(*req.Frequency)
+	}
+	if req.Interval != nil {
+		recurring.Interval = *req.Interval
+	}
+	if req.StartDate != nil {
+		recurring.StartDate = utils.CustomTime{Time: utils.TruncateToDay(req.StartDate.Time)}
+	}
+	if req.EndDate != nil {
+		recurring.EndDate = req.EndDate
+	}
+	if req.IsActive != nil {
+		recurring.IsActive = *req.IsActive
+	}
+	if recurring.EndDate != nil && recurring.EndDate.Before(recurring.StartDate.Time) {
+		return nil, errors.New("end_date must be after start_date")
+	}
+
+	s.scheduleNextOccurrence(recurring)
+
+	// Clear preloaded relations so Save does not write them back
+	recurring.Category = models.Category{}
+	recurring.Asset = models.Asset{}
+	if err := s.repo.Update(recurring); err != nil {
+		return nil, err
+	}
+
+	return s.GetRecurringTransactionByID(recurring.ID, userID)
+}
+
+func (s *recurringTransactionService) DeleteRecurringTransaction(id uint, userID uint) error {
+	if _, err := s.repo.FindByID(id, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("recurring transaction not found")
+		}
+		return err
+	}
+	return s.repo.Delete(id, userID)
+}
+
+// GetUpcomingOccurrences previews the next occurrences from today, including
+// the ones that have been skipped in advance
+func (s *recurringTransactionService) GetUpcomingOccurrences(id uint, userID uint, count int) ([]dto.RecurringOccurrenceResponse, error) {
+	recurring, err := s.repo.FindByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("recurring transaction not found")
+		}
+		return nil, err
+	}
+
+	today := utils.TruncateToDay(time.Now())
+	dates := recurring.Rule().OccurrencesBetween(s.payCycleSettings(userID), today, today.AddDate(100, 0, 0), count)
+	if len(dates) == 0 {
+		return []dto.RecurringOccurrenceResponse{}, nil
+	}
+
+	occurrences, err := s.repo.GetOccurrences(recurring.ID, dates[0], dates[len(dates)-1])
+	if err != nil {
+		return nil, err
+	}
+	recorded := make(map[string]models.RecurringOccurrence, len(occurrences))
+	for _, occurrence := range occurrences {
+		recorded[occurrence.OccurrenceDate.Format("2006-01-02")] = occurrence
+	}
+
+	responses := make([]dto.RecurringOccurrenceResponse, len(dates))
+	for i, date := range dates {
+		response := dto.RecurringOccurrenceResponse{
+			Date:   utils.CustomTime{Time: date},
+			Status: "scheduled",
+		}
+		if occurrence, ok := recorded[date.Format("2006-01-02")]; ok {
+			response.Status = occurrence.Status
+			response.TransactionID = occurrence.TransactionID
+			response.Message = occurrence.Message
+		}
+		responses[i] = response
+	}
+
+	return responses, nil
+}
+
+func (s *recurringTransactionService) SkipOccurrence(id uint, userID uint, date time.Time) error {
+	recurring, err := s.repo.FindByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("recurring transaction not found")
+		}
+		return err
+	}
+
+	date = utils.TruncateToDay(date)
+	if !recurring.Rule().IsOccurrence(s.payCycleSettings(userID), date) {
+		return errors.New("date is not a scheduled occurrence")
+	}
+
+	existing, err := s.repo.FindOccurrence(recurring.ID, date)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if existing != nil {
+		if existing.Status == models.OccurrenceStatusPosted || existing.Status == models.OccurrenceStatusPending {
+			return errors.New("occurrence has already been posted")
+		}
+		existing.Status = models.OccurrenceStatusSkipped
+		return s.repo.UpdateOccurrence(existing)
+	}
+
+	_, err = s.repo.ClaimOccurrence(&models.RecurringOccurrence{
+		RecurringTransactionID: recurring.ID,
+		UserID:                 userID,
+		OccurrenceDate:         utils.CustomTime{Time: date},
+		Status:                 models.OccurrenceStatusSkipped,
+	})
+	return err
+}
+
+// RetryOccurrence posts a failed occurrence again, e.g. once the wallet has
+// enough balance
+func (s *recurringTransactionService) RetryOccurrence(id uint, userID uint, date time.Time) error {
+	recurring, err := s.repo.FindByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("recurring transaction not found")
+		}
+		return err
+	}
+
+	date = utils.TruncateToDay(date)
+	existing, err := s.repo.FindOccurrence(recurring.ID, date)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("occurrence not found")
+		}
+		return err
+	}
+	if existing.Status != models.OccurrenceStatusFailed {
+		return errors.New("only failed occurrences can be retried")
+	}
+
+	occurrence := &models.RecurringOccurrence{
+		RecurringTransactionID: recurring.ID,
+		UserID:                 userID,
+		OccurrenceDate:         utils.CustomTime{Time: date},
+		Status:                 models.OccurrenceStatusPending,
+	}
+	transaction := occurrenceTransaction(recurring, date)
+	claimed, err := s.repo.PostOccurrence(occurrence, transaction, s.transactionRepo.CreateWithBalanceUpdateTx)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return errors.New("occurrence is already being posted")
+	}
+	if occurrence.Status == models.OccurrenceStatusFailed {
+		return errors.New(occurrence.Message)
+	}
+
+	if transaction.TransactionType == 2 {
+		if err := s.budgetService.CheckBudgetAlerts(userID); err != nil {
+			utils.LogErrorf("Failed to check budget alerts for user %d: %v", userID, err)
+		}
+	}
+	return nil
+}
+
+// PostDueOccurrences posts every occurrence that is due up to now and returns
+// how many transactions were created. Each date is claimed in the same DB
+// transaction that posts it and records the outcome, so a crash or a
+// concurrent worker can never post the same occurrence twice or leave it
+// claimed without a result.
+func (s *recurringTransactionService) PostDueOccurrences(now time.Time) (int, error) {
+	recurrings, err := s.repo.FindDue(now)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for i := range recurrings {
+		recurring := &recurrings[i]
+		settings := s.payCycleSettings(recurring.UserID)
+		postedExpense := false
+		var retryFrom *time.Time
+
+		dates := recurring.Rule().OccurrencesBetween(settings, recurring.NextOccurrence.Time, now, 0)
+		for _, date := range dates {
+			occurrence := &models.RecurringOccurrence{
+				RecurringTransactionID: recurring.ID,
+				UserID:                 recurring.UserID,
+				OccurrenceDate:         utils.CustomTime{Time: date},
+				Status:                 models.OccurrenceStatusPending,
+			}
+			transaction := occurrenceTransaction(recurring, date)
+			claimed, err := s.repo.PostOccurrence(occurrence, transaction, s.transactionRepo.CreateWithBalanceUpdateTx)
+			if err != nil {
+				utils.LogErrorf("Failed to post recurring transaction %d on %s: %v", recurring.ID, date.Format("2006-01-02"), err)
+				retryFrom = &date
+				break
+			}
+			if !claimed {
+				continue
+			}
+			if occurrence.Status == models.OccurrenceStatusFailed {
+				utils.LogWarningf("Failed to post recurring transaction %d on %s: %s", recurring.ID, date.Format("2006-01-02"), occurrence.Message)
+				continue
+			}
+
+			posted++
+			if transaction.TransactionType == 2 {
+				postedExpense = true
+			}
+		}
+
+		// A date whose posting failed on a DB error stays due, so the next
+		// run picks it up again instead of skipping past it.
+		if retryFrom != nil {
+			recurring.NextOccurrence = &utils.CustomTime{Time: *retryFrom}
+		} else if next := recurring.Rule().NextOccurrence(settings, now); next != nil {
+			recurring.NextOccurrence = &utils.CustomTime{Time: *next}
+		} else {
+			recurring.NextOccurrence = nil
+		}
+		if err := s.repo.Update(recurring); err != nil {
+			utils.LogErrorf("Failed to schedule recurring transaction %d: %v", recurring.ID, err)
+		}
+
+		if postedExpense {
+			if err := s.budgetService.CheckBudgetAlerts(recurring.UserID); err != nil {
+				utils.LogErrorf("Failed to check budget alerts for user %d: %v", recurring.UserID, err)
+			}
+		}
+	}
+
+	return posted, nil
+}
+
+// occurrenceTransaction builds the transaction a recurring transaction posts on date
+func occurrenceTransaction(recurring *models.RecurringTransaction, date time.Time) *models.TransactionV2 {
+	return &models.TransactionV2{
+		UserID:          recurring.UserID,
+		Description:     recurring.Description,
+		CategoryID:      recurring.CategoryID,
+		AssetID:         recurring.AssetID,
+		Amount:          recurring.Amount,
+		TransactionType: recurring.TransactionType,
+		Date:            utils.CustomTime{Time: date},
+	}
+}
+
+// scheduleNextOccurrence sets the first occurrence on or after today. Past
+// occurrences are not back-filled when a schedule is created or changed.
+func (s *recurringTransactionService) scheduleNextOccurrence(recurring *models.RecurringTransaction) {
+	yesterday := utils.TruncateToDay(time.Now()).AddDate(0, 0, -1)
+	next := recurring.Rule().NextOccurrence(s.payCycleSettings(recurring.UserID), yesterday)
+	if next == nil {
+		recurring.NextOccurrence = nil
+		return
+	}
+	recurring.NextOccurrence = &utils.CustomTime{Time: *next}
+}
+
+// payCycleSettings returns the user's pay cycle settings, or nil to fall back
+// to calendar months
+func (s *recurringTransactionService) payCycleSettings(userID uint) utils.UserSettingsInterface {
+	settings, err := s.settingsRepo.FindByUserID(userID)
+	if err != nil || settings == nil {
+		return nil
+	}
+	return settings
+}
+
+func (s *recurringTransactionService) checkAssetOwnership(assetID uint64, userID uint) error {
+	asset, err := s.assetRepo.GetAssetByID(assetID)
+	if err != nil {
+		return errors.New("asset not found")
+	}
+	if asset.UserID != uint64(userID) {
+		return errors.New("unauthorized: asset does not belong to user")
+	}
+	return nil
+}
+
+func (s *recurringTransactionService) toRecurringTransactionResponse(recurring *models.RecurringTransaction) *dto.RecurringTransactionResponse {
+	return &dto.RecurringTransactionResponse{
+		ID:              recurring.ID,
+		Description:     recurring.Description,
+		CategoryID:      recurring.CategoryID,
+		CategoryName:    recurring.Category.CategoryName,
+		AssetID:         recurring.AssetID,
+		AssetName:       recurring.Asset.Name,
+		Amount:          recurring.Amount,
+		TransactionType: recurring.TransactionType,
+		Frequency:       string(recurring.Frequency),
+		Interval:        recurring.Interval,
+		StartDate:       recurring.StartDate,
+		EndDate:         recurring.EndDate,
+		NextOccurrence:  recurring.NextOccurrence,
+		IsActive:        recurring.IsActive,
+		CreatedAt:       recurring.CreatedAt,
+	}
+}
+
+// parseTransactionType maps "Income"/"Expense" to 1/2
+func parseTransactionType(transactionType string) int {
+	if transactionType == "Expense" || transactionType == "expense" {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"my-api/models"
+	"my-api/money"
+	"my-api/repositories"
+	"my-api/utils"
+	"time"
+)
+
+type SnapshotService interface {
+	Run(userID *uint, from *time.Time, to time.Time) (int, error)
+}
+
+type snapshotService struct {
+	repo        repositories.SnapshotRepository
+	investments InvestmentService
+}
+
+func NewSnapshotService(
+	repo repositories.SnapshotRepository,
+	investments InvestmentService,
+) SnapshotService {
+	return &snapshotService{
+		repo:        repo,
+		investments: investments,
+	}
+}
+
+// Run records the end-of-day balance of every asset for each day up to and
+// including to. Without from, each asset continues from its newest snapshot,
+// which is taken again since it may have been taken before the day was over,
+// or from the oldest day a ledger posting recorded since then affects, so
+// backdated changes reach the older snapshots. Assets without snapshots are
+// backfilled from their first ledger posting. It returns the number of
+// snapshots written.
+func (s *snapshotService) Run(userID *uint, from *time.Time, to time.Time) (int, error) {
+	to = utils.TruncateToDay(to)
+
+	assets, err := s.repo.FindAssets(userID)
+	if err != nil {
+		return 0, err
+	}
+	ids := make([]uint64, len(assets))
+	for i := range assets {
+		ids[i] = assets[i].ID
+	}
+	lastDates, err := s.repo.LastSnapshotDates(ids)
+	if err != nil {
+		return 0, err
+	}
+	changedDates, err := s.repo.EarliestChangedDates(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	// Holdings are valued per user and day; assets arrive grouped by user
+	var holdingsUser uint64
+	holdings := make(map[string]map[uint64]money.Amount)
+
+	written := 0
+	for i := range assets {
+		asset := &assets[i]
+
+		start, err := s.startDate(asset, from, lastDates, changedDates)
+		if err != nil {
+			return written, err
+		}
+		if start.After(to) {
+			continue
+		}
+
+		balance, days, err := s.repo.DailyBalances(asset.ID, start, to)
+		if err != nil {
+			return written, err
+		}
+
+		if asset.UserID != holdingsUser {
+			holdingsUser = asset.UserID
+			holdings = make(map[string]map[uint64]money.Amount)
+		}
+
+		var snapshots []models.BalanceSnapshot
+		for day := start; !day.After(to); day = day.AddDate(0, 0, 1) {
+			key := day.Format("2006-01-02")
+			balance += days[key]
+
+			var value money.Amount
+			if asset.Type == models.AssetTypeInvestment {
+				values, ok := holdings[key]
+				if !ok {
+					if values, err = s.investments.MarketValues(uint(asset.UserID), day); err != nil {
+						return written, err
+					}
+					holdings[key] = values
+				}
+				value = values[asset.ID]
+			}
+
+			snapshots = append(snapshots, models.BalanceSnapshot{
+				UserID:        uint(asset.UserID),
+				AssetID:       asset.ID,
+				SnapshotDate:  utils.CustomTime{Time: day},
+				Balance:       balance,
+				HoldingsValue: value,
+				Currency:      asset.Currency,
+			})
+		}
+
+		if err := s.repo.Upsert(snapshots); err != nil {
+			return written, err
+		}
+		written += len(snapshots)
+	}
+	return written, nil
+}
+
+// startDate picks the first day to snapshot for an asset
+func (s *snapshotService) startDate(asset *models.Asset, from *time.Time, lastDates, changedDates map[uint64]time.Time) (time.Time, error) {
+	if from != nil {
+		return utils.TruncateToDay(*from), nil
+	}
+	if last, ok := lastDates[asset.ID]; ok {
+		if changed, ok := changedDates[asset.ID]; ok && changed.Before(last) {
+			last = changed
+		}
+		return utils.TruncateToDay(last), nil
+	}
+
+	first, err := s.repo.FirstPostingDate(asset.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if first != nil {
+		return utils.TruncateToDay(*first), nil
+	}
+	return utils.TruncateToDay(asset.CreatedAt), nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"errors"
+	"my-api/dto"
+	"my-api/models"
+	"my-api/repositories"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type TagService interface {
+	CreateTag(userID uint, req *dto.CreateTagRequest) (*dto.TagResponse, error)
+	GetTags(userID uint) ([]dto.TagResponse, error)
+	GetTagByID(id uint, userID uint) (*dto.TagResponse, error)
+	UpdateTag(id uint, userID uint, req *dto.UpdateTagRequest) (*dto.TagResponse, error)
+	DeleteTag(id uint, userID uint) error
+}
+
+type tagService struct {
+	repo repositories.TagRepository
+}
+
+func NewTagService(repo repositories.TagRepository) TagService {
+	return &tagService{repo: repo}
+}
+
+func (s *tagService) CreateTag(userID uint, req *dto.CreateTagRequest) (*dto.TagResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("tag name is required")
+	}
+	if _, err := s.repo.FindByName(name, userID); err == nil {
+		return nil, errors.New("tag already exists")
+	}
+
+	tag := &models.Tag{
+		UserID: userID,
+		Name:   name,
+		Color:  req.Color,
+	}
+	if err := s.repo.Create(tag); err != nil {
+		return nil, err
+	}
+
+	return toTagResponse(tag), nil
+}
+
+func (s *tagService) GetTags(userID uint) ([]dto.TagResponse, error) {
+	tags, err := s.repo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.TagResponse, len(tags))
+	for i := range tags {
+		responses[i] = *toTagResponse(&tags[i])
+	}
+	return responses, nil
+}
+
+func (s *tagService) GetTagByID(id uint, userID uint) (*dto.TagResponse, error) {
+	tag, err := s.findTag(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toTagResponse(tag)
+	if count, err := s.repo.CountTransactions(tag.ID); err == nil {
+		response.TransactionCount = &count
+	}
+	return response, nil
+}
+
+func (s *tagService) UpdateTag(id uint, userID uint, req *dto.UpdateTagRequest) (*dto.TagResponse, error) {
+	tag, err := s.findTag(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("tag name is required")
+		}
+		if existing, err := s.repo.FindByName(name, userID); err == nil && existing.ID != tag.ID {
+			return nil, errors.New("tag already exists")
+		}
+		tag.Name = name
+	}
+	if req.Color != nil {
+		tag.Color = *req.Color
+	}
+
+	if err := s.repo.Update(tag); err != nil {
+		return nil, err
+	}
+	return toTagResponse(tag), nil
+}
+
+func (s *tagService) DeleteTag(id uint, userID uint) error {
+	if _, err := s.findTag(id, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id, userID)
+}
+
+func (s *tagService) findTag(id uint, userID uint) (*models.Tag, error) {
+	tag, err := s.repo.FindByID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tag not found")
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+func toTagResponse(tag *models.Tag) *dto.TagResponse {
+	return &dto.TagResponse{
+		ID:    tag.ID,
+		Name:  tag.Name,
+		Color: tag.Color,
+	}
+}
+
+func toTagResponses(tags []models.Tag) []dto.TagResponse {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	responses := make([]dto.TagResponse, len(tags))
+	for i := range tags {
+		responses[i] = *toTagResponse(&tags[i])
+	}
+	return responses
+}
@@ -3,43 +3,70 @@ package services
 import (
 	"errors"
 	"my-api/dto"
+	"my-api/exporters"
 	"my-api/models"
+	"my-api/money"
 	"my-api/repositories"
-	"time"
+	"my-api/utils"
+	"strings"
 )
 
 type TransactionV2Service interface {
-	GetTransactions(userID uint, page, limit int, startDate, endDate *time.Time, transactionType *int, categoryID *uint, assetID *uint64) ([]dto.TransactionV2Response, *dto.PaginationResponse, error)
+	GetTransactions(userID uint, page, limit int, filter *dto.TransactionV2Filter) ([]dto.TransactionV2Response, *dto.PaginationResponse, error)
+	ExportTransactions(userID uint, filter *dto.TransactionV2Filter, writer exporters.Writer) error
 	GetTransactionByID(id, userID uint) (*dto.TransactionV2Response, error)
 	CreateTransaction(transaction *models.TransactionV2) error
-	UpdateTransaction(transaction *models.TransactionV2, oldAmount int, oldType int) error
+	UpdateTransaction(transaction *models.TransactionV2, oldAmount money.Amount, oldType int) error
 	DeleteTransaction(id, userID uint) error
+	GetDeletedTransactions(userID uint, page, limit int) ([]dto.TransactionV2Response, *dto.PaginationResponse, error)
+	RestoreTransaction(id, userID uint) error
+	UpdateTransactionStatus(id, userID uint, status string, unlock bool) error
 	GetAssetTransactions(assetID uint64, userID uint, page, limit int) (*dto.AssetTransactionsResponse, error)
 }
 
 type transactionV2Service struct {
 	transactionRepo repositories.TransactionV2Repository
 	assetRepo       *repositories.AssetRepository
+	payeeService    PayeeService
+	ruleService     CategoryRuleService
+	ledgerRepo      repositories.LedgerRepository
 }
 
-func NewTransactionV2Service(transactionRepo repositories.TransactionV2Repository, assetRepo *repositories.AssetRepository) TransactionV2Service {
+func NewTransactionV2Service(
+	transactionRepo repositories.TransactionV2Repository,
+	assetRepo *repositories.AssetRepository,
+	ledgerRepo repositories.LedgerRepository,
+	payeeService PayeeService,
+	ruleService CategoryRuleService,
+) TransactionV2Service {
 	return &transactionV2Service{
 		transactionRepo: transactionRepo,
 		assetRepo:       assetRepo,
+		payeeService:    payeeService,
+		ruleService:     ruleService,
+		ledgerRepo:      ledgerRepo,
 	}
 }
 
-func (s *transactionV2Service) GetTransactions(userID uint, page, limit int, startDate, endDate *time.Time, transactionType *int, categoryID *uint, assetID *uint64) ([]dto.TransactionV2Response, *dto.PaginationResponse, error) {
-	transactions, total, err := s.transactionRepo.GetAll(userID, page, limit, startDate, endDate, transactionType, categoryID, assetID)
+func (s *transactionV2Service) GetTransactions(userID uint, page, limit int, filter *dto.TransactionV2Filter) ([]dto.TransactionV2Response, *dto.PaginationResponse, error) {
+	transactions, total, err := s.transactionRepo.GetAll(userID, page, limit, filter)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	// Running balances only make sense within a single asset
+	var runningBalances map[uint]money.Amount
+	if filter != nil && filter.AssetID != nil {
+		if runningBalances, err = s.ledgerRepo.GetRunningBalances(*filter.AssetID, transactions); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	transactionResponses := make([]dto.TransactionV2Response, len(transactions))
 	for i, t := range transactions {
 		assetName := ""
 		assetType := ""
-		assetBalance := 0.0
+		var assetBalance money.Amount
 		assetCurrency := ""
 
 		if t.Asset.ID != 0 {
@@ -54,6 +81,7 @@ func (s *transactionV2Service) GetTransactions(userID uint, page, limit int, sta
 			Description:     t.Description,
 			Amount:          t.Amount,
 			TransactionType: t.TransactionType,
+			Status:          t.Status,
 			Date:            t.Date,
 			CategoryName:    t.Category.CategoryName,
 			BankName:        t.Bank.BankName,
@@ -62,6 +90,14 @@ func (s *transactionV2Service) GetTransactions(userID uint, page, limit int, sta
 			AssetType:       assetType,
 			AssetBalance:    assetBalance,
 			AssetCurrency:   assetCurrency,
+			TransferID:      t.TransferID,
+			PayeeID:         t.PayeeID,
+			PayeeName:       payeeName(t.Payee),
+			Splits:          toSplitResponses(t.Splits),
+			Tags:            toTagResponses(t.Tags),
+		}
+		if balance, ok := runningBalances[t.ID]; ok {
+			transactionResponses[i].RunningBalance = &balance
 		}
 	}
 
@@ -80,6 +116,59 @@ func (s *transactionV2Service) GetTransactions(userID uint, page, limit int, sta
 	return transactionResponses, pagination, nil
 }
 
+// exportColumns is the header row of transaction exports
+var exportColumns = []string{"id", "date", "description", "transaction_type", "amount", "category", "payee", "asset", "currency", "tags", "transfer_id", "status"}
+
+// ExportTransactions writes every transaction matching the filter to writer,
+// oldest first, fetching them from the database in batches
+func (s *transactionV2Service) ExportTransactions(userID uint, filter *dto.TransactionV2Filter, writer exporters.Writer) error {
+	if err := writer.WriteHeader(exportColumns); err != nil {
+		return err
+	}
+
+	err := s.transactionRepo.StreamAll(userID, filter, 500, func(batch []models.TransactionV2) error {
+		for _, t := range batch {
+			transactionType := "expense"
+			if t.TransactionType == 1 {
+				transactionType = "income"
+			}
+
+			tagNames := make([]string, len(t.Tags))
+			for i, tag := range t.Tags {
+				tagNames[i] = tag.Name
+			}
+
+			var transferID interface{}
+			if t.TransferID != nil {
+				transferID = *t.TransferID
+			}
+
+			if err := writer.WriteRow([]interface{}{
+				t.ID,
+				t.Date.Format("2006-01-02 15:04:05"),
+				t.Description,
+				transactionType,
+				t.Amount,
+				t.Category.CategoryName,
+				payeeName(t.Payee),
+				t.Asset.Name,
+				t.Asset.Currency,
+				strings.Join(tagNames, ", "),
+				transferID,
+				t.Status,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
 func (s *transactionV2Service) GetTransactionByID(id, userID uint) (*dto.TransactionV2Response, error) {
 	transaction, err := s.transactionRepo.GetByID(id, userID)
 	if err != nil {
@@ -88,7 +177,7 @@ func (s *transactionV2Service) GetTransactionByID(id, userID uint) (*dto.Transac
 
 	assetName := ""
 	assetType := ""
-	assetBalance := 0.0
+	var assetBalance money.Amount
 	assetCurrency := ""
 
 	if transaction.Asset.ID != 0 {
@@ -103,6 +192,7 @@ func (s *transactionV2Service) GetTransactionByID(id, userID uint) (*dto.Transac
 		Description:     transaction.Description,
 		Amount:          transaction.Amount,
 		TransactionType: transaction.TransactionType,
+		Status:          transaction.Status,
 		Date:            transaction.Date,
 		CategoryName:    transaction.Category.CategoryName,
 		BankName:        transaction.Bank.BankName,
@@ -111,23 +201,106 @@ func (s *transactionV2Service) GetTransactionByID(id, userID uint) (*dto.Transac
 		AssetType:       assetType,
 		AssetBalance:    assetBalance,
 		AssetCurrency:   assetCurrency,
+		TransferID:      transaction.TransferID,
+		PayeeID:         transaction.PayeeID,
+		PayeeName:       payeeName(transaction.Payee),
+		Splits:          toSplitResponses(transaction.Splits),
+		Tags:            toTagResponses(transaction.Tags),
 	}
 
 	return response, nil
 }
 
 func (s *transactionV2Service) CreateTransaction(transaction *models.TransactionV2) error {
+	if err := validateSplits(transaction); err != nil {
+		return err
+	}
+	if err := s.ruleService.Categorize(transaction); err != nil {
+		return err
+	}
+	if transaction.CategoryID == 0 {
+		return errors.New("category is required")
+	}
+	if err := s.resolvePayee(transaction); err != nil {
+		return err
+	}
 	return s.transactionRepo.CreateWithBalanceUpdate(transaction)
 }
 
-func (s *transactionV2Service) UpdateTransaction(transaction *models.TransactionV2, oldAmount int, oldType int) error {
+func (s *transactionV2Service) UpdateTransaction(transaction *models.TransactionV2, oldAmount money.Amount, oldType int) error {
+	if err := validateSplits(transaction); err != nil {
+		return err
+	}
+	if err := s.resolvePayee(transaction); err != nil {
+		return err
+	}
 	return s.transactionRepo.UpdateWithBalanceUpdate(transaction, oldAmount, oldType)
 }
 
+// DeleteTransaction moves the transaction to the trash. Its attachments are
+// kept until the trash is purged.
 func (s *transactionV2Service) DeleteTransaction(id, userID uint) error {
 	return s.transactionRepo.DeleteWithBalanceRollback(id, userID)
 }
 
+// GetDeletedTransactions lists the transactions in the trash, most recently
+// deleted first
+func (s *transactionV2Service) GetDeletedTransactions(userID uint, page, limit int) ([]dto.TransactionV2Response, *dto.PaginationResponse, error) {
+	transactions, total, err := s.transactionRepo.GetDeleted(userID, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transactionResponses := make([]dto.TransactionV2Response, len(transactions))
+	for i, t := range transactions {
+		deletedAt := utils.CustomTime{Time: t.DeletedAt.Time}
+		transactionResponses[i] = dto.TransactionV2Response{
+			ID:              t.ID,
+			Description:     t.Description,
+			Amount:          t.Amount,
+			TransactionType: t.TransactionType,
+			Status:          t.Status,
+			Date:            t.Date,
+			CategoryName:    t.Category.CategoryName,
+			BankName:        t.Bank.BankName,
+			AssetID:         t.AssetID,
+			AssetName:       t.Asset.Name,
+			AssetType:       t.Asset.Type,
+			AssetCurrency:   t.Asset.Currency,
+			TransferID:      t.TransferID,
+			PayeeID:         t.PayeeID,
+			PayeeName:       payeeName(t.Payee),
+			Splits:          toSplitResponses(t.Splits),
+			Tags:            toTagResponses(t.Tags),
+			DeletedAt:       &deletedAt,
+		}
+	}
+
+	totalPages := int(total) / limit
+	if int(total)%limit != 0 {
+		totalPages++
+	}
+
+	pagination := &dto.PaginationResponse{
+		Page:       page,
+		PageSize:   limit,
+		TotalItems: total,
+		TotalPages: totalPages,
+	}
+
+	return transactionResponses, pagination, nil
+}
+
+// RestoreTransaction takes a transaction out of the trash and applies it to
+// its asset's balance again
+func (s *transactionV2Service) RestoreTransaction(id, userID uint) error {
+	return s.transactionRepo.RestoreWithBalanceUpdate(id, userID)
+}
+
+func (s *transactionV2Service) UpdateTransactionStatus(id, userID uint, status string, unlock bool) error {
+	return s.transactionRepo.UpdateStatus(id, userID, status, unlock)
+}
+
 func (s *transactionV2Service) GetAssetTransactions(assetID uint64, userID uint, page, limit int) (*dto.AssetTransactionsResponse, error) {
 	asset, err := s.assetRepo.GetAssetByID(assetID)
 	if err != nil {
@@ -142,16 +315,23 @@ func (s *transactionV2Service) GetAssetTransactions(assetID uint64, userID uint,
 	if err != nil {
 		return nil, err
 	}
+	pending, err := s.assetRepo.GetPendingNet([]uint64{asset.ID})
+	if err != nil {
+		return nil, err
+	}
+	runningBalances, err := s.ledgerRepo.GetRunningBalances(asset.ID, transactions)
+	if err != nil {
+		return nil, err
+	}
 
 	transactionResponses := make([]dto.TransactionV2Response, len(transactions))
-	totalIncome := 0.0
-	totalExpense := 0.0
+	var totalIncome, totalExpense money.Amount
 
 	for i, t := range transactions {
 		if t.TransactionType == 1 {
-			totalIncome += float64(t.Amount)
+			totalIncome += t.Amount
 		} else {
-			totalExpense += float64(t.Amount)
+			totalExpense += t.Amount
 		}
 
 		transactionResponses[i] = dto.TransactionV2Response{
@@ -159,14 +339,22 @@ func (s *transactionV2Service) GetAssetTransactions(assetID uint64, userID uint,
 			Description:     t.Description,
 			Amount:          t.Amount,
 			TransactionType: t.TransactionType,
+			Status:          t.Status,
 			Date:            t.Date,
 			CategoryName:    t.Category.CategoryName,
 			BankName:        t.Bank.BankName,
 			AssetID:         t.AssetID,
 			AssetName:       asset.Name,
 			AssetType:       asset.Type,
-			AssetBalance:    asset.Balance,
 			AssetCurrency:   asset.Currency,
+			TransferID:      t.TransferID,
+			PayeeID:         t.PayeeID,
+			PayeeName:       payeeName(t.Payee),
+			Splits:          toSplitResponses(t.Splits),
+			Tags:            toTagResponses(t.Tags),
+		}
+		if balance, ok := runningBalances[t.ID]; ok {
+			transactionResponses[i].RunningBalance = &balance
 		}
 	}
 
@@ -180,9 +368,72 @@ func (s *transactionV2Service) GetAssetTransactions(assetID uint64, userID uint,
 		AssetName:      asset.Name,
 		AssetType:      asset.Type,
 		CurrentBalance: asset.Balance,
+		ClearedBalance: asset.Balance - pending[asset.ID],
 		Currency:       asset.Currency,
 		Transactions:   transactionResponses,
 		TotalIncome:    totalIncome,
 		TotalExpense:   totalExpense,
 	}, nil
 }
+
+// resolvePayee checks an explicitly chosen payee, or picks one from the
+// user's payee rules when none was given. Transfers never get a payee.
+func (s *transactionV2Service) resolvePayee(transaction *models.TransactionV2) error {
+	if transaction.TransferID != nil {
+		return nil
+	}
+	if transaction.PayeeID != nil {
+		return s.payeeService.CheckPayee(*transaction.PayeeID, transaction.UserID)
+	}
+
+	match, err := s.payeeService.Matcher(transaction.UserID)
+	if err != nil {
+		return err
+	}
+	transaction.PayeeID = match(transaction.Description)
+	return nil
+}
+
+func payeeName(payee *models.Payee) string {
+	if payee == nil {
+		return ""
+	}
+	return payee.Name
+}
+
+// validateSplits checks that split lines, when present, add up to the transaction amount
+func validateSplits(transaction *models.TransactionV2) error {
+	if len(transaction.Splits) == 0 {
+		return nil
+	}
+
+	var total money.Amount
+	for _, split := range transaction.Splits {
+		if split.Amount <= 0 {
+			return errors.New("split amount must be positive")
+		}
+		total += split.Amount
+	}
+	if total != transaction.Amount {
+		return errors.New("split amounts must add up to the transaction amount")
+	}
+	return nil
+}
+
+func toSplitResponses(splits []models.TransactionSplit) []dto.TransactionSplitResponse {
+	if len(splits) == 0 {
+		return nil
+	}
+
+	responses := make([]dto.TransactionSplitResponse, len(splits))
+	for i, split := range splits {
+		responses[i] = dto.TransactionSplitResponse{
+			ID:           split.ID,
+			CategoryID:   split.CategoryID,
+			CategoryName: split.Category.CategoryName,
+			Amount:       split.Amount,
+			Description:  split.Description,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"errors"
+	"my-api/dto"
+	"my-api/models"
+	"my-api/repositories"
+)
+
+type TransferService interface {
+	GetTransfers(userID uint, page, limit int, assetID *uint64) ([]dto.TransferResponse, *dto.PaginationResponse, error)
+	GetTransferByID(id, userID uint) (*dto.TransferResponse, error)
+	CreateTransfer(transfer *models.Transfer, categoryID uint) error
+	CreatePayment(transfer *models.Transfer, categoryID uint) error
+	DeleteTransfer(id, userID uint) error
+}
+
+type transferService struct {
+	transferRepo repositories.TransferRepository
+}
+
+func NewTransferService(transferRepo repositories.TransferRepository) TransferService {
+	return &transferService{transferRepo: transferRepo}
+}
+
+func (s *transferService) GetTransfers(userID uint, page, limit int, assetID *uint64) ([]dto.TransferResponse, *dto.PaginationResponse, error) {
+	transfers, total, err := s.transferRepo.GetAll(userID, page, limit, assetID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]dto.TransferResponse, len(transfers))
+	for i := range transfers {
+		responses[i] = *s.toTransferResponse(&transfers[i])
+	}
+
+	totalPages := int(total) / limit
+	if int(total)%limit != 0 {
+		totalPages++
+	}
+
+	pagination := &dto.PaginationResponse{
+		Page:       page,
+		PageSize:   limit,
+		TotalItems: total,
+		TotalPages: totalPages,
+	}
+
+	return responses, pagination, nil
+}
+
+func (s *transferService) GetTransferByID(id, userID uint) (*dto.TransferResponse, error) {
+	transfer, err := s.transferRepo.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toTransferResponse(transfer), nil
+}
+
+func (s *transferService) CreateTransfer(transfer *models.Transfer, categoryID uint) error {
+	if transfer.SourceAssetID == transfer.TargetAssetID {
+		return errors.New("source and target asset must be different")
+	}
+	if err := s.checkCategory(categoryID, transfer.UserID); err != nil {
+		return err
+	}
+	return s.transferRepo.CreateWithBalanceUpdate(transfer, categoryID)
+}
+
+// CreatePayment pays down the credit card or loan in TargetAssetID from
+// SourceAssetID
+func (s *transferService) CreatePayment(transfer *models.Transfer, categoryID uint) error {
+	if transfer.SourceAssetID == transfer.TargetAssetID {
+		return errors.New("source and target asset must be different")
+	}
+	if err := s.checkCategory(categoryID, transfer.UserID); err != nil {
+		return err
+	}
+	if transfer.Description == "" {
+		transfer.Description = "Payment"
+	}
+	return s.transferRepo.CreatePayment(transfer, categoryID)
+}
+
+// checkCategory makes sure the optional category of the transfer legs
+// belongs to the user
+func (s *transferService) checkCategory(categoryID, userID uint) error {
+	if categoryID == 0 {
+		return nil
+	}
+	exists, err := s.transferRepo.CategoryExists(categoryID, userID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("category not found")
+	}
+	return nil
+}
+
+func (s *transferService) DeleteTransfer(id, userID uint) error {
+	return s.transferRepo.DeleteWithBalanceRollback(id, userID)
+}
+
+func (s *transferService) toTransferResponse(transfer *models.Transfer) *dto.TransferResponse {
+	return &dto.TransferResponse{
+		ID:                  transfer.ID,
+		SourceAssetID:       transfer.SourceAssetID,
+		SourceAssetName:     transfer.SourceAsset.Name,
+		TargetAssetID:       transfer.TargetAssetID,
+		TargetAssetName:     transfer.TargetAsset.Name,
+		Amount:              transfer.Amount,
+		Currency:            transfer.SourceAsset.Currency,
+		Description:         transfer.Description,
+		Date:                transfer.Date,
+		SourceTransactionID: transfer.SourceTransactionID,
+		TargetTransactionID: transfer.TargetTransactionID,
+		CreatedAt:           transfer.CreatedAt,
+	}
+}
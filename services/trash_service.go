@@ -0,0 +1,59 @@
+package services
+
+import (
+	"my-api/repositories"
+	"my-api/storage"
+	"time"
+)
+
+// PurgeResult counts what a trash purge removed
+type PurgeResult struct {
+	Transactions int64
+	Assets       int64
+	Categories   int64
+}
+
+type TrashService interface {
+	Purge(before time.Time) (*PurgeResult, error)
+}
+
+type trashService struct {
+	repo        repositories.TrashRepository
+	fileStorage storage.FileStorage
+}
+
+func NewTrashService(
+	repo repositories.TrashRepository,
+	fileStorage storage.FileStorage,
+) TrashService {
+	return &trashService{
+		repo:        repo,
+		fileStorage: fileStorage,
+	}
+}
+
+// Purge permanently removes transactions, wallets and categories that were
+// moved to the trash before the cutoff, and the files attached to the removed
+// transactions
+func (s *trashService) Purge(before time.Time) (*PurgeResult, error) {
+	result := &PurgeResult{}
+
+	attachments, purged, err := s.repo.PurgeTransactions(before)
+	removeAttachmentFiles(s.fileStorage, attachments)
+	result.Transactions = purged
+	if err != nil {
+		return result, err
+	}
+
+	attachments, purged, err = s.repo.PurgeAssets(before)
+	removeAttachmentFiles(s.fileStorage, attachments)
+	result.Assets = purged
+	if err != nil {
+		return result, err
+	}
+
+	if result.Categories, err = s.repo.PurgeCategories(before); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -5,6 +5,8 @@ import (
 	"my-api/dto"
 	"my-api/models"
 	"my-api/repositories"
+	"my-api/utils"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -18,11 +20,12 @@ type UserSettingsService interface {
 }
 
 type userSettingsService struct {
-	repo repositories.UserSettingsRepository
+	repo    repositories.UserSettingsRepository
+	budgets BudgetService
 }
 
-func NewUserSettingsService(repo repositories.UserSettingsRepository) UserSettingsService {
-	return &userSettingsService{repo: repo}
+func NewUserSettingsService(repo repositories.UserSettingsRepository, budgets BudgetService) UserSettingsService {
+	return &userSettingsService{repo: repo, budgets: budgets}
 }
 
 func (s *userSettingsService) GetUserSettings(userID uint) (*dto.UserSettingsResponse, error) {
@@ -53,12 +56,18 @@ func (s *userSettingsService) CreateUserSettings(userID uint, req *dto.CreateUse
 		return nil, errors.New("user settings already exist, use update instead")
 	}
 
+	baseCurrency := models.DefaultBaseCurrency
+	if req.BaseCurrency != "" {
+		baseCurrency = strings.ToUpper(req.BaseCurrency)
+	}
+
 	// Create settings
 	settings := &models.UserSettings{
 		UserID:           userID,
 		PayCycleType:     req.PayCycleType,
 		PayDay:           req.PayDay,
 		CycleStartOffset: req.CycleStartOffset,
+		BaseCurrency:     baseCurrency,
 		CreatedAt:        time.Now(),
 	}
 
@@ -66,6 +75,7 @@ func (s *userSettingsService) CreateUserSettings(userID uint, req *dto.CreateUse
 	if err != nil {
 		return nil, err
 	}
+	s.realignBudgets(userID)
 
 	return s.toUserSettingsResponse(settings), nil
 }
@@ -85,6 +95,8 @@ func (s *userSettingsService) UpdateUserSettings(userID uint, req *dto.UpdateUse
 		return nil, err
 	}
 
+	payCycleType, payDay, cycleStartOffset := settings.PayCycleType, settings.PayDay, settings.CycleStartOffset
+
 	// Update fields
 	if req.PayCycleType != "" {
 		settings.PayCycleType = req.PayCycleType
@@ -95,17 +107,44 @@ func (s *userSettingsService) UpdateUserSettings(userID uint, req *dto.UpdateUse
 	if req.CycleStartOffset != nil {
 		settings.CycleStartOffset = *req.CycleStartOffset
 	}
+	if req.BaseCurrency != nil {
+		settings.BaseCurrency = strings.ToUpper(*req.BaseCurrency)
+	}
 
 	err = s.repo.Update(settings)
 	if err != nil {
 		return nil, err
 	}
+	if settings.PayCycleType != payCycleType || settings.CycleStartOffset != cycleStartOffset ||
+		!samePayDay(settings.PayDay, payDay) {
+		s.realignBudgets(userID)
+	}
 
 	return s.toUserSettingsResponse(settings), nil
 }
 
 func (s *userSettingsService) DeleteUserSettings(userID uint) error {
-	return s.repo.Delete(userID)
+	if err := s.repo.Delete(userID); err != nil {
+		return err
+	}
+	s.realignBudgets(userID)
+	return nil
+}
+
+// realignBudgets moves the user's pay cycle budgets onto the new pay cycle.
+// The settings are already saved, so a failure is only logged; the budgets
+// pick up the new cycle the next time they are read anyway.
+func (s *userSettingsService) realignBudgets(userID uint) {
+	if err := s.budgets.RealignPayCycleBudgets(userID); err != nil {
+		utils.LogErrorf("Failed to realign pay cycle budgets for user %d: %v", userID, err)
+	}
+}
+
+func samePayDay(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
 func (s *userSettingsService) toUserSettingsResponse(settings *models.UserSettings) *dto.UserSettingsResponse {
@@ -121,6 +160,7 @@ func (s *userSettingsService) toUserSettingsResponse(settings *models.UserSettin
 		PayCycleType:     settings.PayCycleType,
 		PayDay:           settings.PayDay,
 		CycleStartOffset: settings.CycleStartOffset,
+		BaseCurrency:     settings.BaseCurrency,
 		CreatedAt:        settings.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt:        updatedAt,
 	}
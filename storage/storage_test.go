@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorageRoundTrip(t *testing.T) {
+	store, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage failed: %v", err)
+	}
+
+	size, err := store.Save("1/42/receipt.pdf", strings.NewReader("%PDF-1.4 receipt"))
+	if err != nil || size != 16 {
+		t.Fatalf("Save returned %d, %v", size, err)
+	}
+
+	reader, err := store.Open("1/42/receipt.pdf")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "%PDF-1.4 receipt" {
+		t.Errorf("unexpected content %q", data)
+	}
+
+	if err := store.Delete("1/42/receipt.pdf"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Open("1/42/receipt.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestLocalStorageRejectsEscapingKeys(t *testing.T) {
+	store, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage failed: %v", err)
+	}
+
+	for _, key := range []string{"", "../secret", "a/../../secret", "/etc/passwd"} {
+		if _, err := store.Save(key, strings.NewReader("x")); err == nil {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+	}
+}
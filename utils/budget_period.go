@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"time"
+)
+
+// BudgetPeriodFor returns the window holding date of a budget that renews
+// every week, month or year from anchor. Windows run from one renewal day up
+// to the day before the next, and dates before the anchor get the first
+// window.
+func BudgetPeriodFor(frequency RecurrenceFrequency, anchor, date time.Time) FinancialPeriod {
+	anchor, date = TruncateToDay(anchor), TruncateToDay(date)
+	if date.Before(anchor) {
+		date = anchor
+	}
+
+	var start, next time.Time
+	switch frequency {
+	case RecurrenceWeekly:
+		weeks := int(date.Sub(anchor).Hours()/24) / 7
+		start = anchor.AddDate(0, 0, 7*weeks)
+		next = start.AddDate(0, 0, 7)
+	default:
+		step := 1
+		if frequency == RecurrenceYearly {
+			step = 12
+		}
+		months := (date.Year()-anchor.Year())*12 + int(date.Month()-anchor.Month())
+		count := months / step
+		start = AddMonthsClamped(anchor, count*step)
+		if start.After(date) {
+			count--
+			start = AddMonthsClamped(anchor, count*step)
+		}
+		next = AddMonthsClamped(anchor, (count+1)*step)
+	}
+
+	return FinancialPeriod{
+		PeriodLabel: start.Format("2006-01-02"),
+		StartDate:   start,
+		EndDate:     next.AddDate(0, 0, -1),
+	}
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBudgetPeriodFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		frequency RecurrenceFrequency
+		anchor    time.Time
+		date      time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "weekly from a Wednesday",
+			frequency: RecurrenceWeekly,
+			anchor:    date(2026, 9, 30),
+			date:      date(2026, 10, 16),
+			wantStart: date(2026, 10, 14),
+			wantEnd:   date(2026, 10, 20),
+		},
+		{
+			name:      "monthly keeps the anchor day",
+			frequency: RecurrenceMonthly,
+			anchor:    date(2026, 1, 15),
+			date:      date(2026, 10, 14),
+			wantStart: date(2026, 9, 15),
+			wantEnd:   date(2026, 10, 14),
+		},
+		{
+			name:      "monthly clamps to the month end",
+			frequency: RecurrenceMonthly,
+			anchor:    date(2026, 1, 31),
+			date:      date(2026, 3, 1),
+			wantStart: date(2026, 2, 28),
+			wantEnd:   date(2026, 3, 30),
+		},
+		{
+			name:      "yearly",
+			frequency: RecurrenceYearly,
+			anchor:    date(2025, 4, 1),
+			date:      date(2026, 3, 31),
+			wantStart: date(2025, 4, 1),
+			wantEnd:   date(2026, 3, 31),
+		},
+		{
+			name:      "dates before the anchor get the first window",
+			frequency: RecurrenceMonthly,
+			anchor:    date(2026, 10, 1),
+			date:      date(2026, 9, 20),
+			wantStart: date(2026, 10, 1),
+			wantEnd:   date(2026, 10, 31),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BudgetPeriodFor(tt.frequency, tt.anchor, tt.date)
+			if !got.StartDate.Equal(tt.wantStart) || !got.EndDate.Equal(tt.wantEnd) {
+				t.Errorf("expected %s - %s, got %s - %s",
+					tt.wantStart.Format("2006-01-02"), tt.wantEnd.Format("2006-01-02"),
+					got.StartDate.Format("2006-01-02"), got.EndDate.Format("2006-01-02"))
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// PeriodsBetween splits the days from start to end (both inclusive) into
+// calendar periods: "day", "week" (Monday to Sunday), "month" or "year". The
+// first and last periods are cut to the range.
+func PeriodsBetween(groupBy string, start, end time.Time) []FinancialPeriod {
+	start, end = TruncateToDay(start), TruncateToDay(end)
+
+	var periods []FinancialPeriod
+	for current := start; !current.After(end); {
+		periodStart, periodEnd, label := calendarPeriod(groupBy, current)
+		if periodStart.Before(start) {
+			periodStart = start
+		}
+		if periodEnd.After(end) {
+			periodEnd = end
+		}
+		periods = append(periods, FinancialPeriod{
+			PeriodLabel: label,
+			StartDate:   periodStart,
+			EndDate:     periodEnd,
+		})
+		current = periodEnd.AddDate(0, 0, 1)
+	}
+	return periods
+}
+
+// calendarPeriod returns the bounds and label of the period holding day
+func calendarPeriod(groupBy string, day time.Time) (time.Time, time.Time, string) {
+	switch groupBy {
+	case "week":
+		offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+		monday := day.AddDate(0, 0, -offset)
+		year, week := monday.ISOWeek()
+		return monday, monday.AddDate(0, 0, 6), fmt.Sprintf("%d-W%02d", year, week)
+	case "month":
+		first := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+		return first, first.AddDate(0, 1, -1), first.Format("2006-01")
+	case "year":
+		first := time.Date(day.Year(), 1, 1, 0, 0, 0, 0, day.Location())
+		return first, first.AddDate(1, 0, -1), first.Format("2006")
+	default:
+		return day, day, day.Format("2006-01-02")
+	}
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodsBetween(t *testing.T) {
+	tests := []struct {
+		name    string
+		groupBy string
+		start   time.Time
+		end     time.Time
+		want    []FinancialPeriod
+	}{
+		{
+			name:    "months are cut to the range",
+			groupBy: "month",
+			start:   date(2026, 1, 15),
+			end:     date(2026, 3, 10),
+			want: []FinancialPeriod{
+				{PeriodLabel: "2026-01", StartDate: date(2026, 1, 15), EndDate: date(2026, 1, 31)},
+				{PeriodLabel: "2026-02", StartDate: date(2026, 2, 1), EndDate: date(2026, 2, 28)},
+				{PeriodLabel: "2026-03", StartDate: date(2026, 3, 1), EndDate: date(2026, 3, 10)},
+			},
+		},
+		{
+			name:    "weeks run Monday to Sunday",
+			groupBy: "week",
+			start:   date(2026, 10, 1), // Thursday
+			end:     date(2026, 10, 12),
+			want: []FinancialPeriod{
+				{PeriodLabel: "2026-W40", StartDate: date(2026, 10, 1), EndDate: date(2026, 10, 4)},
+				{PeriodLabel: "2026-W41", StartDate: date(2026, 10, 5), EndDate: date(2026, 10, 11)},
+				{PeriodLabel: "2026-W42", StartDate: date(2026, 10, 12), EndDate: date(2026, 10, 12)},
+			},
+		},
+		{
+			name:    "days",
+			groupBy: "day",
+			start:   date(2026, 2, 28),
+			end:     date(2026, 3, 1),
+			want: []FinancialPeriod{
+				{PeriodLabel: "2026-02-28", StartDate: date(2026, 2, 28), EndDate: date(2026, 2, 28)},
+				{PeriodLabel: "2026-03-01", StartDate: date(2026, 3, 1), EndDate: date(2026, 3, 1)},
+			},
+		},
+		{
+			name:    "years",
+			groupBy: "year",
+			start:   date(2025, 6, 1),
+			end:     date(2026, 2, 1),
+			want: []FinancialPeriod{
+				{PeriodLabel: "2025", StartDate: date(2025, 6, 1), EndDate: date(2025, 12, 31)},
+				{PeriodLabel: "2026", StartDate: date(2026, 1, 1), EndDate: date(2026, 2, 1)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PeriodsBetween(tt.groupBy, tt.start, tt.end)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d periods, got %d: %v", len(tt.want), len(got), got)
+			}
+			for i := range tt.want {
+				if got[i].PeriodLabel != tt.want[i].PeriodLabel ||
+					!got[i].StartDate.Equal(tt.want[i].StartDate) ||
+					!got[i].EndDate.Equal(tt.want[i].EndDate) {
+					t.Errorf("period %d: expected %+v, got %+v", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"time"
+)
+
+// RecurrenceFrequency represents how often a recurring transaction repeats
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily    RecurrenceFrequency = "daily"
+	RecurrenceWeekly   RecurrenceFrequency = "weekly"
+	RecurrenceMonthly  RecurrenceFrequency = "monthly"
+	RecurrenceYearly   RecurrenceFrequency = "yearly"
+	RecurrencePayCycle RecurrenceFrequency = "pay_cycle" // First day of each financial period
+)
+
+// RecurrenceRule describes a repeating schedule anchored at StartDate
+type RecurrenceRule struct {
+	Frequency RecurrenceFrequency
+	Interval  int // Repeat every N units, defaults to 1
+	StartDate time.Time
+	EndDate   *time.Time
+}
+
+// TruncateToDay returns midnight UTC of the given date
+func TruncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// AddMonthsClamped adds months to a date, clamping the day to the end of the
+// resulting month (e.g. Jan 31 + 1 month = Feb 28/29)
+func AddMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := time.Date(first.Year(), first.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// OccurrencesBetween returns the occurrence dates that fall within [from, to].
+// A limit of 0 means no limit. Settings are only used for pay_cycle rules.
+func (r RecurrenceRule) OccurrencesBetween(settings UserSettingsInterface, from, to time.Time, limit int) []time.Time {
+	var dates []time.Time
+	from = TruncateToDay(from)
+
+	r.iterate(settings, func(date time.Time) bool {
+		if date.After(to) {
+			return false
+		}
+		if !date.Before(from) {
+			dates = append(dates, date)
+		}
+		return limit == 0 || len(dates) < limit
+	})
+
+	return dates
+}
+
+// NextOccurrence returns the first occurrence strictly after the given date,
+// or nil when the schedule has ended
+func (r RecurrenceRule) NextOccurrence(settings UserSettingsInterface, after time.Time) *time.Time {
+	var next *time.Time
+	after = TruncateToDay(after)
+
+	r.iterate(settings, func(date time.Time) bool {
+		if date.After(after) {
+			next = &date
+			return false
+		}
+		return true
+	})
+
+	return next
+}
+
+// IsOccurrence reports whether the given date is part of the schedule
+func (r RecurrenceRule) IsOccurrence(settings UserSettingsInterface, date time.Time) bool {
+	date = TruncateToDay(date)
+	dates := r.OccurrencesBetween(settings, date, date.Add(24*time.Hour-time.Second), 1)
+	return len(dates) == 1
+}
+
+// iterate calls fn for every occurrence in order until fn returns false or
+// the rule's end date is passed
+func (r RecurrenceRule) iterate(settings UserSettingsInterface, fn func(time.Time) bool) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	start := TruncateToDay(r.StartDate)
+
+	var endDate *time.Time
+	if r.EndDate != nil {
+		end := TruncateToDay(*r.EndDate)
+		endDate = &end
+	}
+
+	if r.Frequency == RecurrencePayCycle {
+		period := GetFinancialPeriodForDate(settings, start)
+		if TruncateToDay(period.StartDate).Before(start) {
+			period = GetFinancialPeriodForDate(settings, period.EndDate.AddDate(0, 0, 1))
+		}
+		for {
+			date := TruncateToDay(period.StartDate)
+			if endDate != nil && date.After(*endDate) {
+				return
+			}
+			if !fn(date) {
+				return
+			}
+			for i := 0; i < interval; i++ {
+				period = GetFinancialPeriodForDate(settings, period.EndDate.AddDate(0, 0, 1))
+			}
+		}
+	}
+
+	for n := 0; ; n++ {
+		var date time.Time
+		switch r.Frequency {
+		case RecurrenceDaily:
+			date = start.AddDate(0, 0, n*interval)
+		case RecurrenceWeekly:
+			date = start.AddDate(0, 0, 7*n*interval)
+		case RecurrenceYearly:
+			date = AddMonthsClamped(start, 12*n*interval)
+		default:
+			date = AddMonthsClamped(start, n*interval)
+		}
+
+		if endDate != nil && date.After(*endDate) {
+			return
+		}
+		if !fn(date) {
+			return
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+type testPayCycleSettings struct {
+	payCycleType PayCycleType
+	payDay       *int
+	offset       int
+}
+
+func (s *testPayCycleSettings) GetPayCycleType() PayCycleType { return s.payCycleType }
+func (s *testPayCycleSettings) GetPayDay() *int               { return s.payDay }
+func (s *testPayCycleSettings) GetCycleStartOffset() int      { return s.offset }
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestMonthlyRecurrenceClampsToMonthEnd(t *testing.T) {
+	rule := RecurrenceRule{Frequency: RecurrenceMonthly, StartDate: date(2026, 1, 31)}
+
+	got := rule.OccurrencesBetween(nil, date(2026, 1, 1), date(2026, 4, 30), 0)
+	want := []time.Time{date(2026, 1, 31), date(2026, 2, 28), date(2026, 3, 31), date(2026, 4, 30)}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d occurrences, got %d: %v", len(want), len(got), got)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("occurrence %d: expected %s, got %s", i, want[i].Format("2006-01-02"), got[i].Format("2006-01-02"))
+		}
+	}
+}
+
+func TestWeeklyRecurrenceWithIntervalAndEndDate(t *testing.T) {
+	end := date(2026, 3, 1)
+	rule := RecurrenceRule{Frequency: RecurrenceWeekly, Interval: 2, StartDate: date(2026, 1, 5), EndDate: &end}
+
+	got := rule.OccurrencesBetween(nil, date(2026, 1, 1), date(2026, 12, 31), 0)
+	if len(got) != 4 {
+		t.Fatalf("expected 4 occurrences, got %d: %v", len(got), got)
+	}
+	if !got[3].Equal(date(2026, 2, 16)) {
+		t.Errorf("expected last occurrence 2026-02-16, got %s", got[3].Format("2006-01-02"))
+	}
+
+	if next := rule.NextOccurrence(nil, date(2026, 2, 16)); next != nil {
+		t.Errorf("expected no occurrence after end date, got %s", next.Format("2006-01-02"))
+	}
+}
+
+func TestPayCycleRecurrenceFollowsPayDay(t *testing.T) {
+	payDay := 25
+	settings := &testPayCycleSettings{payCycleType: PayCycleCustomDay, payDay: &payDay, offset: 0}
+	rule := RecurrenceRule{Frequency: RecurrencePayCycle, StartDate: date(2026, 1, 10)}
+
+	next := rule.NextOccurrence(settings, date(2026, 1, 10))
+	if next == nil || !next.Equal(date(2026, 1, 25)) {
+		t.Fatalf("expected next occurrence 2026-01-25, got %v", next)
+	}
+
+	if !rule.IsOccurrence(settings, date(2026, 2, 25)) {
+		t.Error("expected 2026-02-25 to be an occurrence")
+	}
+	if rule.IsOccurrence(settings, date(2026, 2, 1)) {
+		t.Error("expected 2026-02-01 not to be an occurrence")
+	}
+}
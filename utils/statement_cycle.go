@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"time"
+)
+
+// StatementCycle is one credit card billing period. Charges from StartDate up
+// to the end of ClosingDate appear on the statement, which is due on DueDate.
+type StatementCycle struct {
+	StartDate   time.Time `json:"start_date"`
+	ClosingDate time.Time `json:"closing_date"`
+	DueDate     time.Time `json:"due_date"`
+}
+
+// dayOfMonth returns the given day of a month, clamped to the month's last
+// day so a closing day of 31 falls on Feb 28/29
+func dayOfMonth(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// LastStatementCycle returns the most recent cycle that closed on or before
+// date. The due date is the first dueDay after the closing date.
+func LastStatementCycle(closingDay, dueDay int, date time.Time) StatementCycle {
+	day := TruncateToDay(date)
+
+	closing := dayOfMonth(day.Year(), day.Month(), closingDay)
+	if closing.After(day) {
+		closing = dayOfMonth(day.Year(), day.Month()-1, closingDay)
+	}
+	previous := dayOfMonth(closing.Year(), closing.Month()-1, closingDay)
+
+	due := dayOfMonth(closing.Year(), closing.Month(), dueDay)
+	if !due.After(closing) {
+		due = dayOfMonth(closing.Year(), closing.Month()+1, dueDay)
+	}
+
+	return StatementCycle{
+		StartDate:   previous.AddDate(0, 0, 1),
+		ClosingDate: closing,
+		DueDate:     due,
+	}
+}
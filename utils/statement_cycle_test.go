@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLastStatementCycle(t *testing.T) {
+	cases := []struct {
+		name               string
+		closingDay, dueDay int
+		today              time.Time
+		want               StatementCycle
+	}{
+		{"due next month", 25, 10, date(2026, 3, 30), StatementCycle{date(2026, 2, 26), date(2026, 3, 25), date(2026, 4, 10)}},
+		{"closing today", 25, 10, date(2026, 3, 25), StatementCycle{date(2026, 2, 26), date(2026, 3, 25), date(2026, 4, 10)}},
+		{"before closing", 25, 10, date(2026, 3, 24), StatementCycle{date(2026, 1, 26), date(2026, 2, 25), date(2026, 3, 10)}},
+		{"due same month", 5, 25, date(2026, 3, 10), StatementCycle{date(2026, 2, 6), date(2026, 3, 5), date(2026, 3, 25)}},
+		{"clamped to month end", 31, 15, date(2026, 3, 10), StatementCycle{date(2026, 2, 1), date(2026, 2, 28), date(2026, 3, 15)}},
+		{"across year end", 20, 5, date(2026, 1, 10), StatementCycle{date(2025, 11, 21), date(2025, 12, 20), date(2026, 1, 5)}},
+	}
+
+	for _, tc := range cases {
+		got := LastStatementCycle(tc.closingDay, tc.dueDay, tc.today)
+		if !got.StartDate.Equal(tc.want.StartDate) || !got.ClosingDate.Equal(tc.want.ClosingDate) || !got.DueDate.Equal(tc.want.DueDate) {
+			t.Errorf("%s: expected %s..%s due %s, got %s..%s due %s", tc.name,
+				tc.want.StartDate.Format("2006-01-02"), tc.want.ClosingDate.Format("2006-01-02"), tc.want.DueDate.Format("2006-01-02"),
+				got.StartDate.Format("2006-01-02"), got.ClosingDate.Format("2006-01-02"), got.DueDate.Format("2006-01-02"))
+		}
+	}
+}
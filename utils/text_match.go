@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// MatchType is how a rule pattern is compared with a transaction description
+type MatchType string
+
+const (
+	MatchExact    MatchType = "exact"
+	MatchPrefix   MatchType = "prefix"
+	MatchContains MatchType = "contains"
+	MatchRegex    MatchType = "regex"
+)
+
+// TextMatcher is a compiled pattern. Exact, prefix and contains matches ignore
+// case and repeated whitespace; regex patterns are used as written.
+type TextMatcher struct {
+	matchType MatchType
+	pattern   string
+	regex     *regexp.Regexp
+}
+
+// NewTextMatcher compiles a pattern for the given match type
+func NewTextMatcher(matchType MatchType, pattern string) (*TextMatcher, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, errors.New("pattern is required")
+	}
+
+	matcher := &TextMatcher{matchType: matchType, pattern: normalizeText(pattern)}
+	switch matchType {
+	case MatchExact, MatchPrefix, MatchContains:
+	case MatchRegex:
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.New("invalid regex: " + err.Error())
+		}
+		matcher.regex = regex
+	default:
+		return nil, errors.New("unknown match type: " + string(matchType))
+	}
+	return matcher, nil
+}
+
+// Matches reports whether text satisfies the pattern
+func (m *TextMatcher) Matches(text string) bool {
+	if m.regex != nil {
+		return m.regex.MatchString(text)
+	}
+
+	text = normalizeText(text)
+	switch m.matchType {
+	case MatchExact:
+		return text == m.pattern
+	case MatchPrefix:
+		return strings.HasPrefix(text, m.pattern)
+	default:
+		return strings.Contains(text, m.pattern)
+	}
+}
+
+func normalizeText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
@@ -0,0 +1,43 @@
+package utils
+
+import "testing"
+
+func TestTextMatcher(t *testing.T) {
+	cases := []struct {
+		matchType MatchType
+		pattern   string
+		text      string
+		want      bool
+	}{
+		{MatchPrefix, "grab*food", "GRAB*FOOD 1234", true},
+		{MatchPrefix, "grab", "PAYMENT GRAB", false},
+		{MatchContains, "grab  food", "Order GrabFood", false},
+		{MatchContains, "grabfood", "Order GrabFood #99", true},
+		{MatchExact, "netflix", "  NETFLIX ", true},
+		{MatchRegex, `(?i)^grab\*?\s*food`, "GrabFood", true},
+		{MatchRegex, `(?i)^grab\*?\s*food`, "GRAB*FOOD 1234", true},
+		{MatchRegex, `(?i)^grab\*?\s*food`, "GrabCar", false},
+	}
+
+	for _, tc := range cases {
+		matcher, err := NewTextMatcher(tc.matchType, tc.pattern)
+		if err != nil {
+			t.Fatalf("NewTextMatcher(%s, %q) failed: %v", tc.matchType, tc.pattern, err)
+		}
+		if got := matcher.Matches(tc.text); got != tc.want {
+			t.Errorf("%s %q against %q: expected %v, got %v", tc.matchType, tc.pattern, tc.text, tc.want, got)
+		}
+	}
+}
+
+func TestTextMatcherRejectsInvalidPatterns(t *testing.T) {
+	if _, err := NewTextMatcher(MatchRegex, "("); err == nil {
+		t.Error("expected an error for an invalid regex")
+	}
+	if _, err := NewTextMatcher("fuzzy", "grab"); err == nil {
+		t.Error("expected an error for an unknown match type")
+	}
+	if _, err := NewTextMatcher(MatchContains, "  "); err == nil {
+		t.Error("expected an error for an empty pattern")
+	}
+}
@@ -0,0 +1,38 @@
+package workers
+
+import (
+	"time"
+
+	"my-api/repositories"
+	"my-api/services"
+	"my-api/utils"
+
+	"gorm.io/gorm"
+)
+
+// StartBalanceSnapshotWorker records daily asset balances in the background,
+// once at startup and then every interval. The first run backfills assets
+// that have no snapshots yet.
+func StartBalanceSnapshotWorker(db *gorm.DB, interval time.Duration) {
+	service := services.NewSnapshotService(
+		repositories.NewSnapshotRepository(db),
+		services.NewInvestmentService(repositories.NewInvestmentRepository(db), repositories.NewAssetRepository(db)),
+	)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			written, err := service.Run(nil, nil, time.Now())
+			if err != nil {
+				utils.LogErrorf("Balance snapshot worker failed: %v", err)
+			} else {
+				utils.LogInfof("Balance snapshot worker wrote %d snapshots", written)
+			}
+			<-ticker.C
+		}
+	}()
+
+	utils.LogInfof("Balance snapshot worker started (interval %s)", interval)
+}
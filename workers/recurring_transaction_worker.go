@@ -0,0 +1,42 @@
+package workers
+
+import (
+	"time"
+
+	"my-api/repositories"
+	"my-api/services"
+	"my-api/utils"
+
+	"gorm.io/gorm"
+)
+
+// StartRecurringTransactionWorker posts due recurring transactions in the
+// background, once at startup and then every interval.
+func StartRecurringTransactionWorker(db *gorm.DB, interval time.Duration) {
+	settingsRepo := repositories.NewUserSettingsRepository(db)
+	budgetService := services.NewBudgetService(repositories.NewBudgetRepository(db), settingsRepo)
+	service := services.NewRecurringTransactionService(
+		repositories.NewRecurringTransactionRepository(db),
+		repositories.NewTransactionV2Repository(db),
+		repositories.NewAssetRepository(db),
+		settingsRepo,
+		budgetService,
+	)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			posted, err := service.PostDueOccurrences(time.Now())
+			if err != nil {
+				utils.LogErrorf("Recurring transaction worker failed: %v", err)
+			} else if posted > 0 {
+				utils.LogInfof("Recurring transaction worker posted %d transactions", posted)
+			}
+			<-ticker.C
+		}
+	}()
+
+	utils.LogInfof("Recurring transaction worker started (interval %s)", interval)
+}
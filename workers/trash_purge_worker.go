@@ -0,0 +1,37 @@
+package workers
+
+import (
+	"time"
+
+	"my-api/repositories"
+	"my-api/services"
+	"my-api/storage"
+	"my-api/utils"
+
+	"gorm.io/gorm"
+)
+
+// StartTrashPurgeWorker permanently removes transactions, wallets and
+// categories that have been in the trash for longer than retention, once at
+// startup and then every interval
+func StartTrashPurgeWorker(db *gorm.DB, fileStorage storage.FileStorage, interval, retention time.Duration) {
+	service := services.NewTrashService(repositories.NewTrashRepository(db), fileStorage)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			result, err := service.Purge(time.Now().Add(-retention))
+			if err != nil {
+				utils.LogErrorf("Trash purge worker failed: %v", err)
+			} else if result.Transactions+result.Assets+result.Categories > 0 {
+				utils.LogInfof("Trash purge worker removed %d transactions, %d wallets and %d categories",
+					result.Transactions, result.Assets, result.Categories)
+			}
+			<-ticker.C
+		}
+	}()
+
+	utils.LogInfof("Trash purge worker started (interval %s, retention %s)", interval, retention)
+}